@@ -2,14 +2,31 @@ package gopdf
 
 import (
 	"bytes"
-	//"fmt"
+	"fmt"
+	"sort"
 )
 
 type CatalogObj struct { //impl IObj
 	buffer bytes.Buffer
+	//document level javascript (ชื่อ -> index ของ action obj)
+	javaScripts map[string]int
+	//page labels (index ของหน้าแรกของช่วง -> page label dictionary)
+	pageLabels map[int]string
+	//index ของ OutlinesObj (-1 = ไม่มี bookmark)
+	indexOfOutlines int
+	//index ของ OCGObj ทั้งหมด (layer)
+	indexOfOCGs []int
+	//ภาษาของเอกสาร (BCP 47 sample en-US , "" = ไม่ระบุ)
+	lang string
+	//index ของ FormFieldObj และ font ที่ใช้ (ชื่อ resource -> index ของ font obj)
+	indexOfFormFields []int
+	formFonts         map[string]int
+	getRoot           func() *GoPdf
 }
 
 func (me *CatalogObj) Init(funcGetRoot func() *GoPdf) {
+	me.indexOfOutlines = -1
+	me.getRoot = funcGetRoot
 
 }
 
@@ -17,6 +34,95 @@ func (me *CatalogObj) Build() error {
 	me.buffer.WriteString("<<\n")
 	me.buffer.WriteString("  /Type /" + me.GetType() + "\n")
 	me.buffer.WriteString("  /Pages 2 0 R\n")
+	if me.lang != "" {
+		me.buffer.WriteString("  /Lang " + pdfTextString(me.lang) + "\n")
+	}
+	if me.indexOfOutlines != -1 && !me.getRoot().skipObjs[me.indexOfOutlines] {
+		me.buffer.WriteString(fmt.Sprintf("  /Outlines %d 0 R\n", me.indexOfOutlines+1))
+	}
+	//layer ที่ไม่ได้ใช้ถูกตัดออกใน ExtractPage
+	indexOfOCGs := me.getRoot().keptObjs(me.indexOfOCGs)
+	if len(indexOfOCGs) > 0 {
+		var ocgs bytes.Buffer
+		for _, index := range indexOfOCGs {
+			ocgs.WriteString(fmt.Sprintf(" %d 0 R", index+1))
+		}
+		me.buffer.WriteString("  /OCProperties << /OCGs [" + ocgs.String() + " ] /D << /Order [" + ocgs.String() + " ]")
+		//layer ที่ไม่แสดงบนจอเริ่มต้นเป็น OFF สำหรับ viewer ที่ไม่ใช้ /AS
+		var offs bytes.Buffer
+		for _, index := range indexOfOCGs {
+			if ocg, ok := me.getRoot().pdfObjs[index].(*OCGObj); ok && ocg.viewState == "OFF" {
+				offs.WriteString(fmt.Sprintf(" %d 0 R", index+1))
+			}
+		}
+		if offs.Len() > 0 {
+			me.buffer.WriteString(" /OFF [" + offs.String() + " ]")
+		}
+		//ให้ viewer ใช้ /Usage ของ OCG ตอนพิมพ์และตอนแสดงผล
+		me.buffer.WriteString(" /AS [ << /Event /Print /OCGs [" + ocgs.String() + " ] /Category [/Print] >>")
+		me.buffer.WriteString(" << /Event /View /OCGs [" + ocgs.String() + " ] /Category [/View] >> ] >> >>\n")
+	}
+	//field ของหน้าที่ถูกตัดออกใน ExtractPage
+	indexOfFormFields := me.getRoot().keptObjs(me.indexOfFormFields)
+	if len(indexOfFormFields) > 0 && !me.getRoot().isFlattenForms {
+		me.buffer.WriteString("  /AcroForm << /Fields [")
+		for _, index := range indexOfFormFields {
+			me.buffer.WriteString(fmt.Sprintf(" %d 0 R", index+1))
+		}
+		var fontNames []string
+		for name := range me.formFonts {
+			fontNames = append(fontNames, name)
+		}
+		sort.Strings(fontNames)
+		me.buffer.WriteString(" ]")
+		//ลำดับการคำนวณของ field ที่มี calculate action
+		var calculates bytes.Buffer
+		for _, index := range indexOfFormFields {
+			if field, ok := me.getRoot().pdfObjs[index].(*FormFieldObj); ok && field.option.Calculate != "" {
+				calculates.WriteString(fmt.Sprintf(" %d 0 R", index+1))
+			}
+		}
+		if calculates.Len() > 0 {
+			me.buffer.WriteString(" /CO [" + calculates.String() + " ]")
+		}
+		me.buffer.WriteString(" /DR << /Font <<")
+		for _, name := range fontNames {
+			me.buffer.WriteString(fmt.Sprintf(" /%s %d 0 R", name, me.formFonts[name]+1))
+		}
+		me.buffer.WriteString(" >> >> >>\n")
+	}
+	if len(me.javaScripts) > 0 {
+		//name tree ต้องเรียงตามชื่อ
+		var names []string
+		for name := range me.javaScripts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		me.buffer.WriteString("  /Names << /JavaScript << /Names [")
+		for _, name := range names {
+			me.buffer.WriteString(fmt.Sprintf(" (%s) %d 0 R", escapePdfString(name), me.javaScripts[name]+1))
+		}
+		me.buffer.WriteString(" ] >> >>\n")
+	}
+	if len(me.pageLabels) > 0 {
+		//number tree ต้องเรียงตาม index และต้องมีช่วงที่เริ่มที่หน้าแรก
+		indexes := []int{0}
+		for index := range me.pageLabels {
+			if index != 0 {
+				indexes = append(indexes, index)
+			}
+		}
+		sort.Ints(indexes)
+		me.buffer.WriteString("  /PageLabels << /Nums [")
+		for _, index := range indexes {
+			label, ok := me.pageLabels[index]
+			if !ok {
+				label = "<< /S /D >>"
+			}
+			me.buffer.WriteString(fmt.Sprintf(" %d %s", index, label))
+		}
+		me.buffer.WriteString(" ] >>\n")
+	}
 	me.buffer.WriteString(">>\n")
 	return nil
 }
@@ -28,3 +134,46 @@ func (me *CatalogObj) GetType() string {
 func (me *CatalogObj) GetObjBuff() *bytes.Buffer {
 	return &(me.buffer)
 }
+
+//AddJavaScript : add document level javascript action (index of action obj) named name
+func (me *CatalogObj) AddJavaScript(name string, indexOfAction int) {
+	if me.javaScripts == nil {
+		me.javaScripts = make(map[string]int)
+	}
+	me.javaScripts[name] = indexOfAction
+}
+
+//SetIndexOfOutlines : set index of OutlinesObj (root of bookmarks)
+func (me *CatalogObj) SetIndexOfOutlines(index int) {
+	me.indexOfOutlines = index
+}
+
+//SetLang : set natural language of document (/Lang)
+func (me *CatalogObj) SetLang(lang string) {
+	me.lang = lang
+}
+
+//AddOCG : add index of OCGObj (optional content group) to /OCProperties
+func (me *CatalogObj) AddOCG(index int) {
+	me.indexOfOCGs = append(me.indexOfOCGs, index)
+}
+
+//AddFormField : add index of FormFieldObj to /AcroForm with font it use (fontName is resource name , "" = no font ,
+//indexOfFont is index of font obj)
+func (me *CatalogObj) AddFormField(index int, fontName string, indexOfFont int) {
+	if me.formFonts == nil {
+		me.formFonts = make(map[string]int)
+	}
+	me.indexOfFormFields = append(me.indexOfFormFields, index)
+	if fontName != "" {
+		me.formFonts[fontName] = indexOfFont
+	}
+}
+
+//SetPageLabel : set page label dictionary of range of pages start at pageIndex (0 = first page)
+func (me *CatalogObj) SetPageLabel(pageIndex int, label string) {
+	if me.pageLabels == nil {
+		me.pageLabels = make(map[int]string)
+	}
+	me.pageLabels[pageIndex] = label
+}
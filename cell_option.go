@@ -0,0 +1,20 @@
+package gopdf
+
+// border of cell
+const (
+	Left       = 1 //left border
+	Top        = 2 //top border
+	Right      = 4 //right border
+	Bottom     = 8 //bottom border
+	AllBorders = Left | Top | Right | Bottom
+)
+
+// CellOption : option of CellWithOption
+type CellOption struct {
+	//Border : borders to draw (Left | Top | Right | Bottom)
+	Border int
+	//BorderDash : dash array of border (nil = use current dash pattern from SetDashPattern)
+	BorderDash []float64
+	//BorderDashPhase : dash phase of border
+	BorderDashPhase float64
+}
@@ -0,0 +1,173 @@
+package gopdf
+
+import (
+	"strconv"
+)
+
+//ChartOption : option of BarChart and LineChart
+type ChartOption struct {
+	//Max : value at top of chart (0 = max of values)
+	Max float64
+	//BarGap : gap between bars as fraction of bar slot (0 = 0.2)
+	BarGap float64
+	//FillColor : color of bars (nil = current fill color)
+	FillColor *RichColor
+	//LineColor : color of line of LineChart (nil = current stroke color)
+	LineColor *RichColor
+	//AxisColor : color of axes (nil = current stroke color)
+	AxisColor *RichColor
+	//ShowValues : draw value above each bar / point with current font
+	ShowValues bool
+	//Labels : label under each bar / point (nil = no labels) , drawn with current font
+	Labels []string
+	//FormatValue : format of values (nil = strconv.FormatFloat(v, 'f', -1, 64))
+	FormatValue func(v float64) string
+}
+
+//BarChart : draw bar chart in box x,y,w,h (x,y is the upper left corner) , values below zero are drawn as zero
+func (gp *GoPdf) BarChart(x float64, y float64, w float64, h float64, values []float64, opt ChartOption) error {
+	if len(values) == 0 {
+		return nil
+	}
+	max := chartMax(values, opt)
+	gap := opt.BarGap
+	if gap <= 0 {
+		gap = 0.2
+	}
+	slot := w / float64(len(values))
+	barW := slot * (1 - gap)
+
+	gp.SaveGraphicsState()
+	if opt.FillColor != nil {
+		gp.SetFillColor(opt.FillColor.R, opt.FillColor.G, opt.FillColor.B)
+	}
+	for i, v := range values {
+		barH := chartScale(v, max, h)
+		if barH == 0 {
+			continue
+		}
+		barX := x + slot*float64(i) + (slot-barW)/2
+		bar := []Point{
+			{X: barX, Y: y + h},
+			{X: barX + barW, Y: y + h},
+			{X: barX + barW, Y: y + h - barH},
+			{X: barX, Y: y + h - barH},
+		}
+		if err := gp.Polygon(bar, "F"); err != nil {
+			gp.RestoreGraphicsState()
+			return err
+		}
+	}
+	gp.RestoreGraphicsState()
+	gp.chartAxes(x, y, w, h, opt)
+
+	return gp.chartTexts(x, y, h, slot, values, max, opt)
+}
+
+//LineChart : draw line chart in box x,y,w,h (x,y is the upper left corner) , points are at center of each slot
+func (gp *GoPdf) LineChart(x float64, y float64, w float64, h float64, values []float64, opt ChartOption) error {
+	if len(values) == 0 {
+		return nil
+	}
+	max := chartMax(values, opt)
+	slot := w / float64(len(values))
+
+	gp.SaveGraphicsState()
+	if opt.LineColor != nil {
+		gp.SetStrokeColor(opt.LineColor.R, opt.LineColor.G, opt.LineColor.B)
+	}
+	for i := 1; i < len(values); i++ {
+		gp.Line(x+slot*(float64(i)-0.5), y+h-chartScale(values[i-1], max, h), x+slot*(float64(i)+0.5), y+h-chartScale(values[i], max, h))
+	}
+	gp.RestoreGraphicsState()
+	gp.chartAxes(x, y, w, h, opt)
+
+	return gp.chartTexts(x, y, h, slot, values, max, opt)
+}
+
+//chartAxes : draw left and bottom axis of chart
+func (gp *GoPdf) chartAxes(x float64, y float64, w float64, h float64, opt ChartOption) {
+	gp.SaveGraphicsState()
+	if opt.AxisColor != nil {
+		gp.SetStrokeColor(opt.AxisColor.R, opt.AxisColor.G, opt.AxisColor.B)
+	}
+	gp.Line(x, y, x, y+h)
+	gp.Line(x, y+h, x+w, y+h)
+	gp.RestoreGraphicsState()
+}
+
+//chartTexts : draw values and labels of chart centered in each slot , current position is not changed
+func (gp *GoPdf) chartTexts(x float64, y float64, h float64, slot float64, values []float64, max float64, opt ChartOption) error {
+	if !opt.ShowValues && len(opt.Labels) == 0 {
+		return nil
+	}
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET && gp.Curr.Font_ISubset == nil ||
+		gp.Curr.Font_Type == CURRENT_FONT_TYPE_IFONT && gp.Curr.Font_IFont == nil {
+		return ErrFontNotSet
+	}
+	currX, currY := gp.Curr.X, gp.Curr.Y
+	defer func() {
+		gp.Curr.X, gp.Curr.Y = currX, currY
+	}()
+
+	format := opt.FormatValue
+	if format == nil {
+		format = func(v float64) string {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	lineH := gp.autoLineHeight()
+	for i, v := range values {
+		center := x + slot*(float64(i)+0.5)
+		if opt.ShowValues {
+			err := gp.chartText(center, y+h-chartScale(v, max, h)-lineH, lineH, format(v))
+			if err != nil {
+				return err
+			}
+		}
+		if i < len(opt.Labels) {
+			err := gp.chartText(center, y+h, lineH, opt.Labels[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//chartText : draw text horizontally centered at center in line top at y
+func (gp *GoPdf) chartText(center float64, y float64, lineH float64, text string) error {
+	width, err := gp.MeasureTextWidth(text)
+	if err != nil {
+		return err
+	}
+	gp.Curr.X = center - width/2
+	gp.Curr.Y = y
+	gp.Cell(&Rect{W: width, H: lineH}, text)
+	return nil
+}
+
+//chartMax : value at top of chart
+func chartMax(values []float64, opt ChartOption) float64 {
+	if opt.Max > 0 {
+		return opt.Max
+	}
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+//chartScale : height of v in chart of height h
+func chartScale(v float64, max float64, h float64) float64 {
+	if v <= 0 || max <= 0 {
+		return 0
+	}
+	if v > max {
+		v = max
+	}
+	return v / max * h
+}
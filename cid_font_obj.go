@@ -17,7 +17,7 @@ func (me *CIDFontObj) Init(funcGetRoot func() *GoPdf) {
 func (me *CIDFontObj) Build() error {
 
 	me.buffer.WriteString("<<\n")
-	me.buffer.WriteString(fmt.Sprintf("/BaseFont /%s\n", CreateEmbeddedFontSubsetName(me.PtrToSubsetFontObj.GetFamily())))
+	me.buffer.WriteString(fmt.Sprintf("/BaseFont /%s\n", me.PtrToSubsetFontObj.GetSubsetFontName()))
 	me.buffer.WriteString("/CIDSystemInfo\n")
 	me.buffer.WriteString("<<\n")
 	me.buffer.WriteString("  /Ordering (Identity)\n")
@@ -29,10 +29,13 @@ func (me *CIDFontObj) Build() error {
 	me.buffer.WriteString("/Type /Font\n")
 	characterToGlyphIndex := me.PtrToSubsetFontObj.CharacterToGlyphIndex
 	me.buffer.WriteString("/W [")
-	for _, v := range characterToGlyphIndex {
-		width := me.PtrToSubsetFontObj.GlyphIndexToPdfWidth(v)
+	for k, v := range characterToGlyphIndex {
+		width := me.PtrToSubsetFontObj.glyphWidthOfRune(k, v)
 		me.buffer.WriteString(fmt.Sprintf("%d[%d]", v, width))
 	}
+	for glyphIndex := range me.PtrToSubsetFontObj.substitutedGlyphs {
+		me.buffer.WriteString(fmt.Sprintf("%d[%d]", glyphIndex, me.PtrToSubsetFontObj.GlyphIndexToPdfWidth(glyphIndex)))
+	}
 	me.buffer.WriteString("]\n")
 	me.buffer.WriteString(">>\n")
 	return nil
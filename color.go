@@ -0,0 +1,83 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//ErrInvalidHexColor : text of Hex is not "#rgb" or "#rrggbb"
+var ErrInvalidHexColor = errors.New("invalid hex color")
+
+//Color : color in rgb , cmyk or gray color space , create by RGB , Hex , CMYK or Gray
+type Color struct {
+	//Space : "RGB" , "CMYK" or "Gray"
+	Space string
+	//R , G , B : rgb 0-255 (Space "RGB")
+	R, G, B uint8
+	//C , M , Y , K : cmyk 0.0-1.0 (Space "CMYK")
+	C, M, Y, K float64
+	//Level : gray 0.0 (black) - 1.0 (white) (Space "Gray")
+	Level float64
+}
+
+//RGB : rgb color (0-255) , converted to default color space when it is used (SetDefaultColorSpace)
+func RGB(r uint8, g uint8, b uint8) Color {
+	return Color{Space: "RGB", R: r, G: g, B: b}
+}
+
+//Hex : rgb color of css style hex text "#ff8800" or "#f80" ("#" is optional)
+func Hex(hex string) (Color, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, ErrInvalidHexColor
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, ErrInvalidHexColor
+	}
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+//CMYK : cmyk color (0.0-1.0)
+func CMYK(c float64, m float64, y float64, k float64) Color {
+	return Color{Space: "CMYK", C: c, M: m, Y: y, K: k}
+}
+
+//Gray : gray color 0.0 (black) - 1.0 (white)
+func Gray(level float64) Color {
+	return Color{Space: "Gray", Level: level}
+}
+
+//operator : operator that set fill (or stroke) color to c (end with new line)
+func (c Color) operator(gp *GoPdf, stroke bool) string {
+	switch c.Space {
+	case "CMYK":
+		op := "k"
+		if stroke {
+			op = "K"
+		}
+		return fmt.Sprintf("%.3f %.3f %.3f %.3f %s\n", fixRange10(c.C), fixRange10(c.M), fixRange10(c.Y), fixRange10(c.K), op)
+	case "Gray":
+		op := "g"
+		if stroke {
+			op = "G"
+		}
+		return fmt.Sprintf("%.3f %s\n", fixRange10(c.Level), op)
+	}
+	return gp.colorOperator(c.R, c.G, c.B, stroke)
+}
+
+//SetFill : set the color for the fill , operator (rg , k or g) is chosen by color space of color
+func (gp *GoPdf) SetFill(color Color) {
+	gp.getContent().AppendStreamSetColor(color, false)
+}
+
+//SetStroke : set the color for the stroke , operator (RG , K or G) is chosen by color space of color
+func (gp *GoPdf) SetStroke(color Color) {
+	gp.getContent().AppendStreamSetColor(color, true)
+}
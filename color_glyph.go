@@ -0,0 +1,87 @@
+package gopdf
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+//colorLayers : layers (COLR) of glyph of r , nil if r is not color glyph , r must be added to subset
+func (s *SubsetFontObj) colorLayers(r rune) []core.ColorLayer {
+	glyphIndex, ok := s.CharacterToGlyphIndex[r]
+	if !ok {
+		return nil
+	}
+	return s.ttfp.ColorLayers(glyphIndex)
+}
+
+//hasColorGlyph : text has glyph with COLR layers (font without COLR always draw glyphs in one color)
+func (s *SubsetFontObj) hasColorGlyph(text string) bool {
+	if !s.ttfp.HasTable("COLR") {
+		return false
+	}
+	for _, r := range text {
+		if s.colorLayers(r) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+//appendStreamColorGlyphs : same as AppendStreamSubsetFont but each color glyph is drawn as its layers filled with colors of palette 0 (CPAL) ,
+//other runs of text are drawn as usual
+func (c *ContentObj) appendStreamColorGlyphs(rectangle *Rect, text string, sub *SubsetFontObj) {
+	root := c.getRoot()
+	fontSize := root.Curr.Font_Size
+	scale := float64(fontSize) / 1000.0
+	font := "/F" + strconv.Itoa(root.Curr.Font_FontCount+1) + " " + strconv.Itoa(fontSize) + " Tf\n"
+	x := root.Curr.X
+	y := root.config.PageSize.H - root.cellBaseline(rectangle)
+	palette := sub.ttfp.PaletteColors(0)
+
+	writeText := func(textOp string) {
+		c.stream.WriteString("BT\n")
+		c.stream.WriteString(fmt.Sprintf("%0.2f %0.2f TD\n", x, y))
+		c.stream.WriteString(font)
+		c.stream.WriteString(textOp)
+		c.stream.WriteString("ET\n")
+	}
+	var run []rune
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		textOp, width := c.subsetFontTextOperator(string(run))
+		writeText(textOp)
+		x += width * scale
+		run = run[:0]
+	}
+	for _, r := range text {
+		layers := sub.colorLayers(r)
+		if layers == nil {
+			run = append(run, r)
+			continue
+		}
+		flush()
+		for _, layer := range layers {
+			//glyph ของ layer ไม่มีใน cmap จึงใส่ใน subset แบบ glyph จาก GSUB
+			sub.addSubstitutedGlyphs([]shapedGlyph{{glyphIndex: layer.GlyphIndex, runes: []rune{r}, substituted: true}})
+			c.stream.WriteString("q\n")
+			if layer.PaletteIndex != core.ForegroundPaletteIndex && layer.PaletteIndex < len(palette) {
+				color := palette[layer.PaletteIndex]
+				c.stream.WriteString(root.colorOperator(color.R, color.G, color.B, false))
+			}
+			writeText(fmt.Sprintf("<%04X> Tj\n", layer.GlyphIndex))
+			c.stream.WriteString("Q\n")
+		}
+		x += float64(sub.GlyphIndexToPdfWidth(sub.CharacterToGlyphIndex[r])) * scale
+	}
+	flush()
+
+	if rectangle == nil {
+		root.Curr.X = x
+	} else {
+		root.Curr.X += rectangle.W
+	}
+}
@@ -0,0 +1,56 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+//ErrUnknownColorSpace : color space is not "RGB" , "CMYK" or "Gray"
+var ErrUnknownColorSpace = errors.New("unknown color space")
+
+//SetDefaultColorSpace : color space ("RGB" (default) , "CMYK" or "Gray") that rgb colors of SetFillColor , SetStrokeColor ,
+//RichText and charts are converted to , keep whole document in one color model for prepress
+func (gp *GoPdf) SetDefaultColorSpace(name string) error {
+	if name != "RGB" && name != "CMYK" && name != "Gray" {
+		return ErrUnknownColorSpace
+	}
+	gp.defaultColorSpace = name
+	return nil
+}
+
+//colorOperator : operator that set fill (or stroke) color r,g,b (0-255) in default color space (end with new line)
+func (gp *GoPdf) colorOperator(r uint8, g uint8, b uint8, stroke bool) string {
+	red := float64(r) / 255.0
+	green := float64(g) / 255.0
+	blue := float64(b) / 255.0
+	switch gp.defaultColorSpace {
+	case "CMYK":
+		op := "k"
+		if stroke {
+			op = "K"
+		}
+		c, m, y, k := rgbToCMYK(red, green, blue)
+		return fmt.Sprintf("%.3f %.3f %.3f %.3f %s\n", c, m, y, k, op)
+	case "Gray":
+		op := "g"
+		if stroke {
+			op = "G"
+		}
+		return fmt.Sprintf("%.3f %s\n", fixRange10(luminance(red, green, blue)), op)
+	}
+	op := "rg"
+	if stroke {
+		op = "RG"
+	}
+	return fmt.Sprintf("%.3f %.3f %.3f %s\n", red, green, blue, op)
+}
+
+//rgbToCMYK : naive conversion (no color profile) , black is put in k only
+func rgbToCMYK(r float64, g float64, b float64) (float64, float64, float64, float64) {
+	k := 1 - math.Max(r, math.Max(g, b))
+	if k >= 1 {
+		return 0, 0, 0, 1
+	}
+	return (1 - r - k) / (1 - k), (1 - g - k) / (1 - k), (1 - b - k) / (1 - k), k
+}
@@ -0,0 +1,50 @@
+package gopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+)
+
+//SetCompressLevel : compress content streams with FlateDecode , level is zlib level (1-9 , -1 = zlib.DefaultCompression) ,
+//0 = not compress (default)
+func (gp *GoPdf) SetCompressLevel(level int) {
+	gp.compressLevel = level
+}
+
+//SetMinCompressSize : streams shorter than size bytes are stored uncompressed (default 0 = compress every stream) ,
+//stream is also stored uncompressed if compressed data is not smaller
+func (gp *GoPdf) SetMinCompressSize(size int) {
+	gp.minCompressSize = size
+}
+
+//SetPageCompression : compress (or not) content stream of current page whatever level of SetCompressLevel is ,
+//page compressed by this while document is not compressed use zlib.DefaultCompression
+func (gp *GoPdf) SetPageCompression(enabled bool) {
+	gp.getContent().compression = &enabled
+}
+
+//compressStream : FlateDecode data of stream , false if stream should be stored uncompressed
+func (gp *GoPdf) compressStream(stream []byte) ([]byte, bool) {
+	return gp.compressStreamLevel(stream, gp.compressLevel)
+}
+
+//compressStreamLevel : FlateDecode data of stream with zlib level , false if stream should be stored uncompressed
+func (gp *GoPdf) compressStreamLevel(stream []byte, level int) ([]byte, bool) {
+	if level == zlib.NoCompression || len(stream) < gp.minCompressSize {
+		return stream, false
+	}
+	var zbuff bytes.Buffer
+	w, err := zlib.NewWriterLevel(&zbuff, level)
+	if err != nil {
+		return stream, false
+	}
+	_, err = w.Write(stream)
+	if err != nil {
+		return stream, false
+	}
+	err = w.Close()
+	if err != nil || zbuff.Len() >= len(stream) {
+		return stream, false
+	}
+	return zbuff.Bytes(), true
+}
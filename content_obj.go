@@ -2,9 +2,12 @@ package gopdf
 
 import (
 	"bytes"
+	"compress/zlib"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
+	"strings"
 )
 
 type ContentObj struct { //impl IObj
@@ -13,6 +16,10 @@ type ContentObj struct { //impl IObj
 
 	//text bytes.Buffer
 	getRoot func() *GoPdf
+	//ops ของ value ของ form field ในหน้านี้ ใส่ต่อท้าย stream เมื่อ FlattenForms
+	flattenOps [][]byte
+	//compression : SetPageCompression of this page (nil = level of SetCompressLevel)
+	compression *bool
 }
 
 func (c *ContentObj) Init(funcGetRoot func() *GoPdf) {
@@ -20,72 +27,144 @@ func (c *ContentObj) Init(funcGetRoot func() *GoPdf) {
 }
 
 func (c *ContentObj) Build() error {
-	streamlen := c.stream.Len()
+	stream := c.getRoot().simpleFontStream(c.pageStream())
+	if c.getRoot().isGrayscaleOutput {
+		stream = grayscaleContentStream(stream)
+	}
+	//ซ่อม q/Q ที่ไม่ครบคู่ (strict mode จะ error ก่อนมาถึงตรงนี้)
+	missing, extra := graphicsStateBalance(stream)
+	if missing > 0 || extra > 0 {
+		var repair bytes.Buffer
+		repair.WriteString(strings.Repeat("q\n", extra))
+		repair.Write(stream)
+		repair.WriteString(strings.Repeat("Q\n", missing))
+		stream = repair.Bytes()
+	}
+	stream, isCompressed := c.getRoot().compressStreamLevel(stream, c.compressLevel())
+	streamlen := len(stream)
 	c.buffer.WriteString("<<\n")
 	c.buffer.WriteString("/Length " + strconv.Itoa(streamlen) + "\n")
+	if isCompressed {
+		c.buffer.WriteString("/Filter /FlateDecode\n")
+	}
 	c.buffer.WriteString(">>\n")
 	c.buffer.WriteString("stream\n")
-	c.buffer.Write(c.stream.Bytes())
+	c.buffer.Write(stream)
 	c.buffer.WriteString("endstream\n")
 	return nil
 }
 
+//compressLevel : zlib level of stream of this page
+func (c *ContentObj) compressLevel() int {
+	level := c.getRoot().compressLevel
+	if c.compression == nil {
+		return level
+	}
+	if !*c.compression {
+		return zlib.NoCompression
+	}
+	if level == zlib.NoCompression {
+		return zlib.DefaultCompression
+	}
+	return level
+}
+
 func (c *ContentObj) GetType() string {
 	return "Content"
 }
 
+//pageStream : stream and values of form fields if forms are flattened
+func (c *ContentObj) pageStream() []byte {
+	if !c.getRoot().isFlattenForms || len(c.flattenOps) == 0 {
+		return c.stream.Bytes()
+	}
+	var stream bytes.Buffer
+	stream.Write(c.stream.Bytes())
+	for _, ops := range c.flattenOps {
+		stream.WriteString("q\n")
+		stream.Write(ops)
+		stream.WriteString("Q\n")
+	}
+	return stream.Bytes()
+}
+
 func (c *ContentObj) GetObjBuff() *bytes.Buffer {
 	return &(c.buffer)
 }
 
 func (c *ContentObj) AppendStreamSubsetFont(rectangle *Rect, text string) {
-
-	sumWidth := uint64(0)
-	var buff bytes.Buffer
-	for _, r := range text {
-		index, err := c.getRoot().Curr.Font_ISubset.CharIndex(r)
-		if err != nil {
-			log.Fatalf("err:%s", err.Error())
-		}
-		buff.WriteString(fmt.Sprintf("%04X", index))
-		width, err := c.getRoot().Curr.Font_ISubset.CharWidth(r)
-		if err != nil {
-			log.Fatalf("err:%s", err.Error())
-		}
-		sumWidth += width
+	if sub, ok := c.getRoot().Curr.Font_ISubset.(*SubsetFontObj); ok && sub.hasColorGlyph(text) {
+		c.appendStreamColorGlyphs(rectangle, text, sub)
+		return
 	}
 
+	textOp, sumWidth := c.subsetFontTextOperator(text)
 	fontSize := c.getRoot().Curr.Font_Size
 	x := fmt.Sprintf("%0.2f", c.getRoot().Curr.X)
-	y := fmt.Sprintf("%0.2f", c.getRoot().config.PageSize.H-c.getRoot().Curr.Y-(float64(fontSize)*0.7))
+	y := fmt.Sprintf("%0.2f", c.getRoot().config.PageSize.H-c.getRoot().cellBaseline(rectangle))
 
 	c.stream.WriteString("BT\n")
 	c.stream.WriteString(x + " " + y + " TD\n")
 	c.stream.WriteString("/F" + strconv.Itoa(c.getRoot().Curr.Font_FontCount+1) + " " + strconv.Itoa(fontSize) + " Tf\n")
-	c.stream.WriteString("<" + buff.String() + "> Tj\n")
+	c.stream.WriteString(textOp)
 	c.stream.WriteString("ET\n")
 	if rectangle == nil {
-		fontSize := c.getRoot().Curr.Font_Size
-		c.getRoot().Curr.X += float64(sumWidth) * (float64(fontSize) / 1000.0)
+		c.getRoot().Curr.X += sumWidth * (float64(fontSize) / 1000.0)
 	} else {
 		c.getRoot().Curr.X += rectangle.W
 	}
 }
 
+//subsetFontTextOperator : Tj (or TJ if space advance is scaled by SetSpaceWidthFactor) of text in current subset font
+//and width of text in 1/1000 of font size , chars of text must be added to subset
+func (c *ContentObj) subsetFontTextOperator(text string) (string, float64) {
+	sumWidth := 0.0
+	spaceWidthFactor := c.getRoot().currSpaceWidthFactor()
+	isAdjusted := false
+	var buff bytes.Buffer
+	glyphs := c.getRoot().shapeCurrText(text)
+	if sub, ok := c.getRoot().Curr.Font_ISubset.(*SubsetFontObj); ok {
+		sub.addSubstitutedGlyphs(glyphs)
+	}
+	for _, glyph := range glyphs {
+		buff.WriteString(fmt.Sprintf("%04X", glyph.glyphIndex))
+		width := glyph.width
+		sumWidth += float64(width)
+		if len(glyph.runes) == 1 && isSpaceRune(glyph.runes[0]) && spaceWidthFactor != 1 {
+			//ปรับ advance ของ space ด้วย TJ
+			extra := float64(width) * (spaceWidthFactor - 1)
+			sumWidth += extra
+			buff.WriteString(fmt.Sprintf("> %0.2f <", -extra))
+			isAdjusted = true
+		}
+	}
+	if isAdjusted {
+		return "[<" + buff.String() + ">] TJ\n", sumWidth
+	}
+	return "<" + buff.String() + "> Tj\n", sumWidth
+}
+
 func (c *ContentObj) AppendStream(rectangle *Rect, text string) {
 
 	fontSize := c.getRoot().Curr.Font_Size
+	wordSpacing := c.getRoot().wordSpacing()
 
 	x := fmt.Sprintf("%0.2f", c.getRoot().Curr.X)
-	y := fmt.Sprintf("%0.2f", c.getRoot().config.PageSize.H-c.getRoot().Curr.Y-(float64(fontSize)*0.7))
+	y := fmt.Sprintf("%0.2f", c.getRoot().config.PageSize.H-c.getRoot().cellBaseline(rectangle))
 
 	c.stream.WriteString("BT\n")
 	c.stream.WriteString(x + " " + y + " TD\n")
 	c.stream.WriteString("/F" + strconv.Itoa(c.getRoot().Curr.Font_FontCount+1) + " " + strconv.Itoa(fontSize) + " Tf\n")
-	c.stream.WriteString("(" + text + ") Tj\n")
+	if wordSpacing != 0 {
+		c.stream.WriteString(fmt.Sprintf("%0.2f Tw\n", wordSpacing))
+	}
+	c.stream.WriteString("(" + escapePdfString(text) + ") Tj\n")
+	if wordSpacing != 0 {
+		c.stream.WriteString("0 Tw\n")
+	}
 	c.stream.WriteString("ET\n")
 	if rectangle == nil {
-		c.getRoot().Curr.X += StrHelperGetStringWidth(text, fontSize, c.getRoot().Curr.Font_IFont)
+		c.getRoot().Curr.X += StrHelperGetStringWidth(text, fontSize, c.getRoot().Curr.Font_IFont) + float64(strings.Count(text, " "))*wordSpacing
 	} else {
 		c.getRoot().Curr.X += rectangle.W
 	}
@@ -93,12 +172,20 @@ func (c *ContentObj) AppendStream(rectangle *Rect, text string) {
 }
 
 func (c *ContentObj) AppendStreamLine(x1 float64, y1 float64, x2 float64, y2 float64) {
-
+	c.getRoot().measureArea(x1, y1, x2-x1, y2-y1)
 	h := c.getRoot().config.PageSize.H
 	c.stream.WriteString(fmt.Sprintf("%0.2f %0.2f m %0.2f %0.2f l s\n", x1, h-y1, x2, h-y2))
 }
 
-func (c *ContentObj) AppendUnderline(startX float64, y float64, endX float64, endY float64, text string) {
+//AppendStreamOptionalContent : content of draw is marked as optional content of group named name in /Properties resource
+func (c *ContentObj) AppendStreamOptionalContent(name string, draw func(c *ContentObj)) {
+	c.stream.WriteString("/OC /" + name + " BDC\n")
+	draw(c)
+	c.stream.WriteString("EMC\n")
+}
+
+//AppendUnderline : underline text from startX to endX , baseline is the baseline of text
+func (c *ContentObj) AppendUnderline(startX float64, baseline float64, endX float64, endY float64, text string) {
 
 	h := c.getRoot().config.PageSize.H
 	ut := int(0)
@@ -111,17 +198,26 @@ func (c *ContentObj) AppendUnderline(startX float64, y float64, endX float64, en
 	}
 
 	textH := ContentObj_CalTextHeight(c.getRoot().Curr.Font_Size)
-	arg3 := float64(h) - float64(y) - textH - textH*0.07
+	arg3 := float64(h) - float64(baseline) - textH*0.07
 	arg4 := (float64(ut) / 1000.00) * float64(c.getRoot().Curr.Font_Size)
 	c.stream.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f -%0.2f re f\n", startX, arg3, endX-startX, arg4))
 }
 
 func (c *ContentObj) AppendStreamSetLineWidth(w float64) {
-
+	if w != 0 && math.Abs(w) < 0.005 {
+		//width ที่เล็กมากต้องไม่ถูกปัดเป็น 0 (0 คือ hairline)
+		c.stream.WriteString(strconv.FormatFloat(w, 'f', -1, 64) + " w\n")
+		return
+	}
 	c.stream.WriteString(fmt.Sprintf("%.2f w\n", w))
 
 }
 
+//AppendStreamScale : scale by sx,sy around x,y (pdf space)
+func (c *ContentObj) AppendStreamScale(sx float64, sy float64, x float64, y float64) {
+	c.stream.WriteString(fmt.Sprintf("%0.4f 0 0 %0.4f %0.2f %0.2f cm\n", sx, sy, x-x*sx, y-y*sy))
+}
+
 //  Set the grayscale fills
 func (c *ContentObj) AppendStreamSetGrayFill(w float64) {
 	w = fixRange10(w)
@@ -134,12 +230,199 @@ func (c *ContentObj) AppendStreamSetGrayStroke(w float64) {
 	c.stream.WriteString(fmt.Sprintf("%.2f G\n", w))
 }
 
+//AppendStreamSetDashPattern : set dash pattern of stroke (empty dash = solid line)
+func (c *ContentObj) AppendStreamSetDashPattern(dash []float64, phase float64) {
+	var arr []string
+	for _, d := range dash {
+		arr = append(arr, fmt.Sprintf("%0.2f", d))
+	}
+	c.stream.WriteString(fmt.Sprintf("[%s] %0.2f d\n", strings.Join(arr, " "), phase))
+}
+
+//AppendStreamRectangle : stroke rectangle , x,y is the upper left corner
+func (c *ContentObj) AppendStreamRectangle(x float64, y float64, w float64, h float64) {
+	pageH := c.getRoot().config.PageSize.H
+	c.stream.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f re S\n", x, pageH-(y+h), w, h))
+}
+
+//AppendStreamFillRectangle : fill rectangle with color in its own graphics state , x,y is the upper left corner
+func (c *ContentObj) AppendStreamFillRectangle(x float64, y float64, w float64, h float64, color Color) {
+	pageH := c.getRoot().config.PageSize.H
+	c.stream.WriteString("q\n")
+	c.AppendStreamSetColor(color, false)
+	c.stream.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f re f\n", x, pageH-(y+h), w, h))
+	c.stream.WriteString("Q\n")
+}
+
+//AppendStreamRoundedRect : paint (op S , f or B) rectangle with radii of corners top left , top right , bottom right , bottom left ,
+//x,y is the upper left corner
+func (c *ContentObj) AppendStreamRoundedRect(x float64, y float64, w float64, h float64, radii []float64, op string) {
+	pageH := c.getRoot().config.PageSize.H
+	top := pageH - y
+	bottom := pageH - (y + h)
+	right := x + w
+	tl, tr, br, bl := radii[0], radii[1], radii[2], radii[3]
+	const k = 0.5523 //ระยะ control point ของ bezier ที่ใกล้วงกลม
+	var path bytes.Buffer
+	path.WriteString(fmt.Sprintf("%0.2f %0.2f m\n", x+tl, top))
+	path.WriteString(fmt.Sprintf("%0.2f %0.2f l\n", right-tr, top))
+	if tr > 0 {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c\n", right-tr+tr*k, top, right, top-tr+tr*k, right, top-tr))
+	}
+	path.WriteString(fmt.Sprintf("%0.2f %0.2f l\n", right, bottom+br))
+	if br > 0 {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c\n", right, bottom+br-br*k, right-br+br*k, bottom, right-br, bottom))
+	}
+	path.WriteString(fmt.Sprintf("%0.2f %0.2f l\n", x+bl, bottom))
+	if bl > 0 {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c\n", x+bl-bl*k, bottom, x, bottom+bl-bl*k, x, bottom+bl))
+	}
+	path.WriteString(fmt.Sprintf("%0.2f %0.2f l\n", x, top-tl))
+	if tl > 0 {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c\n", x, top-tl+tl*k, x+tl-tl*k, top, x+tl, top))
+	}
+	path.WriteString("h " + op + "\n")
+	c.stream.Write(path.Bytes())
+}
+
+//AppendStreamPolygon : closed path through points (x,y from top of page) painted by op
+func (c *ContentObj) AppendStreamPolygon(points []Point, op string) {
+	pageH := c.getRoot().config.PageSize.H
+	var path bytes.Buffer
+	for i, point := range points {
+		operator := "l"
+		if i == 0 {
+			operator = "m"
+		}
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f %s\n", point.X, pageH-point.Y, operator))
+	}
+	path.WriteString("h " + op + "\n")
+	c.stream.Write(path.Bytes())
+}
+
+//AppendStreamBorder : stroke borders (Left | Top | Right | Bottom) of rectangle as one path , x,y is the upper left corner ,
+//if dash is not nil border is drawn with dash in its own graphics state
+func (c *ContentObj) AppendStreamBorder(x float64, y float64, w float64, h float64, border int, dash []float64, phase float64) {
+	pageH := c.getRoot().config.PageSize.H
+	top := pageH - y
+	bottom := pageH - (y + h)
+	var path bytes.Buffer
+	if border&Left == Left {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f m %0.2f %0.2f l\n", x, top, x, bottom))
+	}
+	if border&Top == Top {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f m %0.2f %0.2f l\n", x, top, x+w, top))
+	}
+	if border&Right == Right {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f m %0.2f %0.2f l\n", x+w, top, x+w, bottom))
+	}
+	if border&Bottom == Bottom {
+		path.WriteString(fmt.Sprintf("%0.2f %0.2f m %0.2f %0.2f l\n", x, bottom, x+w, bottom))
+	}
+	if path.Len() == 0 {
+		return
+	}
+	if dash != nil {
+		c.AppendStreamSaveGraphicsState()
+		c.AppendStreamSetDashPattern(dash, phase)
+	}
+	c.stream.Write(path.Bytes())
+	c.stream.WriteString("S\n")
+	if dash != nil {
+		c.AppendStreamRestoreGraphicsState()
+	}
+}
+
+//AppendStreamSaveGraphicsState : push graphics state (q)
+func (c *ContentObj) AppendStreamSaveGraphicsState() {
+	c.stream.WriteString("q\n")
+}
+
+//AppendStreamRestoreGraphicsState : pop graphics state (Q)
+func (c *ContentObj) AppendStreamRestoreGraphicsState() {
+	c.stream.WriteString("Q\n")
+}
+
+//AppendStreamRaw : append ops as is (end with new line)
+func (c *ContentObj) AppendStreamRaw(ops string) {
+	c.stream.WriteString(ops)
+	if !strings.HasSuffix(ops, "\n") {
+		c.stream.WriteString("\n")
+	}
+}
+
+//  Set the rgb color fills
+func (c *ContentObj) AppendStreamSetColorFill(r uint8, g uint8, b uint8) {
+	c.stream.WriteString(c.getRoot().colorOperator(r, g, b, false))
+}
+
+//  Set the rgb color stroke
+func (c *ContentObj) AppendStreamSetColorStroke(r uint8, g uint8, b uint8) {
+	c.stream.WriteString(c.getRoot().colorOperator(r, g, b, true))
+}
+
+//AppendStreamSetColor : set fill (or stroke) color in color space of color
+func (c *ContentObj) AppendStreamSetColor(color Color, stroke bool) {
+	c.stream.WriteString(color.operator(c.getRoot(), stroke))
+}
+
 func (c *ContentObj) AppendStreamImage(index int, x float64, y float64, rect *Rect) {
+	c.getRoot().measureArea(x, y, rect.W, rect.H)
 	//fmt.Printf("index = %d",index)
+	c.appendImageDo(index, x, y, rect)
+}
+
+//appendImageDo : paint image at x,y (upper left) , not measured (rotated image is measured by its bounding box)
+func (c *ContentObj) appendImageDo(index int, x float64, y float64, rect *Rect) {
 	h := c.getRoot().config.PageSize.H
 	c.stream.WriteString(fmt.Sprintf("q %0.2f 0 0 %0.2f %0.2f %0.2f cm /I%d Do Q\n", rect.W, rect.H, x, h-(y+rect.H), index+1))
 }
 
+//AppendStreamRotatedImage : draw image rotated by angle (radian , counter-clockwise) around anchorX,anchorY (pdf space)
+func (c *ContentObj) AppendStreamRotatedImage(index int, x float64, y float64, rect *Rect, angle float64, anchorX float64, anchorY float64) {
+	cos := math.Cos(angle)
+	sin := math.Sin(angle)
+	//เลื่อน anchor ไปที่ 0,0 หมุน แล้วเลื่อนกลับ
+	e := anchorX - anchorX*cos + anchorY*sin
+	f := anchorY - anchorX*sin - anchorY*cos
+	c.stream.WriteString("q\n")
+	c.stream.WriteString(fmt.Sprintf("%0.4f %0.4f %0.4f %0.4f %0.2f %0.2f cm\n", cos, sin, -sin, cos, e, f))
+	c.appendImageDo(index, x, y, rect)
+	c.stream.WriteString("Q\n")
+}
+
+//AppendStreamRotatedText : draw text with its origin at x,y (pdf space) rotated by angle (radian)
+func (c *ContentObj) AppendStreamRotatedText(x float64, y float64, angle float64, text string) {
+	fontSize := c.getRoot().Curr.Font_Size
+	cos := math.Cos(angle)
+	sin := math.Sin(angle)
+	c.stream.WriteString("q\n")
+	c.stream.WriteString(fmt.Sprintf("%0.4f %0.4f %0.4f %0.4f %0.2f %0.2f cm\n", cos, sin, -sin, cos, x, y))
+	c.stream.WriteString("BT\n")
+	c.stream.WriteString("/F" + strconv.Itoa(c.getRoot().Curr.Font_FontCount+1) + " " + strconv.Itoa(fontSize) + " Tf\n")
+	c.stream.WriteString(text + " Tj\n")
+	c.stream.WriteString("ET\n")
+	c.stream.WriteString("Q\n")
+}
+
+//graphicsStateBalance : count of q that has no Q (missing) and Q that has no q (extra)
+func graphicsStateBalance(stream []byte) (int, int) {
+	missing := 0
+	extra := 0
+	walkContentStream(stream, func(op string, operands []float64, operandStart int, end int) {
+		if op == "q" {
+			missing++
+		} else if op == "Q" {
+			if missing == 0 {
+				extra++
+			} else {
+				missing--
+			}
+		}
+	})
+	return missing, extra
+}
+
 //cal text height
 func ContentObj_CalTextHeight(fontsize int) float64 {
 	return (float64(fontsize) * 0.7)
@@ -0,0 +1,165 @@
+package gopdf
+
+import (
+	"bytes"
+	"strconv"
+)
+
+//walkContentStream : call fn for every operator in content stream ,
+//operands are the numbers just before the operator (nil if any other operand type is found) ,
+//operandStart is the position of first operand (-1 if none) and end is the position after operator
+func walkContentStream(stream []byte, fn func(op string, operands []float64, operandStart int, end int)) {
+	var operands []float64
+	operandStart := -1
+	i := 0
+	max := len(stream)
+	for i < max {
+		ch := stream[i]
+		if isPdfWhiteSpace(ch) {
+			i++
+			continue
+		}
+		start := i
+		if ch == '%' { //comment
+			for i < max && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+			continue
+		}
+
+		if ch == '(' || ch == '<' || ch == '[' || ch == '/' {
+			//operand ที่ไม่ใช่ตัวเลข
+			i = skipPdfOperand(stream, i)
+			operands = nil
+			operandStart = -1
+			continue
+		}
+
+		for i < max && !isPdfWhiteSpace(stream[i]) && !isPdfDelimiter(stream[i]) {
+			i++
+		}
+		if i == start { //delimiter ที่ไม่รู้จัก
+			i++
+			continue
+		}
+		token := string(stream[start:i])
+		if num, err := strconv.ParseFloat(token, 64); err == nil {
+			if operandStart == -1 {
+				operandStart = start
+			}
+			operands = append(operands, num)
+			continue
+		}
+		fn(token, operands, operandStart, i)
+		operands = nil
+		operandStart = -1
+	}
+}
+
+//contentStreamNames : add every name operand (without /) in content stream to names
+func contentStreamNames(stream []byte, names map[string]bool) {
+	i := 0
+	max := len(stream)
+	for i < max {
+		ch := stream[i]
+		if isPdfWhiteSpace(ch) {
+			i++
+		} else if ch == '/' {
+			end := skipPdfOperand(stream, i)
+			names[string(stream[i+1:end])] = true
+			i = end
+		} else if ch == '%' {
+			for i < max && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		} else if ch == '(' || ch == '<' || ch == '[' {
+			i = skipPdfOperand(stream, i)
+		} else {
+			start := i
+			for i < max && !isPdfWhiteSpace(stream[i]) && !isPdfDelimiter(stream[i]) {
+				i++
+			}
+			if i == start {
+				i++
+			}
+		}
+	}
+}
+
+//skipPdfOperand : skip string, hex string, dictionary, array or name start at i
+func skipPdfOperand(stream []byte, i int) int {
+	max := len(stream)
+	switch stream[i] {
+	case '(':
+		depth := 0
+		for i < max {
+			if stream[i] == '\\' {
+				i += 2
+				continue
+			}
+			if stream[i] == '(' {
+				depth++
+			} else if stream[i] == ')' {
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return max
+	case '<':
+		if i+1 < max && stream[i+1] == '<' {
+			return skipPdfNested(stream, i, "<<", ">>")
+		}
+		for i < max && stream[i] != '>' {
+			i++
+		}
+		return i + 1
+	case '[':
+		return skipPdfNested(stream, i, "[", "]")
+	}
+	i++ //name
+	for i < max && !isPdfWhiteSpace(stream[i]) && !isPdfDelimiter(stream[i]) {
+		i++
+	}
+	return i
+}
+
+func skipPdfNested(stream []byte, i int, open string, close string) int {
+	max := len(stream)
+	depth := 0
+	for i < max {
+		if stream[i] == '(' {
+			i = skipPdfOperand(stream, i)
+			continue
+		}
+		if bytes.HasPrefix(stream[i:], []byte(open)) {
+			depth++
+			i += len(open)
+			continue
+		}
+		if bytes.HasPrefix(stream[i:], []byte(close)) {
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return i
+			}
+			continue
+		}
+		i++
+	}
+	return max
+}
+
+func isPdfWhiteSpace(ch byte) bool {
+	return ch == ' ' || ch == '\n' || ch == '\r' || ch == '\t' || ch == '\f' || ch == 0
+}
+
+func isPdfDelimiter(ch byte) bool {
+	switch ch {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
@@ -30,6 +30,7 @@ type Current struct {
 }
 
 type ImageCache struct {
+	//Path : key of image (hash of image file data)
 	Path  string
 	Index int
 }
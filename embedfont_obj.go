@@ -2,6 +2,7 @@ package gopdf
 
 import (
 	"bytes"
+	"compress/zlib"
 	"io/ioutil"
 	"strconv"
 )
@@ -11,12 +12,17 @@ type EmbedFontObj struct {
 	Data      string
 	zfontpath string
 	font      IFont
+	//pfb : type1 font file (AddType1Font) , embedded instead of zfontpath
+	pfb *PFB
 }
 
 func (e *EmbedFontObj) Init(funcGetRoot func() *GoPdf) {
 }
 
 func (e *EmbedFontObj) Build() error {
+	if e.pfb != nil {
+		return e.buildPFB()
+	}
 	b, err := ioutil.ReadFile(e.zfontpath)
 	if err != nil {
 		return err
@@ -31,6 +37,34 @@ func (e *EmbedFontObj) Build() error {
 	return nil
 }
 
+//buildPFB : embed clear text , encrypted and trailer part of type1 font (/Length1 , /Length2 , /Length3)
+func (e *EmbedFontObj) buildPFB() error {
+	var data bytes.Buffer
+	data.Write(e.pfb.Clear)
+	data.Write(e.pfb.Encrypted)
+	data.Write(e.pfb.Trailer)
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	_, err := w.Write(data.Bytes())
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	e.buffer.WriteString("<</Length " + strconv.Itoa(b.Len()) + "\n")
+	e.buffer.WriteString("/Filter /FlateDecode\n")
+	e.buffer.WriteString("/Length1 " + strconv.Itoa(len(e.pfb.Clear)) + "\n")
+	e.buffer.WriteString("/Length2 " + strconv.Itoa(len(e.pfb.Encrypted)) + "\n")
+	e.buffer.WriteString("/Length3 " + strconv.Itoa(len(e.pfb.Trailer)) + "\n")
+	e.buffer.WriteString(">>\n")
+	e.buffer.WriteString("stream\n")
+	e.buffer.Write(b.Bytes())
+	e.buffer.WriteString("\nendstream\n")
+	return nil
+}
+
 func (e *EmbedFontObj) GetType() string {
 	return "EmbedFont"
 }
@@ -43,3 +77,9 @@ func (e *EmbedFontObj) SetFont(font IFont, zfontpath string) {
 	e.font = font
 	e.zfontpath = zfontpath
 }
+
+//SetPFB : embed type1 font file
+func (e *EmbedFontObj) SetPFB(font IFont, pfb *PFB) {
+	e.font = font
+	e.pfb = pfb
+}
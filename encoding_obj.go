@@ -7,6 +7,10 @@ import (
 type EncodingObj struct {
 	buffer bytes.Buffer
 	font   IFont
+
+	//baseEncoding , differences : set by SetFontEncoding ("" = WinAnsiEncoding with differences of font)
+	baseEncoding string
+	differences  []FontEncodingDifference
 }
 
 func (e *EncodingObj) Init(funcGetRoot func() *GoPdf) {
@@ -19,12 +23,32 @@ func (e *EncodingObj) GetObjBuff() *bytes.Buffer {
 	return &e.buffer
 }
 func (e *EncodingObj) Build() error {
+	if e.baseEncoding != "" {
+		e.buffer.WriteString("<</Type /Encoding /BaseEncoding /" + e.baseEncoding + " /Differences [")
+		e.buffer.WriteString(buildDifferences(e.differences))
+		e.buffer.WriteString("]>>\n")
+		return nil
+	}
 	e.buffer.WriteString("<</Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [")
 	e.buffer.WriteString(e.font.GetDiff())
 	e.buffer.WriteString("]>>\n")
 	return nil
 }
 
+//SetEncoding : set base encoding and custom differences (replace differences of font)
+func (e *EncodingObj) SetEncoding(baseEncoding string, differences []FontEncodingDifference) {
+	e.baseEncoding = baseEncoding
+	e.differences = differences
+}
+
+//EncodeText : convert text to codes of font , text is not converted if SetEncoding is not called
+func (e *EncodingObj) EncodeText(text string) string {
+	if e.baseEncoding == "" {
+		return text
+	}
+	return encodeSimpleText(text, e.baseEncoding, e.differences)
+}
+
 func (e *EncodingObj) SetFont(font IFont) {
 	e.font = font
 }
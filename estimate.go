@@ -0,0 +1,79 @@
+package gopdf
+
+import (
+	"os"
+)
+
+//ratio ของขนาดหลัง zlib โดยประมาณ
+const (
+	estimateFontCompressRatio   = 0.5
+	estimateStreamCompressRatio = 0.35
+	//estimateObjOverhead : "n 0 obj" , dictionary , "endobj" and xref entry of one obj
+	estimateObjOverhead = 100
+)
+
+//EstimateSize : rough size in bytes of pdf file if it is built now (without building it) ,
+//sum of content streams , image files and subset fonts with guessed compression ratios , real size may differ
+func (gp *GoPdf) EstimateSize() int64 {
+	size := int64(len("%PDF-1.7\n\n") + 100) //header and trailer
+	for _, obj := range gp.pdfObjs {
+		size += estimateObjOverhead
+		switch obj := obj.(type) {
+		case *ContentObj:
+			size += gp.estimateStreamSize(obj.stream.Len())
+		case *ImageObj:
+			size += fileSize(obj.imagepath)
+		case *EmbedFontObj:
+			if obj.pfb != nil {
+				size += int64(len(obj.pfb.Clear) + len(obj.pfb.Encrypted) + len(obj.pfb.Trailer))
+			} else {
+				size += fileSize(obj.zfontpath)
+			}
+		case *PdfDictionaryObj:
+			size += obj.estimateSize()
+		case *SubsetFontObj:
+			//ToUnicode และ /W ของแต่ละ glyph
+			size += int64(len(obj.UsedGlyphs()) * 40)
+		}
+	}
+	return size
+}
+
+//estimateStreamSize : size of stream of length n after compression of SetCompressLevel
+func (gp *GoPdf) estimateStreamSize(n int) int64 {
+	if gp.compressLevel == 0 || n < gp.minCompressSize {
+		return int64(n)
+	}
+	return int64(float64(n) * estimateStreamCompressRatio)
+}
+
+//estimateSize : size of compressed subset font , glyphs in subset and tables that are copied
+func (me *PdfDictionaryObj) estimateSize() int64 {
+	ttfp := me.PtrToSubsetFontObj.GetTTFParser()
+	size := 12
+	for _, tag := range []string{"cvt ", "fpgm", "gasp", "head", "hhea", "hmtx", "maxp", "prep"} {
+		if (tag == "cvt " || tag == "fpgm" || tag == "prep") && me.PtrToSubsetFontObj.subsetOption.StripHinting {
+			continue
+		}
+		if tag == "gasp" && !ttfp.HasTable(tag) {
+			continue
+		}
+		size += 16 + int(ttfp.GetTables()[tag].Length)
+	}
+	size += (int(ttfp.NumGlyphs()) + 1) * 4 //loca
+	for _, glyph := range me.PtrToSubsetFontObj.UsedGlyphs() {
+		if !me.isEmptyGlyph(int(glyph)) {
+			size += me.getGlyphSize(int(glyph))
+		}
+	}
+	return int64(float64(size) * estimateFontCompressRatio)
+}
+
+//fileSize : size of file at path , 0 if it can not be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,55 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//ExtGStateObj : graphics state parameter dictionary (/ExtGState)
+type ExtGStateObj struct {
+	buffer bytes.Buffer
+	params extGStateParams
+}
+
+//extGStateParams : parameters of ExtGState , each parameter combination has one ExtGStateObj
+type extGStateParams struct {
+	//overprint of fill (/op) and stroke (/OP)
+	overprintFill   bool
+	overprintStroke bool
+	//defaults : also set alpha (/CA , /ca) and blend mode (/BM) to default (ResetGraphicsState)
+	defaults bool
+}
+
+func (e extGStateParams) key() string {
+	return fmt.Sprintf("op=%t,OP=%t,defaults=%t", e.overprintFill, e.overprintStroke, e.defaults)
+}
+
+func (e *ExtGStateObj) Init(funcGetRoot func() *GoPdf) {
+}
+
+func (e *ExtGStateObj) Build() error {
+	e.buffer.WriteString("<<\n")
+	e.buffer.WriteString("/Type /ExtGState\n")
+	e.buffer.WriteString(fmt.Sprintf("/OP %t\n", e.params.overprintStroke))
+	e.buffer.WriteString(fmt.Sprintf("/op %t\n", e.params.overprintFill))
+	if e.params.overprintFill || e.params.overprintStroke {
+		e.buffer.WriteString("/OPM 1\n")
+	} else {
+		e.buffer.WriteString("/OPM 0\n")
+	}
+	if e.params.defaults {
+		e.buffer.WriteString("/CA 1\n")
+		e.buffer.WriteString("/ca 1\n")
+		e.buffer.WriteString("/BM /Normal\n")
+	}
+	e.buffer.WriteString(">>\n")
+	return nil
+}
+
+func (e *ExtGStateObj) GetType() string {
+	return "ExtGState"
+}
+
+func (e *ExtGStateObj) GetObjBuff() *bytes.Buffer {
+	return &(e.buffer)
+}
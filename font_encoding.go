@@ -0,0 +1,89 @@
+package gopdf
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//ErrUnknownFontEncoding : encoding name not support (WinAnsiEncoding , MacRomanEncoding)
+var ErrUnknownFontEncoding = errors.New("unknown font encoding")
+
+//ErrNotSimpleFont : font encoding can set only for simple font (AddFont) , not for ttf subset font
+var ErrNotSimpleFont = errors.New("font encoding is only for simple font")
+
+//FontEncodingDifference : map rune to code of simple font , GlyphName is the glyph of code (/Differences)
+type FontEncodingDifference struct {
+	Code      byte
+	Rune      rune
+	GlyphName string
+}
+
+//winAnsiEncoding : WinAnsiEncoding codes of runes that are not ascii or latin-1
+var winAnsiEncoding = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+//macRomanEncoding : MacRomanEncoding codes (0x80 - 0xFF) of pdf
+var macRomanEncoding = map[rune]byte{
+	'Ä': 0x80, 'Å': 0x81, 'Ç': 0x82, 'É': 0x83, 'Ñ': 0x84, 'Ö': 0x85, 'Ü': 0x86, 'á': 0x87,
+	'à': 0x88, 'â': 0x89, 'ä': 0x8A, 'ã': 0x8B, 'å': 0x8C, 'ç': 0x8D, 'é': 0x8E, 'è': 0x8F,
+	'ê': 0x90, 'ë': 0x91, 'í': 0x92, 'ì': 0x93, 'î': 0x94, 'ï': 0x95, 'ñ': 0x96, 'ó': 0x97,
+	'ò': 0x98, 'ô': 0x99, 'ö': 0x9A, 'õ': 0x9B, 'ú': 0x9C, 'ù': 0x9D, 'û': 0x9E, 'ü': 0x9F,
+	'†': 0xA0, '°': 0xA1, '¢': 0xA2, '£': 0xA3, '§': 0xA4, '•': 0xA5, '¶': 0xA6, 'ß': 0xA7,
+	'®': 0xA8, '©': 0xA9, '™': 0xAA, '´': 0xAB, '¨': 0xAC, 'Æ': 0xAE, 'Ø': 0xAF,
+	'±': 0xB1, '¥': 0xB4, 'µ': 0xB5, 'ª': 0xBB, 'º': 0xBC, 'æ': 0xBE, 'ø': 0xBF,
+	'¿': 0xC0, '¡': 0xC1, '¬': 0xC2, 'ƒ': 0xC4, '«': 0xC7, '»': 0xC8, '…': 0xC9,
+	'\u00A0': 0xCA, 'À': 0xCB, 'Ã': 0xCC, 'Õ': 0xCD, 'Œ': 0xCE, 'œ': 0xCF,
+	'–': 0xD0, '—': 0xD1, '“': 0xD2, '”': 0xD3, '‘': 0xD4, '’': 0xD5, '÷': 0xD6,
+	'ÿ': 0xD8, 'Ÿ': 0xD9, '⁄': 0xDA, '¤': 0xDB, '‹': 0xDC, '›': 0xDD, 'ﬁ': 0xDE, 'ﬂ': 0xDF,
+	'‡': 0xE0, '·': 0xE1, '‚': 0xE2, '„': 0xE3, '‰': 0xE4, 'Â': 0xE5, 'Ê': 0xE6, 'Á': 0xE7,
+	'Ë': 0xE8, 'È': 0xE9, 'Í': 0xEA, 'Î': 0xEB, 'Ï': 0xEC, 'Ì': 0xED, 'Ó': 0xEE, 'Ô': 0xEF,
+	'Ò': 0xF1, 'Ú': 0xF2, 'Û': 0xF3, 'Ù': 0xF4, 'ı': 0xF5, 'ˆ': 0xF6, '˜': 0xF7,
+	'¯': 0xF8, '˘': 0xF9, '˙': 0xFA, '˚': 0xFB, '¸': 0xFC, '˝': 0xFD, '˛': 0xFE, 'ˇ': 0xFF,
+}
+
+//encodeSimpleText : convert text to codes of base encoding and differences , rune that not in encoding become '?'
+func encodeSimpleText(text string, baseEncoding string, differences []FontEncodingDifference) string {
+	var buff strings.Builder
+	for _, r := range text {
+		buff.WriteByte(encodeSimpleRune(r, baseEncoding, differences))
+	}
+	return buff.String()
+}
+
+func encodeSimpleRune(r rune, baseEncoding string, differences []FontEncodingDifference) byte {
+	for _, diff := range differences {
+		if diff.Rune == r {
+			return diff.Code
+		}
+	}
+	if r < 0x80 {
+		return byte(r)
+	}
+	if baseEncoding == "MacRomanEncoding" {
+		if code, ok := macRomanEncoding[r]; ok {
+			return code
+		}
+		return '?'
+	}
+	if code, ok := winAnsiEncoding[r]; ok {
+		return code
+	}
+	if r >= 0xA0 && r <= 0xFF {
+		return byte(r)
+	}
+	return '?'
+}
+
+//buildDifferences : /Differences array of custom differences (sample 128 /Euro 150 /endash)
+func buildDifferences(differences []FontEncodingDifference) string {
+	var items []string
+	for _, diff := range differences {
+		items = append(items, strconv.Itoa(int(diff.Code))+" /"+diff.GlyphName)
+	}
+	return strings.Join(items, " ")
+}
@@ -0,0 +1,109 @@
+package gopdf
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testSimpleFont : IFont with width 500 for every code
+type testSimpleFont struct {
+	family string
+	cw     FontCw
+}
+
+func (f *testSimpleFont) Init() {
+	f.cw = make(FontCw)
+	for i := 0; i < 256; i++ {
+		f.cw[byte(i)] = 500
+	}
+}
+func (f *testSimpleFont) GetType() string         { return "TrueType" }
+func (f *testSimpleFont) GetName() string         { return "TestSimple" }
+func (f *testSimpleFont) GetDesc() []FontDescItem { return nil }
+func (f *testSimpleFont) GetUp() int              { return -100 }
+func (f *testSimpleFont) GetUt() int              { return 50 }
+func (f *testSimpleFont) GetCw() FontCw           { return f.cw }
+func (f *testSimpleFont) GetEnc() string          { return "cp1252" }
+func (f *testSimpleFont) GetDiff() string         { return "" }
+func (f *testSimpleFont) GetOriginalsize() int    { return 0 }
+func (f *testSimpleFont) SetFamily(family string) { f.family = family }
+func (f *testSimpleFont) GetFamily() string       { return f.family }
+
+func newTestSimpleFontPdf(t *testing.T) *GoPdf {
+	zfontpath := filepath.Join(t.TempDir(), "test.z")
+	err := ioutil.WriteFile(zfontpath, []byte{}, 0644)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	pdf.AddFont("simple", &testSimpleFont{}, zfontpath)
+	err = pdf.SetFont("simple", "", 14)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return &pdf
+}
+
+func TestSetFontEncoding(t *testing.T) {
+	pdf := newTestSimpleFontPdf(t)
+	err := pdf.SetFontEncoding("WinAnsiEncoding")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	startX := pdf.GetX()
+	pdf.Cell(nil, "5€ “a” — (é)")
+	content := pdf.getContent().stream.String()
+	if !strings.Contains(content, "(5\x80 \x93a\x94 \x97 \\(\xe9\\)) Tj\n") {
+		t.Errorf("text not encoded in WinAnsiEncoding\n%q", content)
+	}
+	if pdf.GetX()-startX != 12*500*14/1000.0 {
+		t.Errorf("width must be measured by encoded codes but got %f", pdf.GetX()-startX)
+	}
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "<</Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences []>>\n") {
+		t.Errorf("encoding obj not found")
+	}
+	if !strings.Contains(s, "  /Subtype /TrueType\n  /BaseFont /TestSimple\n") || !strings.Contains(s, "  /Encoding ") {
+		t.Errorf("font dict must refer to encoding")
+	}
+
+	err = pdf.SetFontEncoding("MacRomanEncoding", FontEncodingDifference{Code: 0xDB, Rune: '€', GlyphName: "Euro"})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Cell(nil, "€é")
+	if !strings.HasSuffix(pdf.getContent().stream.String(), "(\xdb\x8e) Tj\nET\n") {
+		t.Errorf("text not encoded in MacRomanEncoding with differences")
+	}
+	if !strings.Contains(string(pdf.GetBytesPdf()), "/BaseEncoding /MacRomanEncoding /Differences [219 /Euro]>>") {
+		t.Errorf("differences not found")
+	}
+
+	if pdf.SetFontEncoding("Cp874") != ErrUnknownFontEncoding {
+		t.Errorf("expect ErrUnknownFontEncoding")
+	}
+	if newTestPdf(t).SetFontEncoding("WinAnsiEncoding") != ErrNotSimpleFont {
+		t.Errorf("expect ErrNotSimpleFont")
+	}
+}
+
+func TestCanRenderSimpleFontEncoding(t *testing.T) {
+	pdf := newTestSimpleFontPdf(t)
+	if !pdf.CanRender('a') || pdf.CanRender('é') {
+		t.Errorf("font without encoding can render only ascii")
+	}
+	err := pdf.SetFontEncoding("MacRomanEncoding", FontEncodingDifference{Code: 0xDB, Rune: '€', GlyphName: "Euro"})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if !pdf.CanRender('€') || !pdf.CanRender('é') || !pdf.CanRender('?') {
+		t.Errorf("runes of encoding and differences must be rendered")
+	}
+	if pdf.CanRender('Ā') {
+		t.Errorf("rune that is not in encoding must not be rendered")
+	}
+}
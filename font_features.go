@@ -0,0 +1,127 @@
+package gopdf
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+//shapedGlyph : glyph to draw and runes of text it represents
+type shapedGlyph struct {
+	glyphIndex uint64
+	runes      []rune
+	//width : width in 1/1000 of font size
+	width uint64
+	//substituted : glyph come from GSUB (not cmap)
+	substituted bool
+}
+
+//SetFontFeatures : turn opentype features of ttf fonts on or off for text drawn after this (sample {"liga": true, "smcp": false}) ,
+//single and ligature substitutions of GSUB in enabled features are applied , unknown or unsupported features are ignored
+func (gp *GoPdf) SetFontFeatures(features map[string]bool) {
+	gp.fontFeatures = make(map[string]bool)
+	for feature, enabled := range features {
+		gp.fontFeatures[feature] = enabled
+	}
+	gp.ClearMeasureCache()
+}
+
+//enabledFontFeatures : sorted features that are turned on
+func (gp *GoPdf) enabledFontFeatures() []string {
+	var features []string
+	for feature, enabled := range gp.fontFeatures {
+		if enabled {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+//shapeCurrText : glyphs of text in current subset font with enabled features applied
+func (gp *GoPdf) shapeCurrText(text string) []shapedGlyph {
+	subset := gp.Curr.Font_ISubset
+	var glyphs []shapedGlyph
+	for _, r := range text {
+		glyphs = append(glyphs, shapedGlyph{
+			glyphIndex: subset.CharCodeToGlyphIndex(r),
+			runes:      []rune{r},
+			width:      subset.RuneWidth(r),
+		})
+	}
+	features := gp.enabledFontFeatures()
+	if sub, ok := subset.(*SubsetFontObj); ok && len(features) > 0 {
+		glyphs = sub.substitute(glyphs, features)
+	}
+	return glyphs
+}
+
+//substitute : apply lookups of features to glyphs in order of LookupList
+func (s *SubsetFontObj) substitute(glyphs []shapedGlyph, features []string) []shapedGlyph {
+	key := strings.Join(features, ",")
+	lookups, ok := s.gsubLookups[key]
+	if !ok {
+		lookups = s.ttfp.GSUBLookups(features)
+		s.gsubLookups[key] = lookups
+	}
+	for _, lookup := range lookups {
+		var result []shapedGlyph
+		for i := 0; i < len(glyphs); i++ {
+			glyph := glyphs[i]
+			if substitute, ok := lookup.SingleSubstitutions[glyph.glyphIndex]; ok {
+				glyph = s.substitutedGlyph(substitute, glyph.runes)
+			} else if ligature, n := matchLigature(lookup.Ligatures[glyph.glyphIndex], glyphs[i+1:]); n > 0 {
+				var runes []rune
+				for _, component := range glyphs[i : i+1+n] {
+					runes = append(runes, component.runes...)
+				}
+				glyph = s.substitutedGlyph(ligature.Glyph, runes)
+				i += n
+			}
+			result = append(result, glyph)
+		}
+		glyphs = result
+	}
+	return glyphs
+}
+
+//substitutedGlyph : glyph from GSUB that represents runes
+func (s *SubsetFontObj) substitutedGlyph(glyphIndex uint64, runes []rune) shapedGlyph {
+	return shapedGlyph{
+		glyphIndex:  glyphIndex,
+		runes:       runes,
+		width:       s.GlyphIndexToPdfWidth(glyphIndex),
+		substituted: true,
+	}
+}
+
+//addSubstitutedGlyphs : add glyphs from GSUB to subset (glyphs from cmap are added by AddChars)
+func (s *SubsetFontObj) addSubstitutedGlyphs(glyphs []shapedGlyph) {
+	for _, glyph := range glyphs {
+		if _, ok := s.substitutedGlyphs[glyph.glyphIndex]; glyph.substituted && !ok {
+			s.substitutedGlyphs[glyph.glyphIndex] = glyph.runes
+		}
+	}
+}
+
+//matchLigature : first ligature whose components are at start of next , and count of components
+func matchLigature(ligatures []core.Ligature, next []shapedGlyph) (core.Ligature, int) {
+	for _, ligature := range ligatures {
+		n := len(ligature.Components)
+		if n == 0 || n > len(next) {
+			continue
+		}
+		matched := true
+		for i, component := range ligature.Components {
+			if next[i].glyphIndex != component {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return ligature, n
+		}
+	}
+	return core.Ligature{}, 0
+}
@@ -28,13 +28,17 @@ func (f *FontObj) Init(funcGetRoot func() *GoPdf) {
 func (f *FontObj) Build() error {
 
 	baseFont := f.Family
+	subtype := "TrueType"
 	if f.Font != nil {
 		baseFont = f.Font.GetName()
+		if f.Font.GetType() == "Type1" {
+			subtype = "Type1"
+		}
 	}
 
 	f.buffer.WriteString("<<\n")
 	f.buffer.WriteString("  /Type /" + f.GetType() + "\n")
-	f.buffer.WriteString("  /Subtype /TrueType\n")
+	f.buffer.WriteString("  /Subtype /" + subtype + "\n")
 	f.buffer.WriteString("  /BaseFont /" + baseFont + "\n")
 	if f.IsEmbedFont {
 		f.buffer.WriteString("  /FirstChar 32 /LastChar 255\n")
@@ -0,0 +1,83 @@
+package gopdf
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+//FontOption : option of AddTTFFontWithOption and AddFontWithOption ,
+//descriptor values that are not nil replace the values read from font (escape hatch for fonts that report wrong metrics)
+type FontOption struct {
+	Flags       *int
+	ItalicAngle *float64
+	//CapHeight , StemV , FontBBox : in glyph space of pdf (1/1000 of font size)
+	CapHeight *int
+	StemV     *int
+	FontBBox  *[4]int
+	//Embed : nil or true embed font file , false write only /BaseFont name and descriptor (font must be installed in viewer)
+	Embed *bool
+	//Subset : option of subset of ttf font
+	Subset SubsetOption
+	//PreferSimple : ttf font is written as simple TrueType font (1 byte codes of WinAnsiEncoding , smaller and read by old viewers)
+	//when every drawn rune is in WinAnsiEncoding , otherwise as Type0 font
+	PreferSimple bool
+}
+
+//SubsetOption : option of glyphs in subset of ttf font
+type SubsetOption struct {
+	//ReplaceNotdefWithEmpty : keep glyph 0 (.notdef , required) without outline , missing glyphs are drawn as blank instead of box
+	ReplaceNotdefWithEmpty bool
+	//StripHinting : do not copy hinting tables (cvt , fpgm and prep) to subset , smaller font but worse rendering at small sizes
+	StripHinting bool
+}
+
+//equal : same values of options (values of pointers are compared)
+func (f FontOption) equal(o FontOption) bool {
+	return reflect.DeepEqual(f, o)
+}
+
+//embed : font file must be embedded
+func (f FontOption) embed() bool {
+	return f.Embed == nil || *f.Embed
+}
+
+//descItems : descriptor entries overridden by option
+func (f FontOption) descItems() []FontDescItem {
+	var items []FontDescItem
+	if f.Flags != nil {
+		items = append(items, FontDescItem{Key: "Flags", Val: strconv.Itoa(*f.Flags)})
+	}
+	if f.ItalicAngle != nil {
+		items = append(items, FontDescItem{Key: "ItalicAngle", Val: strconv.FormatFloat(*f.ItalicAngle, 'f', -1, 64)})
+	}
+	if f.CapHeight != nil {
+		items = append(items, FontDescItem{Key: "CapHeight", Val: strconv.Itoa(*f.CapHeight)})
+	}
+	if f.StemV != nil {
+		items = append(items, FontDescItem{Key: "StemV", Val: strconv.Itoa(*f.StemV)})
+	}
+	if f.FontBBox != nil {
+		bbox := *f.FontBBox
+		items = append(items, FontDescItem{Key: "FontBBox", Val: fmt.Sprintf("[%d %d %d %d]", bbox[0], bbox[1], bbox[2], bbox[3])})
+	}
+	return items
+}
+
+//mergeFontDesc : descs with entries of option replaced (or added when font has no such entry) , descs is not modified
+func mergeFontDesc(descs []FontDescItem, option FontOption) []FontDescItem {
+	merged := append([]FontDescItem(nil), descs...)
+	for _, item := range option.descItems() {
+		found := false
+		for i := range merged {
+			if merged[i].Key == item.Key {
+				merged[i].Val = item.Val
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
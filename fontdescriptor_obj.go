@@ -8,6 +8,7 @@ type FontDescriptorObj struct {
 	buffer            bytes.Buffer
 	font              IFont
 	fontFileObjRelate string
+	fontOption        FontOption
 }
 
 func (f *FontDescriptorObj) Init(funcGetRoot func() *GoPdf) {
@@ -17,7 +18,7 @@ func (f *FontDescriptorObj) Init(funcGetRoot func() *GoPdf) {
 func (f *FontDescriptorObj) Build() error {
 
 	f.buffer.WriteString("<</Type /FontDescriptor /FontName /" + f.font.GetName() + " ")
-	descs := f.font.GetDesc()
+	descs := mergeFontDesc(f.font.GetDesc(), f.fontOption)
 	i := 0
 	max := len(descs)
 	for i < max {
@@ -25,13 +26,15 @@ func (f *FontDescriptorObj) Build() error {
 		i++
 	}
 
-	if f.GetType() == "Type1" {
-		f.buffer.WriteString("/FontFile ")
-	} else {
-		f.buffer.WriteString("/FontFile2 ")
+	//ไม่ embed font ไม่มี font file
+	if f.fontFileObjRelate != "" {
+		if f.font.GetType() == "Type1" {
+			f.buffer.WriteString("/FontFile ")
+		} else {
+			f.buffer.WriteString("/FontFile2 ")
+		}
+		f.buffer.WriteString(f.fontFileObjRelate)
 	}
-
-	f.buffer.WriteString(f.fontFileObjRelate)
 	f.buffer.WriteString(">>\n")
 
 	return nil
@@ -56,3 +59,8 @@ func (f *FontDescriptorObj) GetFont() IFont {
 func (f *FontDescriptorObj) SetFontFileObjRelate(relate string) {
 	f.fontFileObjRelate = relate
 }
+
+//SetFontOption : set option that override values of GetDesc
+func (f *FontDescriptorObj) SetFontOption(option FontOption) {
+	f.fontOption = option
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"sort"
+)
+
+//ForegroundPaletteIndex : PaletteIndex of ColorLayer that is painted with current text color
+const ForegroundPaletteIndex = 0xFFFF
+
+//ColorLayer : glyph painted with color of palette as one layer of color glyph (layer record of COLR table version 0)
+type ColorLayer struct {
+	GlyphIndex   uint64
+	PaletteIndex int
+}
+
+//PaletteColor : color of CPAL palette
+type PaletteColor struct {
+	R uint8
+	G uint8
+	B uint8
+	A uint8
+}
+
+//ColorLayers : layers of color glyph from bottom to top , nil if font has no COLR table or glyph is not color glyph
+func (me *TTFParser) ColorLayers(glyphIndex uint64) []ColorLayer {
+	table, ok := me.tables["COLR"]
+	if !ok {
+		return nil
+	}
+	g := gsubReader{data: me.cahceFontData}
+	start := int(table.Offset)
+	numBaseGlyphs := g.ushort(start + 2)
+	baseGlyphs := start + int(g.ulong(start+4))
+	layers := start + int(g.ulong(start+8))
+	numLayers := g.ushort(start + 12)
+
+	//base glyph record เรียงตาม glyph id
+	i := sort.Search(numBaseGlyphs, func(i int) bool {
+		return uint64(g.ushort(baseGlyphs+i*6)) >= glyphIndex
+	})
+	if i >= numBaseGlyphs || uint64(g.ushort(baseGlyphs+i*6)) != glyphIndex {
+		return nil
+	}
+	first := g.ushort(baseGlyphs + i*6 + 2)
+	count := g.ushort(baseGlyphs + i*6 + 4)
+	var result []ColorLayer
+	for j := first; j < first+count && j < numLayers; j++ {
+		record := layers + j*4
+		result = append(result, ColorLayer{
+			GlyphIndex:   uint64(g.ushort(record)),
+			PaletteIndex: g.ushort(record + 2),
+		})
+	}
+	return result
+}
+
+//PaletteColors : colors of palette of CPAL table , nil if font has no CPAL table or no such palette
+func (me *TTFParser) PaletteColors(palette int) []PaletteColor {
+	table, ok := me.tables["CPAL"]
+	if !ok {
+		return nil
+	}
+	g := gsubReader{data: me.cahceFontData}
+	start := int(table.Offset)
+	numEntries := g.ushort(start + 2)
+	numPalettes := g.ushort(start + 4)
+	records := start + int(g.ulong(start+8))
+	if palette < 0 || palette >= numPalettes {
+		return nil
+	}
+	first := g.ushort(start + 12 + palette*2)
+	colors := make([]PaletteColor, numEntries)
+	for i := range colors {
+		record := records + (first+i)*4
+		if record+4 > len(me.cahceFontData) {
+			return nil
+		}
+		//color record เป็น BGRA
+		b := me.cahceFontData[record : record+4]
+		colors[i] = PaletteColor{R: b[2], G: b[1], B: b[0], A: b[3]}
+	}
+	return colors
+}
@@ -325,7 +325,7 @@ func (f *FontMaker) MakeWidthArray(widths map[int]int64) (string, error) {
 	str := "\tme.cw = make(gopdf.FontCw)\n"
 	for c := 0; c <= 255; c++ {
 		str += "\tme.cw["
-		chr := string(c)
+		chr := string(rune(c))
 		if chr == "\"" {
 			str += "gopdf.ToByte(\"\\\"\")"
 		} else if chr == "\\" {
@@ -398,7 +398,7 @@ func (f *FontMaker) GetInfoFromTrueType(fontpath string, fontmaps []FontMap) (Tt
 		f.MultiplyAndRound(k, parser.yMax),
 	}
 	info.PushInt64s("FontBBox", fontBBoxs)
-	info.PushInt64("CapHeight", f.MultiplyAndRound(k, parser.capHeight))
+	info.PushInt64("CapHeight", f.MultiplyAndRound(k, parser.CapHeight()))
 	missingWidth := f.MultiplyAndRoundWithUInt64(k, parser.widths[0])
 	info.PushInt64("MissingWidth", missingWidth)
 
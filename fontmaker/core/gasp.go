@@ -0,0 +1,40 @@
+package core
+
+//behavior flags of GaspRange
+const (
+	GaspGridfit            = 0x0001
+	GaspDoGray             = 0x0002
+	GaspSymmetricGridfit   = 0x0004
+	GaspSymmetricSmoothing = 0x0008
+)
+
+//GaspRange : rendering behavior (grid-fitting , anti-aliasing) of sizes up to MaxPPEM (record of gasp table)
+type GaspRange struct {
+	//MaxPPEM : upper limit (inclusive) of size in pixels per em of this range , 0xFFFF = all larger sizes
+	MaxPPEM uint16
+	//Behavior : GaspGridfit , GaspDoGray , GaspSymmetricGridfit and GaspSymmetricSmoothing flags
+	Behavior uint16
+}
+
+//GaspRanges : size ranges of gasp table in order of MaxPPEM , nil if font has no gasp table
+func (me *TTFParser) GaspRanges() []GaspRange {
+	table, ok := me.tables["gasp"]
+	if !ok {
+		return nil
+	}
+	g := gsubReader{data: me.cahceFontData}
+	start := int(table.Offset)
+	count := g.ushort(start + 2)
+	if uint64(4+count*4) > table.Length {
+		return nil
+	}
+	ranges := make([]GaspRange, count)
+	for i := range ranges {
+		record := start + 4 + i*4
+		ranges[i] = GaspRange{
+			MaxPPEM:  uint16(g.ushort(record)),
+			Behavior: uint16(g.ushort(record + 2)),
+		}
+	}
+	return ranges
+}
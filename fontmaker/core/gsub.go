@@ -0,0 +1,173 @@
+package core
+
+import (
+	"sort"
+)
+
+//Ligature : glyphs (first glyph and Components) replaced by Glyph
+type Ligature struct {
+	Components []uint64
+	Glyph      uint64
+}
+
+//GSUBLookup : substitutions of one lookup in GSUB table (only single and ligature substitution are supported)
+type GSUBLookup struct {
+	//SingleSubstitutions : glyph to glyph (lookup type 1)
+	SingleSubstitutions map[uint64]uint64
+	//Ligatures : first glyph to ligatures start with it in order of preference (lookup type 4)
+	Ligatures map[uint64][]Ligature
+}
+
+//GSUBLookups : lookups of GSUB used by features (sample "liga" , "smcp") in order of LookupList ,
+//features of every script and language are used , lookups of other types are skipped
+func (me *TTFParser) GSUBLookups(features []string) []GSUBLookup {
+	table, ok := me.tables["GSUB"]
+	if !ok {
+		return nil
+	}
+	g := gsubReader{data: me.cahceFontData}
+	start := int(table.Offset)
+	featureList := start + g.ushort(start+6)
+	lookupList := start + g.ushort(start+8)
+
+	wanted := make(map[string]bool)
+	for _, feature := range features {
+		wanted[feature] = true
+	}
+	found := make(map[int]bool)
+	var lookupIndexes []int
+	count := g.ushort(featureList)
+	for i := 0; i < count; i++ {
+		record := featureList + 2 + i*6
+		if !wanted[g.tag(record)] {
+			continue
+		}
+		feature := featureList + g.ushort(record+4)
+		lookupCount := g.ushort(feature + 2)
+		for j := 0; j < lookupCount; j++ {
+			index := g.ushort(feature + 4 + j*2)
+			if !found[index] {
+				found[index] = true
+				lookupIndexes = append(lookupIndexes, index)
+			}
+		}
+	}
+	sort.Ints(lookupIndexes)
+
+	var lookups []GSUBLookup
+	for _, index := range lookupIndexes {
+		if index >= g.ushort(lookupList) {
+			continue
+		}
+		lookup := lookupList + g.ushort(lookupList+2+index*2)
+		lookupType := g.ushort(lookup)
+		subTableCount := g.ushort(lookup + 4)
+		result := GSUBLookup{
+			SingleSubstitutions: make(map[uint64]uint64),
+			Ligatures:           make(map[uint64][]Ligature),
+		}
+		for j := 0; j < subTableCount; j++ {
+			subTable := lookup + g.ushort(lookup+6+j*2)
+			subTableType := lookupType
+			if lookupType == 7 { //extension
+				subTableType = g.ushort(subTable + 2)
+				subTable += int(g.ulong(subTable + 4))
+			}
+			if subTableType == 1 {
+				g.singleSubstitutions(subTable, result.SingleSubstitutions)
+			} else if subTableType == 4 {
+				g.ligatures(subTable, result.Ligatures)
+			}
+		}
+		if len(result.SingleSubstitutions) > 0 || len(result.Ligatures) > 0 {
+			lookups = append(lookups, result)
+		}
+	}
+	return lookups
+}
+
+//gsubReader : read big-endian values of font data , out of range read 0
+type gsubReader struct {
+	data []byte
+}
+
+func (g gsubReader) ushort(i int) int {
+	if i < 0 || i+2 > len(g.data) {
+		return 0
+	}
+	return int(g.data[i])<<8 | int(g.data[i+1])
+}
+
+func (g gsubReader) ulong(i int) uint64 {
+	return uint64(g.ushort(i))<<16 | uint64(g.ushort(i+2))
+}
+
+func (g gsubReader) tag(i int) string {
+	if i < 0 || i+4 > len(g.data) {
+		return ""
+	}
+	return string(g.data[i : i+4])
+}
+
+//coverage : glyphs of coverage table in order of coverage index
+func (g gsubReader) coverage(offset int) []uint64 {
+	var glyphs []uint64
+	format := g.ushort(offset)
+	count := g.ushort(offset + 2)
+	if format == 1 {
+		for i := 0; i < count; i++ {
+			glyphs = append(glyphs, uint64(g.ushort(offset+4+i*2)))
+		}
+	} else if format == 2 {
+		for i := 0; i < count; i++ {
+			record := offset + 4 + i*6
+			startGlyph := g.ushort(record)
+			endGlyph := g.ushort(record + 2)
+			for glyph := startGlyph; glyph <= endGlyph; glyph++ {
+				glyphs = append(glyphs, uint64(glyph))
+			}
+		}
+	}
+	return glyphs
+}
+
+//singleSubstitutions : read single substitution subtable (format 1 delta or format 2 array)
+func (g gsubReader) singleSubstitutions(offset int, result map[uint64]uint64) {
+	format := g.ushort(offset)
+	coverage := g.coverage(offset + g.ushort(offset+2))
+	if format == 1 {
+		delta := g.ushort(offset + 4)
+		for _, glyph := range coverage {
+			result[glyph] = uint64((int(glyph) + delta) & 0xFFFF)
+		}
+	} else if format == 2 {
+		count := g.ushort(offset + 4)
+		for i, glyph := range coverage {
+			if i < count {
+				result[glyph] = uint64(g.ushort(offset + 6 + i*2))
+			}
+		}
+	}
+}
+
+//ligatures : read ligature substitution subtable
+func (g gsubReader) ligatures(offset int, result map[uint64][]Ligature) {
+	coverage := g.coverage(offset + g.ushort(offset+2))
+	setCount := g.ushort(offset + 4)
+	for i, glyph := range coverage {
+		if i >= setCount {
+			break
+		}
+		set := offset + g.ushort(offset+6+i*2)
+		count := g.ushort(set)
+		for j := 0; j < count; j++ {
+			ligature := set + g.ushort(set+2+j*2)
+			componentCount := g.ushort(ligature + 2)
+			var components []uint64
+			for k := 1; k < componentCount; k++ {
+				components = append(components, uint64(g.ushort(ligature+4+(k-1)*2)))
+			}
+			result[glyph] = append(result[glyph], Ligature{Components: components, Glyph: uint64(g.ushort(ligature))})
+		}
+	}
+}
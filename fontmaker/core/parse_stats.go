@@ -0,0 +1,51 @@
+package core
+
+import (
+	"time"
+)
+
+//ParseStats : size of tables and time of Parse* steps of last Parse
+type ParseStats struct {
+	//TableSizes : Length of each table in table directory by tag
+	TableSizes map[string]uint64
+	//StepDurations : time spent in each Parse* step (sample "ParseCmap") , nil if SetProfiling is not on
+	StepDurations map[string]time.Duration
+}
+
+//LargestTable : tag of largest table ("" if font has no table)
+func (p ParseStats) LargestTable() string {
+	largest := ""
+	for tag, size := range p.TableSizes {
+		if largest == "" || size > p.TableSizes[largest] || (size == p.TableSizes[largest] && tag < largest) {
+			largest = tag
+		}
+	}
+	return largest
+}
+
+//SetProfiling : time Parse* steps of next Parse for Stats (off by default)
+func (me *TTFParser) SetProfiling(enabled bool) {
+	me.profiling = enabled
+}
+
+//Stats : size of tables and (if SetProfiling is on) time of Parse* steps of last Parse
+func (me *TTFParser) Stats() ParseStats {
+	stats := ParseStats{TableSizes: make(map[string]uint64)}
+	for tag, table := range me.tables {
+		stats.TableSizes[tag] = table.Length
+	}
+	if me.stepDurations != nil {
+		stats.StepDurations = make(map[string]time.Duration)
+		for name, duration := range me.stepDurations {
+			stats.StepDurations[name] = duration
+		}
+	}
+	return stats
+}
+
+func (me *TTFParser) addStepDuration(name string, duration time.Duration) {
+	if me.stepDurations == nil {
+		me.stepDurations = make(map[string]time.Duration)
+	}
+	me.stepDurations[name] += duration
+}
@@ -1,16 +1,21 @@
 package core
 
 import (
+	"bytes"
 	//"encoding/binary"
 	//"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"math/big"
-	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf16"
 )
 
 var ERROR_NO_UNICODE_ENCODING_FOUND = errors.New("No Unicode encoding found")
@@ -18,6 +23,34 @@ var ERROR_UNEXPECTED_SUBTABLE_FORMAT = errors.New("Unexpected subtable format")
 var ERROR_INCORRECT_MAGIC_NUMBER = errors.New("Incorrect magic number")
 var ERROR_POSTSCRIPT_NAME_NOT_FOUND = errors.New("PostScript name not found")
 
+//ErrMissingTable : font has no table that parser needs , returned error wraps it with tag of table (check by errors.Is)
+var ErrMissingTable = errors.New("table not found")
+
+//ErrUnrecognizedFontFormat : file is not truetype or opentype font
+var ErrUnrecognizedFontFormat = errors.New("Unrecognized file (font) format")
+
+//ErrFileOutOfLength : file ends before data that is read
+var ErrFileOutOfLength = errors.New("file out of length")
+
+//ErrLocaGlyphCount : loca table has less than numGlyphs + 1 entries (corrupt font) , returned error wraps it with counts
+var ErrLocaGlyphCount = errors.New("loca entries do not match number of glyphs")
+
+//Err names of ERROR_ errors (same values , for errors.Is)
+var (
+	ErrNoUnicodeEncoding      = ERROR_NO_UNICODE_ENCODING_FOUND
+	ErrUnexpectedSubtable     = ERROR_UNEXPECTED_SUBTABLE_FORMAT
+	ErrIncorrectMagicNumber   = ERROR_INCORRECT_MAGIC_NUMBER
+	ErrPostScriptNameNotFound = ERROR_POSTSCRIPT_NAME_NOT_FOUND
+	ErrByteSwappedOrCorrupt   = ERROR_BYTE_SWAPPED_OR_CORRUPT
+	ErrNoGlyfTable            = ERROR_NO_GLYF_TABLE
+)
+
+//ERROR_NO_GLYF_TABLE : font has no glyf and loca table (CFF outlines) , glyphs can not be read or subset
+var ERROR_NO_GLYF_TABLE = errors.New("font has no glyf table")
+
+//ERROR_BYTE_SWAPPED_OR_CORRUPT : values in head table are implausible (little-endian / byte-swapped or damaged file)
+var ERROR_BYTE_SWAPPED_OR_CORRUPT = errors.New("font appears byte-swapped or corrupt")
+
 type TTFParser struct {
 	tables map[string]TableDirectoryEntry
 	//head
@@ -31,12 +64,18 @@ type TTFParser struct {
 	numberOfHMetrics uint64
 	ascender         int64
 	descender        int64
+	lineGap          int64
 	//end Hhea
 
 	numGlyphs      uint64
 	widths         []uint64
 	chars          map[int]uint64
 	postScriptName string
+	//name id 1 , 2 , 16 , 17 of name table
+	familyName         string
+	subfamilyName      string
+	preferredFamily    string
+	preferredSubfamily string
 
 	//os2
 	os2Version    uint64
@@ -53,6 +92,9 @@ type TTFParser struct {
 	underlineThickness int64
 	isFixedPitch       bool
 	sTypoLineGap       int64
+	useTypoMetrics     bool
+	strikeoutSize      int64
+	strikeoutPosition  int64
 	usWinAscent        uint64
 	usWinDescent       uint64
 
@@ -68,8 +110,12 @@ type TTFParser struct {
 	symbol        bool
 	//data of font
 	cahceFontData []byte
+	//profiling : time Parse* steps for Stats
+	profiling     bool
+	stepDurations map[string]time.Duration
 }
 
+var FixedPitch = 1 << 0
 var Symbolic = 1 << 2
 var Nonsymbolic = (1 << 5)
 
@@ -109,8 +155,29 @@ func (me *TTFParser) ItalicAngle() int64 {
 	return me.italicAngle
 }
 
+//IsFixedPitch : true if font is monospaced (isFixedPitch of post table or every glyph has the same advance width)
+func (me *TTFParser) IsFixedPitch() bool {
+	if me.isFixedPitch {
+		return true
+	}
+	advance := uint64(0)
+	for _, width := range me.widths {
+		if width == 0 {
+			continue
+		}
+		if advance != 0 && width != advance {
+			return false
+		}
+		advance = width
+	}
+	return advance != 0
+}
+
 func (me *TTFParser) Flag() int {
 	flag := 0
+	if me.IsFixedPitch() {
+		flag |= FixedPitch
+	}
 	if me.symbol {
 		flag |= Symbolic
 	} else {
@@ -137,6 +204,21 @@ func (me *TTFParser) Descender() int64 {
 	return descender
 }
 
+//StrikeoutSize : thickness of strikeout line (yStrikeoutSize of OS/2 table)
+func (me *TTFParser) StrikeoutSize() int64 {
+	return me.strikeoutSize
+}
+
+//StrikeoutPosition : position of top of strikeout line above baseline (yStrikeoutPosition of OS/2 table)
+func (me *TTFParser) StrikeoutPosition() int64 {
+	return me.strikeoutPosition
+}
+
+//LineGap : line gap from hhea table
+func (me *TTFParser) LineGap() int64 {
+	return me.lineGap
+}
+
 func (me *TTFParser) TypoAscender() int64 {
 	return me.typoAscender
 }
@@ -145,9 +227,51 @@ func (me *TTFParser) TypoDescender() int64 {
 	return me.typoDescender
 }
 
+//TypoLineGap : sTypoLineGap of OS/2 table
+func (me *TTFParser) TypoLineGap() int64 {
+	return me.sTypoLineGap
+}
+
+//UseTypoMetrics : bit 7 (USE_TYPO_METRICS) of fsSelection of OS/2 table is set ,
+//line height should be computed from typo ascender , descender and line gap instead of win (or hhea) metrics
+func (me *TTFParser) UseTypoMetrics() bool {
+	return me.useTypoMetrics
+}
+
+//CapHeight : sCapHeight of OS/2 , when font has no cap height (OS/2 version < 2 or 0) use top of glyph 'H' or ascender if font has no 'H'
 func (me *TTFParser) CapHeight() int64 {
 	//fmt.Printf("\n\n>>>>>%d\n\n\n", me.capHeight)
-	return me.capHeight
+	if me.capHeight != 0 {
+		return me.capHeight
+	}
+	if glyph, ok := me.chars['H']; ok {
+		if bounds, ok := me.GlyphBounds(glyph); ok && bounds.YMax > 0 {
+			return bounds.YMax
+		}
+	}
+	return me.ascender
+}
+
+//BBox : bounding box in font design units
+type BBox struct {
+	XMin, YMin, XMax, YMax int64
+}
+
+//GlyphBounds : bounding box of glyph from header of glyf table , false if glyph has no outline or font has no glyf table
+func (me *TTFParser) GlyphBounds(glyph uint64) (BBox, bool) {
+	glyf, ok := me.tables["glyf"]
+	if !ok || glyph+1 >= uint64(len(me.LocaTable)) || me.LocaTable[glyph] >= me.LocaTable[glyph+1] {
+		return BBox{}, false
+	}
+	offset := int(glyf.Offset + me.LocaTable[glyph])
+	if offset+10 > len(me.cahceFontData) {
+		return BBox{}, false
+	}
+	short := func(i int) int64 {
+		return int64(int16(uint16(me.cahceFontData[offset+i])<<8 | uint16(me.cahceFontData[offset+i+1])))
+	}
+	//numberOfContours (2 bytes) , xMin , yMin , xMax , yMax
+	return BBox{XMin: short(2), YMin: short(4), XMax: short(6), YMax: short(8)}, true
 }
 
 func (me *TTFParser) NumGlyphs() uint64 {
@@ -166,6 +290,27 @@ func (me *TTFParser) Widths() []uint64 {
 	return me.widths
 }
 
+//PostScriptName : name id 6 of name table
+func (me *TTFParser) PostScriptName() string {
+	return me.postScriptName
+}
+
+//PreferredFamily : typographic family name (name id 16) , family name (name id 1) if font has no typographic family
+func (me *TTFParser) PreferredFamily() string {
+	if me.preferredFamily != "" {
+		return me.preferredFamily
+	}
+	return me.familyName
+}
+
+//PreferredSubfamily : typographic subfamily name (name id 17 sample "SemiBold") , subfamily name (name id 2) if font has no typographic subfamily
+func (me *TTFParser) PreferredSubfamily() string {
+	if me.preferredSubfamily != "" {
+		return me.preferredSubfamily
+	}
+	return me.subfamilyName
+}
+
 func (me *TTFParser) Chars() map[int]uint64 {
 	return me.chars
 }
@@ -176,17 +321,40 @@ func (me *TTFParser) GetTables() map[string]TableDirectoryEntry {
 
 func (me *TTFParser) Parse(fontpath string) error {
 	//fmt.Printf("\nstart parse\n")
-	fd, err := os.Open(fontpath)
+	fontData, err := ioutil.ReadFile(fontpath)
+	if err != nil {
+		return err
+	}
+	return me.parse(fontData)
+}
+
+//ParseByReader : same as Parse , read font data from r (sample embedded or downloaded font)
+func (me *TTFParser) ParseByReader(r io.Reader) error {
+	fontData, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return me.parse(fontData)
+}
+
+//ParseFS : same as Parse , read font name from fsys (sample embed.FS)
+func (me *TTFParser) ParseFS(fsys fs.FS, name string) error {
+	fontData, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return err
 	}
-	defer fd.Close()
+	return me.parse(fontData)
+}
+
+func (me *TTFParser) parse(fontData []byte) error {
+	fd := bytes.NewReader(fontData)
 	version, err := me.Read(fd, 4)
 	if err != nil {
 		return err
 	}
-	if !me.CompareBytes(version, []byte{0x00, 0x01, 0x00, 0x00}) {
-		return errors.New("Unrecognized file (font) format")
+	//truetype หรือ OTTO (CFF outlines)
+	if !me.CompareBytes(version, []byte{0x00, 0x01, 0x00, 0x00}) && !me.CompareBytes(version, []byte("OTTO")) {
+		return ErrUnrecognizedFontFormat
 	}
 
 	i := uint64(0)
@@ -230,49 +398,37 @@ func (me *TTFParser) Parse(fontpath string) error {
 
 	//fmt.Printf("%+v\n", me.tables)
 
-	err = me.ParseHead(fd)
-	if err != nil {
-		return err
-	}
-
-	err = me.ParseHhea(fd)
-	if err != nil {
-		return err
-	}
-
-	err = me.ParseMaxp(fd)
-	if err != nil {
-		return err
-	}
-	err = me.ParseHmtx(fd)
-	if err != nil {
-		return err
-	}
-	err = me.ParseCmap(fd)
-	if err != nil {
-		return err
-	}
-	err = me.ParseName(fd)
-	if err != nil {
-		return err
-	}
-	err = me.ParseOS2(fd)
-	if err != nil {
-		return err
-	}
-	err = me.ParsePost(fd)
-	if err != nil {
-		return err
-	}
-	err = me.ParseLoca(fd)
-	if err != nil {
-		return err
+	steps := []struct {
+		name  string
+		parse func(fd io.ReadSeeker) error
+	}{
+		{"ParseHead", me.ParseHead},
+		{"ParseHhea", me.ParseHhea},
+		{"ParseMaxp", me.ParseMaxp},
+		{"ParseHmtx", me.ParseHmtx},
+		{"ParseCmap", me.ParseCmap},
+		{"ParseName", me.ParseName},
+		{"ParseOS2", me.ParseOS2},
+		{"ParsePost", me.ParsePost},
+		{"ParseLoca", me.ParseLoca},
+	}
+	me.stepDurations = nil
+	for _, step := range steps {
+		if !me.profiling {
+			if err := step.parse(fd); err != nil {
+				return err
+			}
+			continue
+		}
+		start := time.Now()
+		err := step.parse(fd)
+		me.addStepDuration(step.name, time.Since(start))
+		if err != nil {
+			return err
+		}
 	}
 	//fmt.Printf("%#v\n", me.widths)
-	me.cahceFontData, err = me.readFontData(fontpath)
-	if err != nil {
-		return err
-	}
+	me.cahceFontData = fontData
 
 	return nil
 }
@@ -281,21 +437,19 @@ func (me *TTFParser) FontData() []byte {
 	return me.cahceFontData
 }
 
-func (me *TTFParser) readFontData(fontpath string) ([]byte, error) {
-	b, err := ioutil.ReadFile(fontpath)
-	if err != nil {
-		return nil, err
-	}
-	return b, nil
-}
-
-func (me *TTFParser) ParseLoca(fd *os.File) error {
+func (me *TTFParser) ParseLoca(fd io.ReadSeeker) error {
 
 	me.IsShortIndex = false
 	if me.indexToLocFormat == 0 {
 		me.IsShortIndex = true
 	}
 
+	//font CFF ไม่มี loca
+	if !me.HasTable("loca") {
+		me.LocaTable = nil
+		return nil
+	}
+
 	//fmt.Printf("indexToLocFormat = %d\n", me.indexToLocFormat)
 	err := me.Seek(fd, "loca")
 	if err != nil {
@@ -327,11 +481,15 @@ func (me *TTFParser) ParseLoca(fd *os.File) error {
 			i++
 		}
 	}
-	me.LocaTable = locaTable
+	//ต้องมี numGlyphs + 1 entries ที่เกินมาเป็น padding ของ table
+	if uint64(len(locaTable)) < me.numGlyphs+1 {
+		return fmt.Errorf("%w: %d entries for %d glyphs", ErrLocaGlyphCount, len(locaTable), me.numGlyphs)
+	}
+	me.LocaTable = locaTable[:me.numGlyphs+1]
 	return nil
 }
 
-func (me *TTFParser) ParsePost(fd *os.File) error {
+func (me *TTFParser) ParsePost(fd io.ReadSeeker) error {
 
 	err := me.Seek(fd, "post")
 	if err != nil {
@@ -375,7 +533,7 @@ func (me *TTFParser) ParsePost(fd *os.File) error {
 	return nil
 }
 
-func (me *TTFParser) ParseOS2(fd *os.File) error {
+func (me *TTFParser) ParseOS2(fd io.ReadSeeker) error {
 	err := me.Seek(fd, "OS/2")
 	if err != nil {
 		return err
@@ -396,7 +554,19 @@ func (me *TTFParser) ParseOS2(fd *os.File) error {
 	}
 	me.Embeddable = (fsType != 2) && ((fsType & 0x200) == 0)
 
-	err = me.Skip(fd, (11*2)+10+(4*4)+4)
+	err = me.Skip(fd, 8*2) // subscript and superscript size and offset
+	if err != nil {
+		return err
+	}
+	me.strikeoutSize, err = me.ReadShort(fd)
+	if err != nil {
+		return err
+	}
+	me.strikeoutPosition, err = me.ReadShort(fd)
+	if err != nil {
+		return err
+	}
+	err = me.Skip(fd, 2+10+(4*4)+4) // sFamilyClass, panose, ulUnicodeRange, achVendID
 	if err != nil {
 		return err
 	}
@@ -405,6 +575,7 @@ func (me *TTFParser) ParseOS2(fd *os.File) error {
 		return err
 	}
 	me.Bold = ((fsSelection & 32) != 0)
+	me.useTypoMetrics = ((fsSelection & 128) != 0)
 	err = me.Skip(fd, 2*2) // usFirstCharIndex, usLastCharIndex
 	if err != nil {
 		return err
@@ -452,14 +623,14 @@ func (me *TTFParser) ParseOS2(fd *os.File) error {
 		}
 
 	} else {
-		me.capHeight = me.ascender
+		me.capHeight = 0 //ไม่มี sCapHeight ใช้ค่าจาก glyph 'H' (ดู CapHeight)
 	}
 	//fmt.Printf("\n\nme.capHeight=%d , me.usWinAscent=%d,me.usWinDescent=%d\n\n", me.capHeight, me.usWinAscent, me.usWinDescent)
 
 	return nil
 }
 
-func (me *TTFParser) ParseName(fd *os.File) error {
+func (me *TTFParser) ParseName(fd io.ReadSeeker) error {
 
 	//$this->Seek('name');
 	err := me.Seek(fd, "name")
@@ -488,35 +659,51 @@ func (me *TTFParser) ParseName(fd *os.File) error {
 		return err
 	}
 
+	type nameRecord struct {
+		platformID, languageID, nameID, length, offset uint64
+	}
+	var records []nameRecord
 	for i := 0; i < int(count); i++ {
-		err = me.Skip(fd, 3*2) // platformID, encodingID, languageID
+		var record nameRecord
+		record.platformID, err = me.ReadUShort(fd)
 		if err != nil {
 			return err
 		}
-		nameID, err := me.ReadUShort(fd)
+		err = me.Skip(fd, 2) // encodingID
 		if err != nil {
 			return err
 		}
-		length, err := me.ReadUShort(fd)
+		fields := []*uint64{&record.languageID, &record.nameID, &record.length, &record.offset}
+		for _, field := range fields {
+			*field, err = me.ReadUShort(fd)
+			if err != nil {
+				return err
+			}
+		}
+		records = append(records, record)
+	}
+
+	names := make(map[uint64]string)
+	for _, record := range records {
+		if record.nameID != 1 && record.nameID != 2 && record.nameID != 6 && record.nameID != 16 && record.nameID != 17 {
+			continue
+		}
+		//ใช้ชื่อ windows ภาษาอังกฤษ (US) ก่อน ถ้าไม่มีใช้ชื่อแรกที่เจอ
+		_, found := names[record.nameID]
+		isWindowsEnglish := record.platformID == 3 && record.languageID == 0x409
+		if found && (!isWindowsEnglish || record.nameID == 6) {
+			continue
+		}
+		_, err = fd.Seek(int64(tableOffset+stringOffset+record.offset), 0)
 		if err != nil {
 			return err
 		}
-		offset, err := me.ReadUShort(fd)
+		stmp, err := me.Read(fd, int(record.length))
 		if err != nil {
 			return err
 		}
-		if nameID == 6 {
+		if record.nameID == 6 {
 			// PostScript name
-			_, err = fd.Seek(int64(tableOffset+stringOffset+offset), 0)
-			if err != nil {
-				return err
-			}
-
-			stmp, err := me.Read(fd, int(length))
-			if err != nil {
-				return err
-			}
-
 			var tmpStmp []byte
 			for _, v := range stmp {
 				if v != 0 {
@@ -530,9 +717,13 @@ func (me *TTFParser) ParseName(fd *os.File) error {
 				return err
 			}
 			me.postScriptName = s
-			break
 		}
+		names[record.nameID] = decodeName(record.platformID, stmp)
 	}
+	me.familyName = names[1]
+	me.subfamilyName = names[2]
+	me.preferredFamily = names[16]
+	me.preferredSubfamily = names[17]
 
 	if me.postScriptName == "" {
 		return ERROR_POSTSCRIPT_NAME_NOT_FOUND
@@ -542,6 +733,22 @@ func (me *TTFParser) ParseName(fd *os.File) error {
 	return nil
 }
 
+//decodeName : text of name record , unicode (0) and windows (3) platform names are utf-16be , others are single byte
+func decodeName(platformID uint64, b []byte) string {
+	if platformID != 0 && platformID != 3 {
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
 func (me *TTFParser) PregReplace(pattern string, replacement string, subject string) (string, error) {
 
 	reg, err := regexp.Compile(pattern)
@@ -552,8 +759,11 @@ func (me *TTFParser) PregReplace(pattern string, replacement string, subject str
 	return str, nil
 }
 
-func (me *TTFParser) ParseCmap(fd *os.File) error {
-	me.Seek(fd, "cmap")
+func (me *TTFParser) ParseCmap(fd io.ReadSeeker) error {
+	err := me.Seek(fd, "cmap")
+	if err != nil {
+		return err
+	}
 	me.Skip(fd, 2) // version
 	numTables, err := me.ReadUShort(fd)
 	if err != nil {
@@ -718,8 +928,8 @@ func (me *TTFParser) ParseCmap(fd *os.File) error {
 			if gid >= 65536 {
 				gid -= 65536
 			}
-			if gid > 0 {
-				//fmt.Printf("%d gid = %d, ", int(c), gid)
+			//segments ที่ซ้อนกัน ใช้ glyph ของ segment แรกที่ map ได้ (glyph 0 ไม่นับ)
+			if _, ok := me.chars[int(c)]; gid > 0 && !ok {
 				me.chars[int(c)] = gid
 			}
 		}
@@ -730,14 +940,17 @@ func (me *TTFParser) ParseCmap(fd *os.File) error {
 	return nil
 }
 
-func (me *TTFParser) FTell(fd *os.File) (uint64, error) {
-	offset, err := fd.Seek(0, os.SEEK_CUR)
+func (me *TTFParser) FTell(fd io.ReadSeeker) (uint64, error) {
+	offset, err := fd.Seek(0, io.SeekCurrent)
 	return uint64(offset), err
 }
 
-func (me *TTFParser) ParseHmtx(fd *os.File) error {
+func (me *TTFParser) ParseHmtx(fd io.ReadSeeker) error {
 
-	me.Seek(fd, "hmtx")
+	err := me.Seek(fd, "hmtx")
+	if err != nil {
+		return err
+	}
 	i := uint64(0)
 	for i < me.numberOfHMetrics {
 		advanceWidth, err := me.ReadUShort(fd)
@@ -778,7 +991,7 @@ func (me *TTFParser) ArrayPadUint(arr []uint64, size uint64, val uint64) ([]uint
 	return result, nil
 }
 
-func (me *TTFParser) ParseHead(fd *os.File) error {
+func (me *TTFParser) ParseHead(fd io.ReadSeeker) error {
 
 	//fmt.Printf("\nParseHead\n")
 	err := me.Seek(fd, "head")
@@ -796,7 +1009,9 @@ func (me *TTFParser) ParseHead(fd *os.File) error {
 	}
 
 	//fmt.Printf("\nmagicNumber = %d\n", magicNumber)
-	if magicNumber != 0x5F0F3CF5 {
+	if magicNumber == 0xF53C0F5F || magicNumber == 0x0F5FF53C { //little-endian or 16 bit swapped
+		return ERROR_BYTE_SWAPPED_OR_CORRUPT
+	} else if magicNumber != 0x5F0F3CF5 {
 		return ERROR_INCORRECT_MAGIC_NUMBER
 	}
 
@@ -845,10 +1060,24 @@ func (me *TTFParser) ParseHead(fd *os.File) error {
 		return err
 	}
 
+	return me.checkHead()
+}
+
+//checkHead : sanity check of values read from head table , sfnt is big-endian so a byte-swapped file gives implausible values
+func (me *TTFParser) checkHead() error {
+	if me.unitsPerEm < 16 || me.unitsPerEm > 16384 {
+		return ERROR_BYTE_SWAPPED_OR_CORRUPT
+	}
+	if me.xMin > me.xMax || me.yMin > me.yMax {
+		return ERROR_BYTE_SWAPPED_OR_CORRUPT
+	}
+	if me.indexToLocFormat != 0 && me.indexToLocFormat != 1 {
+		return ERROR_BYTE_SWAPPED_OR_CORRUPT
+	}
 	return nil
 }
 
-func (me *TTFParser) ParseHhea(fd *os.File) error {
+func (me *TTFParser) ParseHhea(fd io.ReadSeeker) error {
 
 	err := me.Seek(fd, "hhea")
 	if err != nil {
@@ -870,7 +1099,12 @@ func (me *TTFParser) ParseHhea(fd *os.File) error {
 		return err
 	}
 
-	err = me.Skip(fd, 13*2)
+	me.lineGap, err = me.ReadShort(fd)
+	if err != nil {
+		return err
+	}
+
+	err = me.Skip(fd, 12*2)
 	if err != nil {
 		return err
 	}
@@ -884,7 +1118,7 @@ func (me *TTFParser) ParseHhea(fd *os.File) error {
 	return nil
 }
 
-func (me *TTFParser) ParseMaxp(fd *os.File) error {
+func (me *TTFParser) ParseMaxp(fd io.ReadSeeker) error {
 	err := me.Seek(fd, "maxp")
 	if err != nil {
 		return err
@@ -900,10 +1134,10 @@ func (me *TTFParser) ParseMaxp(fd *os.File) error {
 	return nil
 }
 
-func (me *TTFParser) Seek(fd *os.File, tag string) error {
+func (me *TTFParser) Seek(fd io.ReadSeeker, tag string) error {
 	table, ok := me.tables[tag]
 	if !ok {
-		return errors.New("me.tables not contain key=" + tag)
+		return fmt.Errorf("%w: %s", ErrMissingTable, tag)
 	}
 	val := table.Offset
 	_, err := fd.Seek(int64(val), 0)
@@ -917,7 +1151,7 @@ func (me *TTFParser) BytesToString(b []byte) string {
 	return string(b) //strings.TrimSpace(string(b))
 }
 
-func (me *TTFParser) ReadUShort(fd *os.File) (uint64, error) {
+func (me *TTFParser) ReadUShort(fd io.ReadSeeker) (uint64, error) {
 	buff, err := me.Read(fd, 2)
 	if err != nil {
 		return 0, err
@@ -927,7 +1161,7 @@ func (me *TTFParser) ReadUShort(fd *os.File) (uint64, error) {
 	return num.Uint64(), nil
 }
 
-func (me *TTFParser) ReadShort(fd *os.File) (int64, error) {
+func (me *TTFParser) ReadShort(fd io.ReadSeeker) (int64, error) {
 	buff, err := me.Read(fd, 2)
 	if err != nil {
 		return 0, err
@@ -946,7 +1180,7 @@ func (me *TTFParser) ReadShort(fd *os.File) (int64, error) {
 	return v, nil
 }
 
-func (me *TTFParser) ReadULong(fd *os.File) (uint64, error) {
+func (me *TTFParser) ReadULong(fd io.ReadSeeker) (uint64, error) {
 	buff, err := me.Read(fd, 4)
 	//fmt.Printf("%#v\n", buff)
 	if err != nil {
@@ -957,7 +1191,7 @@ func (me *TTFParser) ReadULong(fd *os.File) (uint64, error) {
 	return num.Uint64(), nil
 }
 
-func (me *TTFParser) Skip(fd *os.File, length int64) error {
+func (me *TTFParser) Skip(fd io.ReadSeeker, length int64) error {
 	_, err := fd.Seek(int64(length), 1)
 	if err != nil {
 		return err
@@ -965,14 +1199,14 @@ func (me *TTFParser) Skip(fd *os.File, length int64) error {
 	return nil
 }
 
-func (me *TTFParser) Read(fd *os.File, length int) ([]byte, error) {
+func (me *TTFParser) Read(fd io.ReadSeeker, length int) ([]byte, error) {
 	buff := make([]byte, length)
 	readlength, err := fd.Read(buff)
 	if err != nil {
 		return nil, err
 	}
 	if readlength != length {
-		return nil, errors.New("file out of length")
+		return nil, ErrFileOutOfLength
 	}
 	//fmt.Printf("%d,%s\n", readlength, string(buff))
 	return buff, nil
@@ -1002,3 +1236,52 @@ func (me *TTFParser) CompareBytes(a []byte, b []byte) bool {
 	}
 	return true
 }
+
+//HasTable : true if font contains table of tag (sample "GSUB" , "GPOS" , "kern")
+func (me *TTFParser) HasTable(tag string) bool {
+	_, ok := me.tables[tag]
+	return ok
+}
+
+//AvailableFeatures : sorted feature tags in FeatureList of GSUB and GPOS (sample "kern" , "liga" , "smcp")
+func (me *TTFParser) AvailableFeatures() []string {
+	found := make(map[string]bool)
+	var features []string
+	for _, tag := range []string{"GSUB", "GPOS"} {
+		for _, feature := range me.featureTags(tag) {
+			if !found[feature] {
+				found[feature] = true
+				features = append(features, feature)
+			}
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+//featureTags : tags in FeatureList of GSUB or GPOS table
+func (me *TTFParser) featureTags(tableTag string) []string {
+	table, ok := me.tables[tableTag]
+	data := me.cahceFontData
+	start := int(table.Offset)
+	if !ok || start+10 > len(data) {
+		return nil
+	}
+	readUShort := func(i int) int {
+		return int(data[i])<<8 | int(data[i+1])
+	}
+	featureList := start + readUShort(start+6)
+	if featureList+2 > len(data) {
+		return nil
+	}
+	count := readUShort(featureList)
+	var tags []string
+	for i := 0; i < count; i++ {
+		record := featureList + 2 + i*6
+		if record+6 > len(data) {
+			break
+		}
+		tags = append(tags, string(data[record:record+4]))
+	}
+	return tags
+}
@@ -0,0 +1,468 @@
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+//parseTestFont : parse font in res/fonts (zlib compressed ttf)
+func parseTestFont(t *testing.T, name string) *TTFParser {
+	var parser TTFParser
+	err := parser.Parse(writeTestFont(t, name, testFontBytes(t, name)))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return &parser
+}
+
+//testFontBytes : uncompressed ttf of res/fonts/name.z
+func testFontBytes(t *testing.T, name string) []byte {
+	z, err := ioutil.ReadFile("../../res/fonts/" + name + ".z")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	r, err := zlib.NewReader(bytes.NewReader(z))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return b
+}
+
+//writeTestFont : write b to temp file and return its path
+func writeTestFont(t *testing.T, name string, b []byte) string {
+	path := filepath.Join(t.TempDir(), name+".ttf")
+	err := ioutil.WriteFile(path, b, 0644)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return path
+}
+
+func TestAvailableFeatures(t *testing.T) {
+	parser := parseTestFont(t, "THSarabun")
+	if !parser.HasTable("GSUB") || !parser.HasTable("GPOS") || !parser.HasTable("kern") || parser.HasTable("COLR") {
+		t.Errorf("unexpected tables")
+	}
+	features := parser.AvailableFeatures()
+	if !reflect.DeepEqual(features, []string{"ccmp", "frac", "kern", "liga", "rlig"}) {
+		t.Errorf("unexpected features %q", features)
+	}
+
+	//GSUB ที่มี smcp (a -> A) แทน GSUB เดิมของ font
+	a, A := parser.Chars()['a'], parser.Chars()['A']
+	var table bytes.Buffer
+	put := func(values ...int) {
+		for _, v := range values {
+			table.WriteByte(byte(v >> 8))
+			table.WriteByte(byte(v))
+		}
+	}
+	put(1, 0, 10, 30, 44)     //version , ScriptList , FeatureList , LookupList
+	put(1)                    //ScriptList : 1 script
+	table.WriteString("DFLT") //script tag
+	put(8, 4, 0)              //script : default LangSys , no other LangSys
+	put(0, 0xFFFF, 1, 0)      //LangSys : feature 0
+	put(1)                    //FeatureList : 1 feature
+	table.WriteString("smcp") //feature tag
+	put(8, 0, 1, 0)           //feature : lookup 0
+	put(1, 4)                 //LookupList : 1 lookup
+	put(1, 0, 1, 8)           //lookup : single substitution , 1 subtable
+	put(2, 8, 1, int(A))      //subtable format 2 : coverage , 1 substitute
+	put(1, 1, int(a))         //coverage format 1 : 1 glyph
+	patched := append([]byte(nil), testFontBytes(t, "THSarabun")...)
+	for i := 0; i < int(patched[4])<<8|int(patched[5]); i++ {
+		record := 12 + i*16
+		if string(patched[record:record+4]) != "GSUB" {
+			continue
+		}
+		offset, length := len(patched), table.Len()
+		for j := 0; j < 4; j++ {
+			patched[record+8+j] = byte(offset >> uint(24-8*j))
+			patched[record+12+j] = byte(length >> uint(24-8*j))
+		}
+	}
+	patched = append(patched, table.Bytes()...)
+
+	var smallCaps TTFParser
+	if err := smallCaps.Parse(writeTestFont(t, "smcp", patched)); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	features = smallCaps.AvailableFeatures()
+	if !reflect.DeepEqual(features, []string{"kern", "smcp"}) {
+		t.Errorf("expect GPOS kern and GSUB smcp but got %q", features)
+	}
+	lookups := smallCaps.GSUBLookups([]string{"smcp"})
+	if len(lookups) != 1 || lookups[0].SingleSubstitutions[a] != A {
+		t.Errorf("expect smcp to substitute glyph %d with %d but got %v", a, A, lookups)
+	}
+	if len(smallCaps.GSUBLookups([]string{"liga"})) != 0 {
+		t.Errorf("font without liga must have no liga lookups")
+	}
+}
+
+func TestIsFixedPitch(t *testing.T) {
+	parser := parseTestFont(t, "Loma")
+	if parser.IsFixedPitch() || parser.Flag()&FixedPitch != 0 {
+		t.Errorf("Loma is not monospaced")
+	}
+	//font that not set isFixedPitch in post but every glyph has the same advance
+	for i := range parser.widths {
+		if parser.widths[i] != 0 {
+			parser.widths[i] = 1229
+		}
+	}
+	if !parser.IsFixedPitch() || parser.Flag()&FixedPitch == 0 {
+		t.Errorf("font with same advance widths must be monospaced")
+	}
+	parser.widths[len(parser.widths)-1] = 500
+	parser.isFixedPitch = true
+	if !parser.IsFixedPitch() {
+		t.Errorf("isFixedPitch of post table must be monospaced")
+	}
+}
+
+func TestByteSwappedHead(t *testing.T) {
+	b := testFontBytes(t, "Loma")
+	var parser TTFParser
+	err := parser.Parse(writeTestFont(t, "Loma", b))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	head := parser.GetTables()["head"]
+
+	//whole head table in little-endian 16 bit words
+	swapped := append([]byte(nil), b...)
+	for i := head.Offset; i+1 < head.Offset+head.Length; i += 2 {
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+	}
+	err = new(TTFParser).Parse(writeTestFont(t, "swapped", swapped))
+	if err != ERROR_BYTE_SWAPPED_OR_CORRUPT {
+		t.Errorf("expect ERROR_BYTE_SWAPPED_OR_CORRUPT but got %v", err)
+	}
+
+	//only unitsPerEm swapped (2048 become 8)
+	swapped = append([]byte(nil), b...)
+	swapped[head.Offset+18], swapped[head.Offset+19] = swapped[head.Offset+19], swapped[head.Offset+18]
+	err = new(TTFParser).Parse(writeTestFont(t, "upem", swapped))
+	if err != ERROR_BYTE_SWAPPED_OR_CORRUPT {
+		t.Errorf("expect ERROR_BYTE_SWAPPED_OR_CORRUPT but got %v", err)
+	}
+}
+
+func TestParseCmapOverlappingSegments(t *testing.T) {
+	b := testFontBytes(t, "Loma")
+	segments := []struct {
+		start, end, delta, rangeOffset int
+	}{
+		{0x41, 0x43, 10 - 0x41, 0}, //A B C -> 10 11 12
+		{0x42, 0x44, 20 - 0x42, 0}, //B C D -> 20 21 22 (B C overlap segment แรก)
+		{0x45, 0x45, 0, 6},         //E -> glyphIdArray[0] = 0
+		{0x45, 0x46, 30 - 0x45, 0}, //E F -> 30 31
+		{0xFFFF, 0xFFFF, 1, 0},
+	}
+	var cmap bytes.Buffer
+	put := func(v int) {
+		cmap.WriteByte(byte(v >> 8))
+		cmap.WriteByte(byte(v))
+	}
+	segCount := len(segments)
+	put(0)  //version
+	put(1)  //numTables
+	put(3)  //platformID
+	put(1)  //encodingID
+	put(0)  //offset (high)
+	put(12) //offset (low)
+	put(4)  //format
+	put(16 + 8*segCount + 2)
+	put(0) //language
+	put(segCount * 2)
+	put(8) //searchRange
+	put(2) //entrySelector
+	put(2) //rangeShift
+	for _, seg := range segments {
+		put(seg.end)
+	}
+	put(0) //reservedPad
+	for _, seg := range segments {
+		put(seg.start)
+	}
+	for _, seg := range segments {
+		put(seg.delta)
+	}
+	for _, seg := range segments {
+		put(seg.rangeOffset)
+	}
+	put(0) //glyphIdArray
+
+	//ย้าย cmap ไปต่อท้ายไฟล์
+	numTables := int(b[4])<<8 | int(b[5])
+	patched := append([]byte(nil), b...)
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if string(patched[record:record+4]) != "cmap" {
+			continue
+		}
+		offset, length := len(patched), cmap.Len()
+		for j := 0; j < 4; j++ {
+			patched[record+8+j] = byte(offset >> uint(24-8*j))
+			patched[record+12+j] = byte(length >> uint(24-8*j))
+		}
+	}
+	patched = append(patched, cmap.Bytes()...)
+
+	var parser TTFParser
+	err := parser.Parse(writeTestFont(t, "overlap", patched))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	expected := map[int]uint64{'A': 10, 'B': 11, 'C': 12, 'D': 22, 'E': 30, 'F': 31}
+	if !reflect.DeepEqual(parser.Chars(), expected) {
+		t.Errorf("expect %v but got %v", expected, parser.Chars())
+	}
+}
+
+func TestCapHeightOS2Version1(t *testing.T) {
+	b := testFontBytes(t, "Loma")
+	parser := parseTestFont(t, "Loma")
+	os2 := parser.GetTables()["OS/2"]
+
+	//OS/2 version 1 has no sCapHeight
+	patched := append([]byte(nil), b...)
+	patched[os2.Offset], patched[os2.Offset+1] = 0, 1
+	var v1 TTFParser
+	err := v1.Parse(writeTestFont(t, "v1", patched))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	bounds, ok := v1.GlyphBounds(v1.Chars()['H'])
+	if !ok {
+		t.Fatalf("glyph 'H' must have bounds")
+	}
+	if v1.CapHeight() == 0 || v1.CapHeight() != bounds.YMax {
+		t.Errorf("expect cap height %d (top of 'H') but got %d", bounds.YMax, v1.CapHeight())
+	}
+}
+
+func TestParseWithoutLoca(t *testing.T) {
+	b := testFontBytes(t, "Loma")
+	//OTTO font (CFF outlines) has no glyf and loca
+	patched := append([]byte(nil), b...)
+	copy(patched, "OTTO")
+	numTables := int(b[4])<<8 | int(b[5])
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		switch string(patched[record : record+4]) {
+		case "glyf":
+			copy(patched[record:], "CFF ")
+		case "loca":
+			copy(patched[record:], "zzzz")
+		}
+	}
+	var parser TTFParser
+	err := parser.Parse(writeTestFont(t, "cff", patched))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if parser.LocaTable != nil || parser.HasTable("glyf") {
+		t.Errorf("font without loca must have no loca table")
+	}
+	if _, ok := parser.GlyphBounds(parser.Chars()['H']); ok {
+		t.Errorf("glyph bounds must not be found without glyf")
+	}
+}
+
+func TestMissingTableError(t *testing.T) {
+	b := testFontBytes(t, "Loma")
+	patched := append([]byte(nil), b...)
+	numTables := int(b[4])<<8 | int(b[5])
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if string(patched[record:record+4]) == "hhea" {
+			copy(patched[record:], "zzzz")
+		}
+	}
+	err := new(TTFParser).Parse(writeTestFont(t, "nohhea", patched))
+	if !errors.Is(err, ErrMissingTable) || !strings.Contains(err.Error(), "hhea") {
+		t.Errorf("expect ErrMissingTable of hhea but got %v", err)
+	}
+	if errors.Is(err, ErrNoUnicodeEncoding) {
+		t.Errorf("missing table must not be unicode encoding error")
+	}
+}
+
+func TestLocaGlyphCount(t *testing.T) {
+	b := testFontBytes(t, "Loma")
+	parser := parseTestFont(t, "Loma")
+	if uint64(len(parser.LocaTable)) != parser.NumGlyphs()+1 {
+		t.Errorf("expect %d loca entries but got %d", parser.NumGlyphs()+1, len(parser.LocaTable))
+	}
+
+	//loca สั้นไป 2 entries (short index)
+	patched := append([]byte(nil), b...)
+	numTables := int(b[4])<<8 | int(b[5])
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if string(patched[record:record+4]) == "loca" {
+			length := int(parser.GetTables()["loca"].Length) - 4
+			patched[record+14], patched[record+15] = byte(length>>8), byte(length)
+		}
+	}
+	err := new(TTFParser).Parse(writeTestFont(t, "shortloca", patched))
+	if !errors.Is(err, ErrLocaGlyphCount) {
+		t.Errorf("expect ErrLocaGlyphCount but got %v", err)
+	}
+}
+
+func TestPreferredFamily(t *testing.T) {
+	parser := parseTestFont(t, "Loma")
+	if parser.PreferredFamily() != "Loma" || parser.PreferredSubfamily() != "Book" {
+		t.Errorf("font without typographic names must use name id 1/2 but got %q %q", parser.PreferredFamily(), parser.PreferredSubfamily())
+	}
+
+	names := []struct {
+		nameID int
+		text   string
+	}{
+		{1, "Test Sans SemiBold"},
+		{2, "Regular"},
+		{6, "TestSans-SemiBold"},
+		{16, "Test Sans"},
+		{17, "SemiBold"},
+	}
+	var records, texts bytes.Buffer
+	put := func(buff *bytes.Buffer, v int) {
+		buff.WriteByte(byte(v >> 8))
+		buff.WriteByte(byte(v))
+	}
+	for _, name := range names {
+		put(&records, 3)     //platformID
+		put(&records, 1)     //encodingID
+		put(&records, 0x409) //languageID
+		put(&records, name.nameID)
+		put(&records, len(name.text)*2)
+		put(&records, texts.Len())
+		for _, r := range name.text {
+			put(&texts, int(r))
+		}
+	}
+	var table bytes.Buffer
+	put(&table, 0) //format
+	put(&table, len(names))
+	put(&table, 6+records.Len())
+	table.Write(records.Bytes())
+	table.Write(texts.Bytes())
+
+	//ย้าย name ไปต่อท้ายไฟล์
+	patched := append([]byte(nil), testFontBytes(t, "Loma")...)
+	for i := 0; i < int(patched[4])<<8|int(patched[5]); i++ {
+		record := 12 + i*16
+		if string(patched[record:record+4]) != "name" {
+			continue
+		}
+		offset, length := len(patched), table.Len()
+		for j := 0; j < 4; j++ {
+			patched[record+8+j] = byte(offset >> uint(24-8*j))
+			patched[record+12+j] = byte(length >> uint(24-8*j))
+		}
+	}
+	patched = append(patched, table.Bytes()...)
+
+	var typographic TTFParser
+	err := typographic.Parse(writeTestFont(t, "typographic", patched))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if typographic.PreferredFamily() != "Test Sans" || typographic.PreferredSubfamily() != "SemiBold" {
+		t.Errorf("expect Test Sans SemiBold but got %q %q", typographic.PreferredFamily(), typographic.PreferredSubfamily())
+	}
+	if typographic.PostScriptName() != "TestSans-SemiBold" {
+		t.Errorf("expect PostScript name TestSans-SemiBold but got %q", typographic.PostScriptName())
+	}
+}
+
+func TestStrikeout(t *testing.T) {
+	parser := parseTestFont(t, "Loma")
+	//yStrikeoutSize , yStrikeoutPosition ใน OS/2 ของ Loma
+	if parser.StrikeoutSize() != 102 || parser.StrikeoutPosition() != 530 {
+		t.Errorf("expect strikeout 102 at 530 but got %d at %d", parser.StrikeoutSize(), parser.StrikeoutPosition())
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{"fonts/Loma.ttf": &fstest.MapFile{Data: testFontBytes(t, "Loma")}}
+	var parser TTFParser
+	if err := parser.ParseFS(fsys, "fonts/Loma.ttf"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if parser.PostScriptName() != "Loma" || parser.NumGlyphs() != parseTestFont(t, "Loma").NumGlyphs() {
+		t.Errorf("font from fs must be same as font from file")
+	}
+	if err := parser.ParseFS(fsys, "fonts/missing.ttf"); err == nil {
+		t.Errorf("expect error of missing font")
+	}
+}
+
+func TestStats(t *testing.T) {
+	parser := parseTestFont(t, "Loma")
+	stats := parser.Stats()
+	if stats.LargestTable() != "glyf" || stats.TableSizes["glyf"] != parser.GetTables()["glyf"].Length {
+		t.Errorf("expect glyf as largest table but got %s", stats.LargestTable())
+	}
+	if stats.StepDurations != nil {
+		t.Errorf("steps must not be timed without profiling")
+	}
+
+	parser.SetProfiling(true)
+	if err := parser.ParseByReader(bytes.NewReader(testFontBytes(t, "Loma"))); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if _, ok := parser.Stats().StepDurations["ParseCmap"]; !ok || len(parser.Stats().StepDurations) != 9 {
+		t.Errorf("expect time of 9 steps but got %v", parser.Stats().StepDurations)
+	}
+}
+
+func TestUseTypoMetrics(t *testing.T) {
+	parser := parseTestFont(t, "Loma")
+	if parser.UseTypoMetrics() {
+		t.Errorf("Loma does not set USE_TYPO_METRICS")
+	}
+
+	//ตั้ง bit 7 ของ fsSelection (offset 62 ของ OS/2)
+	patched := append([]byte(nil), testFontBytes(t, "Loma")...)
+	os2 := int(parser.GetTables()["OS/2"].Offset)
+	patched[os2+63] |= 0x80
+	var typo TTFParser
+	if err := typo.Parse(writeTestFont(t, "typo", patched)); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if !typo.UseTypoMetrics() || typo.Bold != parser.Bold {
+		t.Errorf("expect USE_TYPO_METRICS set")
+	}
+}
+
+func TestGaspRanges(t *testing.T) {
+	ranges := parseTestFont(t, "THSarabunNew").GaspRanges()
+	expect := []GaspRange{
+		{MaxPPEM: 8, Behavior: GaspDoGray | GaspSymmetricSmoothing},
+		{MaxPPEM: 45, Behavior: GaspGridfit | GaspSymmetricSmoothing},
+		{MaxPPEM: 0xFFFF, Behavior: GaspDoGray | GaspSymmetricSmoothing},
+	}
+	if !reflect.DeepEqual(ranges, expect) {
+		t.Errorf("expect %v but got %v", expect, ranges)
+	}
+	if ranges := parseTestFont(t, "Loma").GaspRanges(); ranges != nil {
+		t.Errorf("Loma has no gasp table but got %v", ranges)
+	}
+}
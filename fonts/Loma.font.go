@@ -284,7 +284,7 @@ func (me *Loma) Init() {
 	me.desc[1] = gopdf.FontDescItem{Key: "Descent", Val: "-200"}
 	me.desc[2] = gopdf.FontDescItem{Key: "CapHeight", Val: "0"}
 	me.desc[3] = gopdf.FontDescItem{Key: "Flags", Val: "33"}
-	me.desc[4] = gopdf.FontDescItem{Key: "FontBBox", Val: "[31257 31560 1338 1146]"}
+	me.desc[4] = gopdf.FontDescItem{Key: "FontBBox", Val: "[-743 -440 1338 1146]"}
 	me.desc[5] = gopdf.FontDescItem{Key: "ItalicAngle", Val: "0"}
 	me.desc[6] = gopdf.FontDescItem{Key: "StemV", Val: "70"}
 	me.desc[7] = gopdf.FontDescItem{Key: "MissingWidth", Val: "750"}
@@ -0,0 +1,447 @@
+package gopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+)
+
+//FormFieldOption : option of AddTextField , AddChoiceField and AddCheckBox
+type FormFieldOption struct {
+	//Keystroke , Validate , Calculate : javascript of /AA actions that run when user types in field (/K) ,
+	//when value is committed (/V) and when other field changes (/C , field is added to calculation order) ,
+	//code is passed through as is , "" = no action
+	Keystroke string
+	Validate  string
+	Calculate string
+	//Multiline : value of text field is wrapped into lines inside field (line height from font)
+	Multiline bool
+}
+
+//additionalActions : /AA dictionary of field , "" if field has no action
+func (o FormFieldOption) additionalActions() string {
+	var buff bytes.Buffer
+	for _, action := range []struct {
+		key  string
+		code string
+	}{{"K", o.Keystroke}, {"V", o.Validate}, {"C", o.Calculate}} {
+		if action.code != "" {
+			buff.WriteString(" /" + action.key + " << /S /JavaScript /JS (" + escapePdfString(action.code) + ") >>")
+		}
+	}
+	if buff.Len() == 0 {
+		return ""
+	}
+	return "<<" + buff.String() + " >>"
+}
+
+//ErrUnknownChoice : value of AddChoiceField is not one of its options
+var ErrUnknownChoice = errors.New("value is not one of choices")
+
+//field flags (/Ff)
+const (
+	formFieldMultiline = 1 << 12
+	formFieldCombo     = 1 << 17
+)
+
+//FormFieldObj : form field (text field , check box or choice field) and its widget annotation (one dictionary)
+type FormFieldObj struct { //impl IObj
+	buffer bytes.Buffer
+	//fieldType : Tx (text) , Btn (check box) or Ch (choice)
+	fieldType string
+	name      string
+	value     string
+	//flags : field flags (/Ff)
+	flags int
+	//choices : options of choice field
+	choices []string
+	//rect : llx lly urx ury (pdf space)
+	rect []float64
+	//da : default appearance (font and color of value) , "" = field has no text
+	da          string
+	indexOfPage int
+	//indexOfAppearance : appearance of value (of checked state of check box)
+	indexOfAppearance int
+	//indexOfOffAppearance : appearance of unchecked state of check box (-1 = none)
+	indexOfOffAppearance int
+	option               FormFieldOption
+}
+
+func (f *FormFieldObj) Init(funcGetRoot func() *GoPdf) {
+}
+
+func (f *FormFieldObj) Build() error {
+	f.buffer.WriteString("<<\n")
+	f.buffer.WriteString("/Type /Annot\n")
+	f.buffer.WriteString("/Subtype /Widget\n")
+	f.buffer.WriteString("/FT /" + f.fieldType + "\n")
+	f.buffer.WriteString("/T " + pdfTextString(f.name) + "\n")
+	if f.fieldType == "Btn" {
+		f.buffer.WriteString("/V /" + f.value + "\n")
+		f.buffer.WriteString("/AS /" + f.value + "\n")
+	} else {
+		f.buffer.WriteString("/V " + pdfTextString(f.value) + "\n")
+	}
+	if f.flags != 0 {
+		f.buffer.WriteString(fmt.Sprintf("/Ff %d\n", f.flags))
+	}
+	if len(f.choices) > 0 {
+		f.buffer.WriteString("/Opt [")
+		for _, choice := range f.choices {
+			f.buffer.WriteString(" " + pdfTextString(choice))
+		}
+		f.buffer.WriteString(" ]\n")
+	}
+	f.buffer.WriteString(fmt.Sprintf("/Rect [%0.2f %0.2f %0.2f %0.2f]\n", f.rect[0], f.rect[1], f.rect[2], f.rect[3]))
+	f.buffer.WriteString(fmt.Sprintf("/P %d 0 R\n", f.indexOfPage+1))
+	f.buffer.WriteString("/F 4\n") //print
+	if f.da != "" {
+		f.buffer.WriteString("/DA (" + escapePdfString(f.da) + ")\n")
+	}
+	if f.indexOfOffAppearance != -1 {
+		f.buffer.WriteString(fmt.Sprintf("/AP << /N << /Yes %d 0 R /Off %d 0 R >> >>\n", f.indexOfAppearance+1, f.indexOfOffAppearance+1))
+	} else {
+		f.buffer.WriteString(fmt.Sprintf("/AP << /N %d 0 R >>\n", f.indexOfAppearance+1))
+	}
+	if aa := f.option.additionalActions(); aa != "" {
+		f.buffer.WriteString("/AA " + aa + "\n")
+	}
+	f.buffer.WriteString(">>\n")
+	return nil
+}
+
+func (f *FormFieldObj) GetType() string {
+	return "FormField"
+}
+
+func (f *FormFieldObj) GetObjBuff() *bytes.Buffer {
+	return &(f.buffer)
+}
+
+//appearances : index of appearance objs of field
+func (f *FormFieldObj) appearances() []int {
+	if f.indexOfOffAppearance != -1 {
+		return []int{f.indexOfAppearance, f.indexOfOffAppearance}
+	}
+	return []int{f.indexOfAppearance}
+}
+
+//FormXObj : form xobject that is the appearance stream of FormFieldObj
+type FormXObj struct { //impl IObj
+	buffer bytes.Buffer
+	//ops : ops of value (pdf space)
+	ops  []byte
+	bbox []float64
+	//font ที่ ops ใช้ (ชื่อ resource และ index ของ font obj) , "" = ไม่มีข้อความ (check box)
+	fontName    string
+	indexOfFont int
+	getRoot     func() *GoPdf
+}
+
+func (x *FormXObj) Init(funcGetRoot func() *GoPdf) {
+	x.getRoot = funcGetRoot
+}
+
+func (x *FormXObj) Build() error {
+	var stream bytes.Buffer
+	if x.fontName != "" {
+		//ข้อความของ field ต้องอยู่ใน /Tx BMC เพื่อให้ viewer แทนที่ได้ตอนแก้ไข
+		stream.WriteString("/Tx BMC\nq\n")
+		stream.Write(x.getRoot().simpleFontStream(x.ops))
+		stream.WriteString("Q\nEMC\n")
+	} else {
+		stream.WriteString("q\n")
+		stream.Write(x.ops)
+		stream.WriteString("Q\n")
+	}
+	x.buffer.WriteString("<<\n")
+	x.buffer.WriteString("/Type /XObject\n")
+	x.buffer.WriteString("/Subtype /Form\n")
+	x.buffer.WriteString(fmt.Sprintf("/BBox [%0.2f %0.2f %0.2f %0.2f]\n", x.bbox[0], x.bbox[1], x.bbox[2], x.bbox[3]))
+	if x.fontName != "" {
+		x.buffer.WriteString(fmt.Sprintf("/Resources << /Font << /%s %d 0 R >> >>\n", x.fontName, x.indexOfFont+1))
+	} else {
+		x.buffer.WriteString("/Resources << >>\n")
+	}
+	x.buffer.WriteString(fmt.Sprintf("/Length %d\n", stream.Len()))
+	x.buffer.WriteString(">>\n")
+	x.buffer.WriteString("stream\n")
+	x.buffer.Write(stream.Bytes())
+	x.buffer.WriteString("endstream\n")
+	return nil
+}
+
+func (x *FormXObj) GetType() string {
+	return "FormXObject"
+}
+
+func (x *FormXObj) GetObjBuff() *bytes.Buffer {
+	return &(x.buffer)
+}
+
+//AddTextField : add text field named name with value drawn in current font , x,y is the upper left corner of field ,
+//opts set javascript actions of field and multiline (value is wrapped into lines inside field) ,
+//field is placed through current transform (Scale , cm of RawContent) like other drawing (also AddChoiceField and AddCheckBox)
+func (gp *GoPdf) AddTextField(name string, x float64, y float64, w float64, h float64, value string, opts ...FormFieldOption) error {
+	fontName, indexOfFont, err := gp.formFont()
+	if err != nil {
+		return err
+	}
+	var option FormFieldOption
+	if len(opts) > 0 {
+		option = opts[0]
+	}
+	ops, err := gp.formOps(func() error {
+		if option.Multiline {
+			gp.Curr.X = x + 2
+			gp.Curr.Y = y + 2
+			return gp.MultiCell(w-4, 0, value)
+		}
+		gp.Curr.X = x + 2
+		gp.Curr.Y = y
+		gp.Cell(&Rect{W: w - 4, H: h}, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	field := &FormFieldObj{fieldType: "Tx", name: name, value: value, option: option}
+	if option.Multiline {
+		field.flags = formFieldMultiline
+	}
+	gp.addFormField(field, x, y, w, h, fontName, indexOfFont, ops)
+	return nil
+}
+
+//AddChoiceField : add combo box named name with choices and selected value (one of choices) drawn in current font ,
+//x,y is the upper left corner of field
+func (gp *GoPdf) AddChoiceField(name string, x float64, y float64, w float64, h float64, choices []string, value string, opts ...FormFieldOption) error {
+	found := false
+	for _, choice := range choices {
+		if choice == value {
+			found = true
+		}
+	}
+	if !found {
+		return ErrUnknownChoice
+	}
+	fontName, indexOfFont, err := gp.formFont()
+	if err != nil {
+		return err
+	}
+	ops, err := gp.formOps(func() error {
+		gp.Curr.X = x + 2
+		gp.Curr.Y = y
+		gp.Cell(&Rect{W: w - 4, H: h}, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	field := &FormFieldObj{fieldType: "Ch", name: name, value: value, flags: formFieldCombo, choices: choices}
+	if len(opts) > 0 {
+		field.option = opts[0]
+	}
+	gp.addFormField(field, x, y, w, h, fontName, indexOfFont, ops)
+	return nil
+}
+
+//AddCheckBox : add check box named name of size x size , x,y is the upper left corner ,
+//box and check mark are drawn with current stroke color and line width
+func (gp *GoPdf) AddCheckBox(name string, x float64, y float64, size float64, checked bool, opts ...FormFieldOption) error {
+	off, err := gp.formOps(func() error {
+		gp.Rectangle(x, y, size, size)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	on, err := gp.formOps(func() error {
+		gp.Rectangle(x, y, size, size)
+		gp.Line(x+size*0.2, y+size*0.55, x+size*0.4, y+size*0.8)
+		gp.Line(x+size*0.4, y+size*0.8, x+size*0.8, y+size*0.2)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	field := &FormFieldObj{fieldType: "Btn", name: name, value: "Off"}
+	if checked {
+		field.value = "Yes"
+	}
+	if len(opts) > 0 {
+		field.option = opts[0]
+	}
+	offAppearance := &FormXObj{ops: off, bbox: gp.formRect(x, y, size, size)}
+	offAppearance.Init(func() *GoPdf {
+		return gp
+	})
+	field.indexOfOffAppearance = gp.addObj(offAppearance)
+	gp.addFormField(field, x, y, size, size, "", -1, on)
+	return nil
+}
+
+//formFont : resource name and index of font obj of current font
+func (gp *GoPdf) formFont() (string, int, error) {
+	if gp.Curr.Font_ISubset == nil && gp.Curr.Font_IFont == nil {
+		return "", -1, ErrFontNotSet
+	}
+	fontName := fmt.Sprintf("F%d", gp.Curr.Font_FontCount+1)
+	indexOfFont := -1
+	if gp.indexOfProcSet != -1 {
+		for _, realte := range gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj).Realtes {
+			if realte.CountOfFont == gp.Curr.Font_FontCount {
+				indexOfFont = realte.IndexOfObj
+			}
+		}
+	}
+	if indexOfFont == -1 {
+		return "", -1, ErrFontNotSet
+	}
+	return fontName, indexOfFont, nil
+}
+
+//formOps : ops that draw adds to content , they are cut out of content to be appearance of field
+func (gp *GoPdf) formOps(draw func() error) ([]byte, error) {
+	content := gp.getContent()
+	start := content.stream.Len()
+	currX, currY := gp.Curr.X, gp.Curr.Y
+	err := draw()
+	ops := append([]byte(nil), content.stream.Bytes()[start:]...)
+	content.stream.Truncate(start)
+	gp.Curr.X, gp.Curr.Y = currX, currY
+	return ops, err
+}
+
+//identityTransform : transform matrix (a b c d e f) that does not move anything
+var identityTransform = [6]float64{1, 0, 0, 1, 0, 0}
+
+//currentTransform : transform matrix (a b c d e f) of graphics state at end of content of current page ,
+//from cm , q and Q operators (Scale , RawContent ...)
+func (gp *GoPdf) currentTransform() [6]float64 {
+	ctm := identityTransform
+	if gp.Curr.IndexOfPageObj == -1 {
+		return ctm
+	}
+	var stack [][6]float64
+	//content ของหน้าคือ content ที่อยู่หลังหน้าจนถึงหน้าถัดไป (เหมือน prepare)
+	for i := gp.Curr.IndexOfPageObj + 1; i < len(gp.pdfObjs); i++ {
+		if gp.pdfObjs[i].GetType() == "Page" {
+			break
+		}
+		content, ok := gp.pdfObjs[i].(*ContentObj)
+		if !ok {
+			continue
+		}
+		walkContentStream(content.stream.Bytes(), func(op string, operands []float64, operandStart int, end int) {
+			switch op {
+			case "q":
+				stack = append(stack, ctm)
+			case "Q":
+				if len(stack) > 0 {
+					ctm = stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+				}
+			case "cm":
+				if len(operands) == 6 {
+					var m [6]float64
+					copy(m[:], operands)
+					ctm = multiplyTransform(m, ctm)
+				}
+			}
+		})
+	}
+	return ctm
+}
+
+//multiplyTransform : matrix m x n (transform by m then by n)
+func multiplyTransform(m [6]float64, n [6]float64) [6]float64 {
+	return [6]float64{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+//transformRect : bounding box (llx lly urx ury) of rect (llx lly urx ury) transformed by ctm
+func transformRect(ctm [6]float64, rect []float64) []float64 {
+	if ctm == identityTransform {
+		return rect
+	}
+	result := []float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, corner := range [][2]float64{{rect[0], rect[1]}, {rect[2], rect[1]}, {rect[0], rect[3]}, {rect[2], rect[3]}} {
+		x := ctm[0]*corner[0] + ctm[2]*corner[1] + ctm[4]
+		y := ctm[1]*corner[0] + ctm[3]*corner[1] + ctm[5]
+		result[0], result[1] = math.Min(result[0], x), math.Min(result[1], y)
+		result[2], result[3] = math.Max(result[2], x), math.Max(result[3], y)
+	}
+	return result
+}
+
+//formRect : rect (llx lly urx ury in pdf space) of field at x,y (upper left corner)
+func (gp *GoPdf) formRect(x float64, y float64, w float64, h float64) []float64 {
+	pageH := gp.config.PageSize.H
+	return []float64{x, pageH - (y + h), x + w, pageH - y}
+}
+
+//addFormField : add field with appearance ops to current page and /AcroForm , fontName is "" if field has no text
+func (gp *GoPdf) addFormField(field *FormFieldObj, x float64, y float64, w float64, h float64, fontName string, indexOfFont int, ops []byte) {
+	rect := gp.formRect(x, y, w, h)
+	appearance := &FormXObj{ops: ops, bbox: rect, fontName: fontName, indexOfFont: indexOfFont}
+	appearance.Init(func() *GoPdf {
+		return gp
+	})
+	//Rect ของ widget อยู่ใน default user space จึงต้องผ่าน transform ปัจจุบัน (Scale , cm ของ RawContent ...)
+	ctm := gp.currentTransform()
+	field.rect = transformRect(ctm, rect)
+	field.indexOfPage = gp.Curr.IndexOfPageObj
+	if fontName != "" {
+		field.da = fmt.Sprintf("/%s %d Tf 0 g", fontName, gp.Curr.Font_Size)
+	}
+	if field.fieldType != "Btn" {
+		field.indexOfOffAppearance = -1
+	}
+	field.indexOfAppearance = gp.addObj(appearance)
+	index := gp.addObj(field)
+	gp.indexOfFormFields = append(gp.indexOfFormFields, index)
+	gp.pdfObjs[0].(*CatalogObj).AddFormField(index, fontName, indexOfFont)
+	page := gp.pdfObjs[gp.Curr.IndexOfPageObj].(*PageObj)
+	page.indexOfAnnots = append(page.indexOfAnnots, index)
+	//ตอน flatten วาดสถานะที่เลือกอยู่
+	if field.value == "Off" && field.indexOfOffAppearance != -1 {
+		ops = gp.pdfObjs[field.indexOfOffAppearance].(*FormXObj).ops
+	}
+	if ctm != identityTransform {
+		ops = append([]byte(fmt.Sprintf("%0.4f %0.4f %0.4f %0.4f %0.2f %0.2f cm\n", ctm[0], ctm[1], ctm[2], ctm[3], ctm[4], ctm[5])), ops...)
+	}
+	content := gp.getContent()
+	content.flattenOps = append(content.flattenOps, ops)
+}
+
+//FlattenForms : when pdf is built , values of form fields are drawn into page content and
+//fields , widget annotations and /AcroForm are removed (pdf is not interactive)
+func (gp *GoPdf) FlattenForms() {
+	gp.isFlattenForms = true
+}
+
+//formObjSkips : objs of form fields and their appearance , removed when forms are flattened
+func (gp *GoPdf) formObjSkips(skips map[int]bool) map[int]bool {
+	if !gp.isFlattenForms || len(gp.indexOfFormFields) == 0 {
+		return skips
+	}
+	merged := make(map[int]bool)
+	for index := range skips {
+		merged[index] = true
+	}
+	for _, index := range gp.indexOfFormFields {
+		merged[index] = true
+		for _, appearance := range gp.pdfObjs[index].(*FormFieldObj).appearances() {
+			merged[appearance] = true
+		}
+	}
+	return merged
+}
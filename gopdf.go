@@ -2,9 +2,15 @@ package gopdf
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
+	"image"
+	"io"
+	"io/fs"
 	ioutil "io/ioutil"
 	"log"
+	"math"
 	"os"
 	//"container/list"
 	"fmt"
@@ -12,6 +18,39 @@ import (
 	"strings"
 )
 
+//ErrFontNotSet : no font is set (call SetFont first)
+var ErrFontNotSet = errors.New("font not set")
+
+//ErrUnbalancedGraphicsState : q/Q of page not balanced (strict graphics state)
+var ErrUnbalancedGraphicsState = errors.New("unbalanced graphics state (q/Q)")
+
+//ErrCropBoxOutOfMediaBox : crop box must lie within the page
+var ErrCropBoxOutOfMediaBox = errors.New("crop box is out of media box")
+
+//ErrPageOutOfRange : page number not exist in document
+var ErrPageOutOfRange = errors.New("page out of range")
+
+//ErrUnknownPageLabelStyle : style of SetPageLabel is not decimal , upperRoman , lowerRoman , upperAlpha or lowerAlpha
+var ErrUnknownPageLabelStyle = errors.New("unknown page label style")
+
+//ErrFontFamilyNotFound : no font was added with the family
+var ErrFontFamilyNotFound = errors.New("not found font family")
+
+//ErrUnknownContentStreamMode : mode of SetContentStreamMode is not "single" or "perPage"
+var ErrUnknownContentStreamMode = errors.New("unknown content stream mode")
+
+//ErrUnknownPaintStyle : style is not "D" , "F" , "FD" or "DF"
+var ErrUnknownPaintStyle = errors.New("unknown paint style")
+
+//ErrInvalidTileSize : width or height of tile is not more than 0
+var ErrInvalidTileSize = errors.New("invalid tile size")
+
+//ErrFontOptionConflict : font of same family and path is added again with other FontOption
+var ErrFontOptionConflict = errors.New("font is already added with other option")
+
+//ErrTooFewSides : polygon has less than 3 sides or star has less than 2 points
+var ErrTooFewSides = errors.New("too few sides")
+
 //GoPdf : A simple library for generating PDF written in Go lang
 type GoPdf struct {
 
@@ -38,10 +77,102 @@ type GoPdf struct {
 	//index ของ procset ซึ่งควรจะมีอันเดียว
 	indexOfProcSet int
 
+	//index ของ OutlinesObj (สร้างเมื่อ AddOutline ครั้งแรก)
+	indexOfOutlinesObj int
+
+	//perPage (default "") หรือ single ดู SetContentStreamMode
+	contentStreamMode string
+	//index ของ content ที่ถูกรวมเข้า content แรกของหน้า (single) ไม่ต้องเขียน
+	indexOfMergedContents map[int]bool
+
+	//index ของ OCGObj ของเส้น guide (สร้างเมื่อ AddGuide ครั้งแรก)
+	indexOfGuideOCG int
+	//dry-run ของ BeginMeasure (nil = วาดจริง)
+	measure *measureState
+
+	//top , middle , baseline , bottom
+	cellVerticalAlign string
+
+	//ตัวคูณความสูงบรรทัดของ MultiCell
+	leadingFactor float64
+
+	//ระยะก่อนและหลังย่อหน้าของ MultiCell
+	paragraphSpaceBefore float64
+	paragraphSpaceAfter  float64
+
+	//pattern ตัดคำด้วย hyphen ของ SetHyphenation (nil = ไม่ตัด)
+	hyphenator *hyphenator
+
+	//true = error ถ้า q/Q ไม่ครบคู่ , false = เติมให้ครบตอน build
+	isStrictGraphicsState bool
+
+	//แปลงสีทั้งหมดเป็นสีเทาตอน build
+	isGrayscaleOutput bool
+
+	//defaultColorSpace : color space of rgb colors ("" = RGB)
+	defaultColorSpace string
+
+	//fontFeatures : opentype features of ttf fonts (SetFontFeatures)
+	fontFeatures map[string]bool
+	//measureCache : widths of MeasureTextWidth (SetMeasureCacheSize)
+	measureCache     *measureCache
+	measureCacheSize int
+	//objectInfos : objs of last built pdf (Objects)
+	objectInfos []PdfObjectInfo
+
+	//จัดเรียงไฟล์สำหรับ fast web view
+	isLinearized bool
+
+	//ตัวคูณความกว้างของ space
+	spaceWidthFactor float64
+
+	//obj ของรูปที่วาดเฉพาะใน dry-run ของ BeginMeasure (ไม่เขียนลงใน pdf)
+	measureDrops map[int]bool
+
+	//ExtGState ปัจจุบันของหน้า และ ชื่อ resource ของ ExtGState ที่สร้างแล้ว (key ของ params -> GS1)
+	extGState      extGStateParams
+	extGStateNames map[string]string
+
+	//จำนวน tiling pattern (ชื่อ P1 , P2 ...)
+	countOfPattern int
+	//จำนวน shading (ชื่อ Sh1 , Sh2 ...)
+	countOfShading int
+	//จำนวน layer ของ AddLayer (ชื่อ OC1 , OC2 ...)
+	countOfLayer int
+	//font ของ script ที่ SmartWrite ใช้ (script -> family)
+	scriptFonts map[string]string
+	//index ของ FormFieldObj ทั้งหมด
+	indexOfFormFields []int
+	//วาด value ของ form field ลงใน content และตัด field ออก (FlattenForms)
+	isFlattenForms bool
+	//font ที่ใช้เมื่อยังไม่ได้ SetFont (SetDefaultFont)
+	defaultFontFamily string
+	defaultFontSize   int
+	//key ของไฟล์รูปใน image cache (path -> hash ของ data)
+	imageKeys map[string]string
+	//ฟังก์ชันที่แปลง bytes ของ pdf ก่อนส่งออก (SetPostProcessor)
+	postProcessor func(pdf []byte) ([]byte, error)
+	//offset ของแต่ละ obj ใน xref ของ pdf ที่ build ล่าสุด
+	xrefOffsets []int
+	//obj ที่ไม่เขียนลงใน pdf ที่กำลัง build (set ระหว่าง compile)
+	skipObjs map[int]bool
+
+	//รูปที่ลอยอยู่ในหน้าปัจจุบัน (AddImageFloat) ให้ MultiCell ตัดบรรทัดหลบ
+	imageFloats []imageFloat
+
+	//zlib level ของ content stream (0 = ไม่บีบอัด) และ ขนาดต่ำสุดที่จะบีบอัด
+	compressLevel   int
+	minCompressSize int
+
+	//template ที่วาดทุกครั้งที่ AddPage
+	pageTemplate       func()
+	isDrawPageTemplate bool
+
 	//IsUnderline bool
 }
 
-//SetLineWidth : set line width
+//SetLineWidth : set line width , 0 = thinnest line that device can render (hairline , same on every zoom and not scaled by Scale) ,
+//width that is not 0 is scaled by Scale and is never written as 0
 func (gp *GoPdf) SetLineWidth(width float64) {
 	gp.getContent().AppendStreamSetLineWidth(width)
 }
@@ -67,6 +198,257 @@ func (gp *GoPdf) SetGrayStroke(grayScale float64) {
 	gp.getContent().AppendStreamSetGrayStroke(grayScale)
 }
 
+//SetFillColor : set the color for the fill (rgb 0-255)
+func (gp *GoPdf) SetFillColor(r uint8, g uint8, b uint8) {
+	gp.getContent().AppendStreamSetColorFill(r, g, b)
+}
+
+//SetStrokeColor : set the color for the stroke (rgb 0-255)
+func (gp *GoPdf) SetStrokeColor(r uint8, g uint8, b uint8) {
+	gp.getContent().AppendStreamSetColorStroke(r, g, b)
+}
+
+//SetGrayscaleOutput : convert rgb/cmyk colors and images to gray when build pdf
+func (gp *GoPdf) SetGrayscaleOutput(grayscale bool) {
+	gp.isGrayscaleOutput = grayscale
+}
+
+//SetCellVerticalAlign : vertical align of text in Cell
+//"top" (cap-height at top of cell) , "middle" (default) , "bottom" (descender at bottom of cell) , "baseline" (baseline at current y)
+func (gp *GoPdf) SetCellVerticalAlign(align string) {
+	gp.cellVerticalAlign = align
+}
+
+//SaveGraphicsState : push current graphics state (q) , must pair with RestoreGraphicsState
+func (gp *GoPdf) SaveGraphicsState() {
+	gp.getContent().AppendStreamSaveGraphicsState()
+}
+
+//RestoreGraphicsState : pop graphics state (Q) that saved by SaveGraphicsState
+func (gp *GoPdf) RestoreGraphicsState() {
+	gp.getContent().AppendStreamRestoreGraphicsState()
+}
+
+//Scale : scale content drawn after this by sx,sy around x,y (use between SaveGraphicsState and RestoreGraphicsState)
+func (gp *GoPdf) Scale(sx float64, sy float64, x float64, y float64) {
+	gp.getContent().AppendStreamScale(sx, sy, x, gp.config.PageSize.H-y)
+}
+
+//SetStrictGraphicsState : true = GetBytesPdfReturnErr return ErrUnbalancedGraphicsState if any page has unbalanced q/Q ,
+//false (default) = missing Q (or q) are added when build pdf
+func (gp *GoPdf) SetStrictGraphicsState(strict bool) {
+	gp.isStrictGraphicsState = strict
+}
+
+//SetLinearized : true = write linearized pdf ("fast web view") , first page can be displayed before whole file is downloaded
+func (gp *GoPdf) SetLinearized(linearized bool) {
+	gp.isLinearized = linearized
+}
+
+//SetDashPattern : set dash pattern of lines and borders drawn after this ,
+//dash is the lengths of dashes and gaps (nil or empty = solid line) , phase is the distance into the pattern to start
+func (gp *GoPdf) SetDashPattern(dash []float64, phase float64) {
+	gp.getContent().AppendStreamSetDashPattern(dash, phase)
+}
+
+//Rectangle : draw rectangle , x,y is the upper left corner
+func (gp *GoPdf) Rectangle(x float64, y float64, w float64, h float64) {
+	gp.getContent().AppendStreamRectangle(x, y, w, h)
+}
+
+//RoundedRectExt : draw rectangle (x,y is the upper left corner) with radius of each corner (top left , top right , bottom right , bottom left) ,
+//radius 0 = square corner , radii are scaled down together if corners next to each other overlap ,
+//style is "D" (default "" stroke) , "F" (fill) or "FD" , "DF" (fill and stroke)
+func (gp *GoPdf) RoundedRectExt(x float64, y float64, w float64, h float64, rTL float64, rTR float64, rBR float64, rBL float64, style string) error {
+	op, err := paintOperator(style)
+	if err != nil {
+		return err
+	}
+	radii := []float64{rTL, rTR, rBR, rBL}
+	for i, r := range radii {
+		radii[i] = math.Max(r, 0)
+	}
+	//ด้าน บน ขวา ล่าง ซ้าย กับ radius ของมุมทั้งสองข้าง
+	scale := 1.0
+	for i, side := range []float64{w, h, w, h} {
+		if sum := radii[i] + radii[(i+1)%4]; sum > side && sum > 0 {
+			scale = math.Min(scale, side/sum)
+		}
+	}
+	for i := range radii {
+		radii[i] *= scale
+	}
+	gp.getContent().AppendStreamRoundedRect(x, y, w, h, radii, op)
+	return nil
+}
+
+//Polygon : draw closed path through points with current fill and stroke color ,
+//style is "D" (default "" stroke) , "F" (fill) or "FD" , "DF" (fill and stroke)
+func (gp *GoPdf) Polygon(points []Point, style string) error {
+	op, err := paintOperator(style)
+	if err != nil {
+		return err
+	}
+	if len(points) < 3 {
+		return ErrTooFewSides
+	}
+	gp.getContent().AppendStreamPolygon(points, op)
+	return nil
+}
+
+//RegularPolygon : draw polygon with sides (at least 3) of same length whose corners are on circle at cx,cy radius r ,
+//first corner is at top of circle turned clockwise by rotate (degree) , style is same as Polygon
+func (gp *GoPdf) RegularPolygon(cx float64, cy float64, r float64, sides int, rotate float64, style string) error {
+	if sides < 3 {
+		return ErrTooFewSides
+	}
+	radii := make([]float64, sides)
+	for i := range radii {
+		radii[i] = r
+	}
+	return gp.Polygon(circlePoints(cx, cy, radii, rotate), style)
+}
+
+//Star : draw star with points (at least 2) on circle at cx,cy radius outerR and inner corners on circle radius innerR ,
+//first point is at top of circle turned clockwise by rotate (degree) , style is same as Polygon
+func (gp *GoPdf) Star(cx float64, cy float64, outerR float64, innerR float64, points int, rotate float64, style string) error {
+	if points < 2 {
+		return ErrTooFewSides
+	}
+	radii := make([]float64, points*2)
+	for i := range radii {
+		radii[i] = outerR
+		if i%2 == 1 {
+			radii[i] = innerR
+		}
+	}
+	return gp.Polygon(circlePoints(cx, cy, radii, rotate), style)
+}
+
+//circlePoints : points at same angle from each other around cx,cy , distance of each point is its radius ,
+//first point is at top turned clockwise by rotate (degree)
+func circlePoints(cx float64, cy float64, radii []float64, rotate float64) []Point {
+	points := make([]Point, len(radii))
+	for i, r := range radii {
+		angle := (rotate + float64(i)*360/float64(len(radii))) * math.Pi / 180
+		points[i] = Point{X: cx + r*math.Sin(angle), Y: cy - r*math.Cos(angle)}
+	}
+	return points
+}
+
+//paintOperator : path painting operator of style "D" (default "") , "F" , "FD" or "DF"
+func paintOperator(style string) (string, error) {
+	op, ok := map[string]string{"": "S", "D": "S", "F": "f", "FD": "B", "DF": "B"}[style]
+	if !ok {
+		return "", ErrUnknownPaintStyle
+	}
+	return op, nil
+}
+
+//SetFontEncoding : set encoding of current simple font (AddFont) , name is "WinAnsiEncoding" or "MacRomanEncoding" ,
+//differences map runes to other codes (/Differences) , text of Cell is converted to codes of this encoding
+func (gp *GoPdf) SetFontEncoding(name string, differences ...FontEncodingDifference) error {
+	if name != "WinAnsiEncoding" && name != "MacRomanEncoding" {
+		return ErrUnknownFontEncoding
+	}
+	if gp.Curr.Font_Type != CURRENT_FONT_TYPE_IFONT || gp.Curr.Font_IFont == nil {
+		return ErrNotSimpleFont
+	}
+	gp.currEncodingObj().SetEncoding(name, differences)
+	gp.ClearMeasureCache()
+	return nil
+}
+
+//SetOverprint : set overprint of fill (text , fill) and stroke for drawing after this (prepress) ,
+//other ExtGState parameters of current page are kept
+func (gp *GoPdf) SetOverprint(fill bool, stroke bool) {
+	params := gp.extGState
+	params.overprintFill = fill
+	params.overprintStroke = stroke
+	gp.setExtGState(params)
+}
+
+//ResetGraphicsState : set fill and stroke color to black , line width to 1 , solid line , no overprint ,
+//full alpha , normal blend mode and no char spacing , word spacing or text rise (SetSpaceWidthFactor is reset to 1)
+func (gp *GoPdf) ResetGraphicsState() {
+	content := gp.getContent()
+	content.AppendStreamSetColor(Gray(0), false)
+	content.AppendStreamSetColor(Gray(0), true)
+	content.AppendStreamSetLineWidth(1)
+	content.AppendStreamSetDashPattern(nil, 0)
+	gp.setExtGState(extGStateParams{defaults: true})
+	//ext gstate ที่ reset แล้วไม่ต่างจากค่าเริ่มต้น
+	gp.extGState = extGStateParams{}
+	content.AppendStreamRaw("0 Tc 0 Tw 0 Ts")
+	gp.spaceWidthFactor = 1
+}
+
+//SetDocumentJavaScript : add javascript that runs when document is opened (/Names /JavaScript) , code is passed through as is ,
+//same name replaces the script
+func (gp *GoPdf) SetDocumentJavaScript(name string, code string) {
+	action := new(BasicObj)
+	action.Init(func() *GoPdf {
+		return gp
+	})
+	action.Data = "<<\n/S /JavaScript\n/JS (" + escapePdfString(code) + ")\n>>\n"
+	index := gp.addObj(action)
+	gp.pdfObjs[0].(*CatalogObj).AddJavaScript(name, index)
+}
+
+//SetPageLabel : label pages from startPage (1 = first page) to start of next range with style "decimal" (1, 2, 3) ,
+//"upperRoman" (I, II) , "lowerRoman" (i, ii) , "upperAlpha" (A, B) , "lowerAlpha" (a, b) or "" (prefix only) ,
+//numbers start at 1 in each range
+func (gp *GoPdf) SetPageLabel(startPage int, style string, prefix string) error {
+	styles := map[string]string{
+		"decimal":    "D",
+		"upperRoman": "R",
+		"lowerRoman": "r",
+		"upperAlpha": "A",
+		"lowerAlpha": "a",
+		"":           "",
+	}
+	name, ok := styles[style]
+	if !ok {
+		return ErrUnknownPageLabelStyle
+	}
+	if startPage < 1 {
+		return ErrPageOutOfRange
+	}
+	label := "<<"
+	if name != "" {
+		label += " /S /" + name
+	}
+	if prefix != "" {
+		label += " /P (" + escapePdfString(prefix) + ")"
+	}
+	label += " >>"
+	gp.pdfObjs[0].(*CatalogObj).SetPageLabel(startPage-1, label)
+	return nil
+}
+
+//SetLanguage : set natural language of document as BCP 47 language tag (sample "en-US" , "th-TH") ,
+//viewers and screen readers use it for text that has no language of its own
+func (gp *GoPdf) SetLanguage(bcp47 string) {
+	gp.pdfObjs[0].(*CatalogObj).SetLang(bcp47)
+}
+
+//SetDefaultFont : font family (added by AddTTFFont ...) and size that is set when text is drawn or page is added
+//and no font is set yet , style is ""
+func (gp *GoPdf) SetDefaultFont(family string, size int) {
+	gp.defaultFontFamily = family
+	gp.defaultFontSize = size
+	gp.applyDefaultFont()
+}
+
+//applyDefaultFont : set default font if no font is set
+func (gp *GoPdf) applyDefaultFont() {
+	if gp.defaultFontFamily == "" || gp.Curr.Font_ISubset != nil || gp.Curr.Font_IFont != nil {
+		return
+	}
+	//family อาจยังไม่ได้ add ลองใหม่ครั้งต่อไป
+	gp.SetFont(gp.defaultFontFamily, "", gp.defaultFontSize)
+}
+
 //SetLeftMargin : set left margin
 func (gp *GoPdf) SetLeftMargin(margin float64) {
 	gp.leftMargin = margin
@@ -97,16 +479,20 @@ func (gp *GoPdf) GetY() float64 {
 	return gp.Curr.Y
 }
 
-//Image : draw image
-func (gp *GoPdf) Image(picPath string, x float64, y float64, rect *Rect) {
+//Image : draw image , opts[0] set filter of image stream (default "auto") , image with invalid option is not drawn
+//(use ImageReturnErr to get error)
+func (gp *GoPdf) Image(picPath string, x float64, y float64, rect *Rect, opts ...ImageOption) {
+	gp.ImageReturnErr(picPath, x, y, rect, opts...)
+}
 
-	//check
-	cacheImageIndex := -1
-	for _, imgcache := range gp.Curr.ImgCaches {
-		if picPath == imgcache.Path {
-			cacheImageIndex = imgcache.Index
-			break
-		}
+//ImageReturnErr : same as Image but return ErrUnknownImageFilter if filter of opts[0] is unknown
+func (gp *GoPdf) ImageReturnErr(picPath string, x float64, y float64, rect *Rect, opts ...ImageOption) error {
+	var opt ImageOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if err := opt.validate(); err != nil {
+		return err
 	}
 
 	//create img object
@@ -115,30 +501,264 @@ func (gp *GoPdf) Image(picPath string, x float64, y float64, rect *Rect) {
 		return gp
 	})
 	imgobj.SetImagePath(picPath)
+	imgobj.SetOption(opt)
 	if rect == nil {
 		rect = imgobj.GetRect()
 	}
 
-	if cacheImageIndex == -1 { //new image
+	cacheImageIndex, _ := gp.imageOf(opt.cacheKey(gp.imageKey(picPath)), imgobj)
+	if cacheImageIndex != -1 {
+		gp.getContent().AppendStreamImage(cacheImageIndex, x, y, rect)
+	}
+	return nil
+}
 
-		index := gp.addObj(imgobj)
-		if gp.indexOfProcSet != -1 {
-			//ยัดรูป
-			procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
-			gp.getContent().AppendStreamImage(gp.Curr.CountOfImg, x, y, rect)
-			procset.RealteXobjs = append(procset.RealteXobjs, RealteXobject{IndexOfObj: index})
-			//เก็บข้อมูลรูปเอาไว้
-			var imgcache ImageCache
-			imgcache.Index = gp.Curr.CountOfImg
-			imgcache.Path = picPath
-			gp.Curr.ImgCaches = append(gp.Curr.ImgCaches, imgcache)
-			gp.Curr.CountOfImg++
-		}
+//ImageWithColorKey : same as Image but pixels with every RGB component between keyLow and keyHigh are transparent (/Mask color key , no soft mask) ,
+//color key works only with images that are not interpolated or indexed , jpeg is lossy so key range should have some tolerance
+func (gp *GoPdf) ImageWithColorKey(picPath string, x float64, y float64, rect *Rect, keyLow [3]uint8, keyHigh [3]uint8) {
+	imgobj := new(ImageObj)
+	imgobj.Init(func() *GoPdf {
+		return gp
+	})
+	imgobj.SetImagePath(picPath)
+	imgobj.SetColorKey(keyLow, keyHigh)
+	if rect == nil {
+		rect = imgobj.GetRect()
+	}
 
-	} else { //sagp img
+	//รูปเดียวกันที่ไม่มี color key เป็นคนละ obj
+	cacheKey := fmt.Sprintf("%s#colorkey%v%v", gp.imageKey(picPath), keyLow, keyHigh)
+	cacheImageIndex, _ := gp.imageOf(cacheKey, imgobj)
+	if cacheImageIndex != -1 {
 		gp.getContent().AppendStreamImage(cacheImageIndex, x, y, rect)
 	}
+}
 
+//ImageRotated : same as Image but image is rotated by angle (degree , counter-clockwise) around anchor of opts (default center of image) ,
+//return upper left corner and size of bounding box of rotated image , current position is moved to lower left corner of bounding box
+func (gp *GoPdf) ImageRotated(picPath string, x float64, y float64, rect *Rect, angle float64, opts ...ImageRotatedOption) (float64, float64, Rect) {
+	var opt ImageRotatedOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	imgobj := new(ImageObj)
+	imgobj.Init(func() *GoPdf {
+		return gp
+	})
+	imgobj.SetImagePath(picPath)
+	if rect == nil {
+		rect = imgobj.GetRect()
+	}
+
+	anchorX, anchorY := opt.anchorOf(x, y, rect)
+	boxX, boxY, box := rotatedBounds(x, y, rect, angle*math.Pi/180, anchorX, anchorY)
+	cacheImageIndex, _ := gp.imageOf(gp.imageKey(picPath), imgobj)
+	if cacheImageIndex != -1 {
+		gp.measureArea(boxX, boxY, box.W, box.H)
+		gp.getContent().AppendStreamRotatedImage(cacheImageIndex, x, y, rect, angle*math.Pi/180, anchorX, gp.config.PageSize.H-anchorY)
+	}
+	gp.Curr.X = boxX
+	gp.Curr.Y = boxY + box.H
+	return boxX, boxY, box
+}
+
+//rotatedBounds : upper left corner and size of bounding box of rect at x,y (upper left) rotated by angle (radian , counter-clockwise)
+//around anchorX,anchorY
+func rotatedBounds(x float64, y float64, rect *Rect, angle float64, anchorX float64, anchorY float64) (float64, float64, Rect) {
+	cos := math.Cos(angle)
+	sin := math.Sin(angle)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range []Point{{X: x, Y: y}, {X: x + rect.W, Y: y}, {X: x, Y: y + rect.H}, {X: x + rect.W, Y: y + rect.H}} {
+		//y ของหน้ากลับด้านกับ pdf จึงหมุนตามเข็มนาฬิกาเมื่อมองใน space ของหน้า
+		dx, dy := corner.X-anchorX, corner.Y-anchorY
+		px := anchorX + dx*cos + dy*sin
+		py := anchorY - dx*sin + dy*cos
+		minX, maxX = math.Min(minX, px), math.Max(maxX, px)
+		minY, maxY = math.Min(minY, py), math.Max(maxY, py)
+	}
+	return minX, minY, Rect{W: maxX - minX, H: maxY - minY}
+}
+
+//ImageMask : draw 1 bit stencil of mask with current fill color , dark and opaque pixels are painted and the others are transparent ,
+//rect nil = size of mask at 128 dpi (same as Image)
+func (gp *GoPdf) ImageMask(mask image.Image, x float64, y float64, rect *Rect) {
+	if rect == nil {
+		bounds := mask.Bounds()
+		rect = &Rect{W: float64(bounds.Dx() * 72 / 128), H: float64(bounds.Dy() * 72 / 128)}
+	}
+	//mask แต่ละอันเป็น obj ใหม่
+	key := fmt.Sprintf("#imagemask%d", len(gp.pdfObjs))
+	cacheImageIndex, _ := gp.imageOf(key, &ImageMaskObj{mask: mask})
+	if cacheImageIndex != -1 {
+		gp.getContent().AppendStreamImage(cacheImageIndex, x, y, rect)
+	}
+}
+
+//ImageTiled : fill rectangle x,y (upper left) ,w,h with image repeated as tiles of tileW x tileH start at upper left ,
+//image is embedded once and painted by tiling pattern
+func (gp *GoPdf) ImageTiled(picPath string, x float64, y float64, w float64, h float64, tileW float64, tileH float64) error {
+	if tileW <= 0 || tileH <= 0 {
+		return ErrInvalidTileSize
+	}
+	imgobj := new(ImageObj)
+	imgobj.Init(func() *GoPdf {
+		return gp
+	})
+	imgobj.SetImagePath(picPath)
+	if _, err := imgobj.bounds(); err != nil {
+		return err
+	}
+	_, indexOfImageObj := gp.imageOf(gp.imageKey(picPath), imgobj)
+	if indexOfImageObj == -1 {
+		return nil
+	}
+
+	pageH := gp.config.PageSize.H
+	pattern := &TilingPatternObj{
+		indexOfImageObj: indexOfImageObj,
+		tileW:           tileW,
+		tileH:           tileH,
+		originX:         x,
+		originY:         pageH - y - tileH,
+	}
+	gp.countOfPattern++
+	name := fmt.Sprintf("P%d", gp.countOfPattern)
+	gp.AddResource("Pattern", name, pattern)
+	gp.getContent().AppendStreamRaw(fmt.Sprintf("q /Pattern cs /%s scn %0.2f %0.2f %0.2f %0.2f re f Q", name, x, pageH-(y+h), w, h))
+	return nil
+}
+
+//RadialGradient : paint radial gradient from color c1 at circle r0 to c2 at circle r1 around cx,cy ,
+//gradient is clipped to outer circle or to opts[0].Clip
+func (gp *GoPdf) RadialGradient(cx float64, cy float64, r0 float64, r1 float64, c1 [3]uint8, c2 [3]uint8, opts ...RadialGradientOption) {
+	var opt RadialGradientOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	pageH := gp.config.PageSize.H
+	shading := &RadialShadingObj{
+		cx:          cx,
+		cy:          pageH - cy,
+		r0:          r0,
+		r1:          r1,
+		c1:          c1,
+		c2:          c2,
+		extendStart: opt.ExtendStart,
+		extendEnd:   opt.ExtendEnd,
+	}
+	shading.Init(func() *GoPdf {
+		return gp
+	})
+	gp.countOfShading++
+	name := fmt.Sprintf("Sh%d", gp.countOfShading)
+	gp.AddResource("Shading", name, shading)
+
+	clip := circlePath(cx, pageH-cy, r1)
+	if opt.Clip != nil {
+		clip = fmt.Sprintf("%0.2f %0.2f %0.2f %0.2f re", opt.ClipX, pageH-(opt.ClipY+opt.Clip.H), opt.Clip.W, opt.Clip.H)
+	}
+	gp.getContent().AppendStreamRaw(fmt.Sprintf("q %s W n /%s sh Q", clip, name))
+}
+
+//circlePath : path of circle at cx,cy (pdf space) by 4 bezier curves
+func circlePath(cx float64, cy float64, r float64) string {
+	k := r * 0.5523 //ระยะ control point ของ bezier ที่ใกล้วงกลม
+	return fmt.Sprintf("%0.2f %0.2f m %0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c %0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c %0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c %0.2f %0.2f %0.2f %0.2f %0.2f %0.2f c h",
+		cx+r, cy,
+		cx+r, cy+k, cx+k, cy+r, cx, cy+r,
+		cx-k, cy+r, cx-r, cy+k, cx-r, cy,
+		cx-r, cy-k, cx-k, cy-r, cx, cy-r,
+		cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+}
+
+//ImageFit : draw image scaled to fit box (upper left corner at current position) without distortion ,
+//align is "center" (default "") or "left" , "right" with "top" , "bottom" (e.g. "top left") for the side that has space left ,
+//opts[0] set filter of image stream (default "auto")
+func (gp *GoPdf) ImageFit(picPath string, box Rect, align string, opts ...ImageOption) error {
+	var opt ImageOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if err := opt.validate(); err != nil {
+		return err
+	}
+	imgobj := new(ImageObj)
+	imgobj.Init(func() *GoPdf {
+		return gp
+	})
+	imgobj.SetImagePath(picPath)
+	imgobj.SetOption(opt)
+	bounds, err := imgobj.bounds()
+	if err != nil {
+		return err
+	}
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return nil
+	}
+	scale := math.Min(box.W/float64(bounds.Dx()), box.H/float64(bounds.Dy()))
+	rect := &Rect{W: float64(bounds.Dx()) * scale, H: float64(bounds.Dy()) * scale}
+
+	x := gp.Curr.X + (box.W-rect.W)/2
+	if strings.Contains(align, "left") {
+		x = gp.Curr.X
+	} else if strings.Contains(align, "right") {
+		x = gp.Curr.X + box.W - rect.W
+	}
+	y := gp.Curr.Y + (box.H-rect.H)/2
+	if strings.Contains(align, "top") {
+		y = gp.Curr.Y
+	} else if strings.Contains(align, "bottom") {
+		y = gp.Curr.Y + box.H - rect.H
+	}
+
+	cacheImageIndex, _ := gp.imageOf(opt.cacheKey(gp.imageKey(picPath)), imgobj)
+	if cacheImageIndex != -1 {
+		gp.getContent().AppendStreamImage(cacheImageIndex, x, y, rect)
+	}
+	return nil
+}
+
+//imageKey : key of image file in image cache , hash of file data so same image from other path (template , page , pattern) is the same obj
+func (gp *GoPdf) imageKey(picPath string) string {
+	if key, ok := gp.imageKeys[picPath]; ok {
+		return key
+	}
+	b, err := ioutil.ReadFile(picPath)
+	if err != nil {
+		return picPath
+	}
+	key := fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+	if gp.imageKeys == nil {
+		gp.imageKeys = make(map[string]string)
+	}
+	gp.imageKeys[picPath] = key
+	return key
+}
+
+//imageOf : index of image (for /I) and index of image obj of cacheKey , add imgobj if cacheKey is new image
+func (gp *GoPdf) imageOf(cacheKey string, imgobj IObj) (int, int) {
+	for _, imgcache := range gp.Curr.ImgCaches {
+		if cacheKey == imgcache.Path {
+			procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+			return imgcache.Index, procset.RealteXobjs[imgcache.Index].IndexOfObj
+		}
+	}
+
+	index := gp.addObj(imgobj)
+	if gp.indexOfProcSet == -1 {
+		return -1, index
+	}
+	//ยัดรูป
+	procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+	procset.RealteXobjs = append(procset.RealteXobjs, RealteXobject{IndexOfObj: index})
+	//เก็บข้อมูลรูปเอาไว้
+	var imgcache ImageCache
+	imgcache.Index = gp.Curr.CountOfImg
+	imgcache.Path = cacheKey
+	gp.Curr.ImgCaches = append(gp.Curr.ImgCaches, imgcache)
+	gp.Curr.CountOfImg++
+	return imgcache.Index, index
 }
 
 //AddPage : add new page
@@ -156,7 +776,114 @@ func (gp *GoPdf) AddPage() {
 
 	//reset
 	gp.indexOfContent = -1
+	gp.extGState = extGStateParams{}
+	gp.imageFloats = nil
 	gp.resetCurrXY()
+	gp.applyDefaultFont()
+
+	gp.drawPageTemplate()
+}
+
+//AddGuide : draw guide line from x0,y0 to x1,y1 in layer "Guides" that is shown on screen but not printed
+func (gp *GoPdf) AddGuide(x0 float64, y0 float64, x1 float64, y1 float64) {
+	if gp.indexOfGuideOCG == -1 {
+		gp.indexOfGuideOCG = gp.addOCG("OCGuides", &OCGObj{name: "Guides", printState: "OFF", viewState: "ON"})
+	}
+	gp.getContent().AppendStreamOptionalContent("OCGuides", func(c *ContentObj) {
+		c.AppendStreamLine(x0, y0, x1, y1)
+	})
+}
+
+//addOCG : add optional content group as /Properties resource named name , return index of obj
+func (gp *GoPdf) addOCG(name string, ocg *OCGObj) int {
+	gp.AddResource("Properties", name, ocg)
+	index := len(gp.pdfObjs) - 1
+	gp.pdfObjs[0].(*CatalogObj).AddOCG(index)
+	return index
+}
+
+//AddOutline : add bookmark to top of current page (nothing is added if no page has been added)
+func (gp *GoPdf) AddOutline(title string) {
+	gp.AddOutlineWithPosition(title, Destination{})
+}
+
+//AddOutlineWithPosition : add bookmark to current page that open it with view dest (sample Destination{Kind: "FitH", Top: y}) ,
+//ErrPageOutOfRange if no page has been added
+func (gp *GoPdf) AddOutlineWithPosition(title string, dest Destination) error {
+	if gp.Curr.IndexOfPageObj == -1 {
+		return ErrPageOutOfRange
+	}
+	destArray, err := dest.array(gp.Curr.IndexOfPageObj, gp.config.PageSize.H)
+	if err != nil {
+		return err
+	}
+	if gp.indexOfOutlinesObj == -1 {
+		outlines := new(OutlinesObj)
+		outlines.Init(func() *GoPdf {
+			return gp
+		})
+		gp.indexOfOutlinesObj = gp.addObj(outlines)
+		gp.pdfObjs[0].(*CatalogObj).SetIndexOfOutlines(gp.indexOfOutlinesObj)
+	}
+	outlines := gp.pdfObjs[gp.indexOfOutlinesObj].(*OutlinesObj)
+	outline := &OutlineObj{
+		title:       title,
+		dest:        destArray,
+		parent:      outlines,
+		indexOfRoot: gp.indexOfOutlinesObj,
+		indexOfPage: gp.Curr.IndexOfPageObj,
+	}
+	outline.indexOfOwn = gp.addObj(outline)
+	outlines.indexOfItems = append(outlines.indexOfItems, outline.indexOfOwn)
+	return nil
+}
+
+//SetCropBox : set visible area of current page (viewer clip display to it) , x,y is the upper left corner ,
+//the crop box must lie within the page , default crop box is the whole page
+func (gp *GoPdf) SetCropBox(x float64, y float64, w float64, h float64) error {
+	pageW := gp.config.PageSize.W
+	pageH := gp.config.PageSize.H
+	if x < 0 || y < 0 || w <= 0 || h <= 0 || x+w > pageW || y+h > pageH {
+		return ErrCropBoxOutOfMediaBox
+	}
+	page := gp.pdfObjs[gp.Curr.IndexOfPageObj].(*PageObj)
+	page.cropBox = []float64{x, pageH - (y + h), x + w, pageH - y}
+	return nil
+}
+
+//AddPageNoTemplate : add new page without drawing the page template
+func (gp *GoPdf) AddPageNoTemplate() {
+	tmp := gp.pageTemplate
+	gp.pageTemplate = nil
+	gp.AddPage()
+	gp.pageTemplate = tmp
+}
+
+//SetContentStreamMode : how /Contents of page refers to its content streams ,
+//"perPage" (default) every page has its own content stream , a page with several streams refers to all of them as array ,
+//"single" several streams of a page are merged so /Contents is always one stream
+func (gp *GoPdf) SetContentStreamMode(mode string) error {
+	if mode != "single" && mode != "perPage" {
+		return ErrUnknownContentStreamMode
+	}
+	gp.contentStreamMode = mode
+	return nil
+}
+
+//GeneratePages : add one page (with page template) for each render and draw it by render , stop at first render that return error
+func (gp *GoPdf) GeneratePages(renders []func(p *GoPdf) error) error {
+	for _, render := range renders {
+		gp.AddPage()
+		if err := render(gp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//SetPageTemplate : set drawing func that runs at the start of every new page (nil = no template)
+func (gp *GoPdf) SetPageTemplate(draw func()) {
+	gp.pageTemplate = draw
 }
 
 //Start : init gopdf
@@ -206,52 +933,254 @@ func (gp *GoPdf) SetFont(family string, style string, size int) error {
 	}
 
 	if !found { //find SubsetFont
-		i = 0
-		max = len(gp.pdfObjs)
-		for i < max {
-			if gp.pdfObjs[i].GetType() == "SubsetFont" {
-				obj := gp.pdfObjs[i]
-				sub, ok := obj.(*SubsetFontObj)
-				if ok {
-					if sub.GetFamily() == family {
-						gp.Curr.Font_Size = size
-						gp.Curr.Font_Style = style
-						gp.Curr.Font_FontCount = sub.CountOfFont
-						gp.Curr.Font_Type = CURRENT_FONT_TYPE_SUBSET
-						gp.Curr.Font_IFont = nil
-						gp.Curr.Font_ISubset = sub
-						found = true
-						break
-					}
-				}
+		sub := gp.findSubsetFont(family)
+		if sub != nil {
+			gp.Curr.Font_Size = size
+			gp.Curr.Font_Style = style
+			gp.Curr.Font_FontCount = sub.CountOfFont
+			gp.Curr.Font_Type = CURRENT_FONT_TYPE_SUBSET
+			gp.Curr.Font_IFont = nil
+			gp.Curr.Font_ISubset = sub
+			found = true
+		}
+	}
+
+	if !found {
+		return ErrFontFamilyNotFound
+	}
+
+	return nil
+}
+
+//WritePdf : wirte pdf file
+func (gp *GoPdf) WritePdf(pdfPath string) {
+	ioutil.WriteFile(pdfPath, gp.GetBytesPdf(), 0644)
+}
+
+//GetBytesPdfReturnErr : get bytes of pdf file
+func (gp *GoPdf) GetBytesPdfReturnErr() ([]byte, error) {
+	return gp.getBytesPdfContext(context.Background())
+}
+
+//WriteContext : write pdf file to w , stop and return ctx.Err() if ctx is done before pdf is built (checked per page)
+func (gp *GoPdf) WriteContext(ctx context.Context, w io.Writer) error {
+	b, err := gp.getBytesPdfContext(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (gp *GoPdf) getBytesPdfContext(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	gp.prepare()
+	var b []byte
+	var err error
+	if gp.isLinearized {
+		b, err = gp.compileLinearized(ctx)
+	} else {
+		b, err = gp.compile(ctx, nil)
+	}
+	if err != nil || gp.postProcessor == nil {
+		return b, err
+	}
+	return gp.postProcessor(b)
+}
+
+//SetPostProcessor : fn transform bytes of pdf after it is built by GetBytesPdf , WritePdf ... (sample sign or optimize) ,
+//use XrefOffsets to find objs in pdf
+func (gp *GoPdf) SetPostProcessor(fn func(pdf []byte) ([]byte, error)) {
+	gp.postProcessor = fn
+}
+
+//XrefOffsets : byte offset of each obj (obj number - 1) in pdf that is built last , -1 = free obj ,
+//nil if pdf is not built yet or is linearized (objs are renumbered)
+func (gp *GoPdf) XrefOffsets() []int {
+	return gp.xrefOffsets
+}
+
+//ExtractPage : get bytes of a new pdf file that contains only page n (start at 1) ,
+//objs that page n does not use (fonts , images , bookmarks and form fields of other pages) are removed
+func (gp *GoPdf) ExtractPage(n int) ([]byte, error) {
+	gp.prepare()
+	pagesObj := gp.pdfObjs[gp.indexOfPagesObj].(*PagesObj)
+	if n < 1 || n > pagesObj.PageCount {
+		return nil, ErrPageOutOfRange
+	}
+
+	//ตัด page และ content ของหน้าอื่นๆ ออก
+	skips := make(map[int]bool)
+	indexOfPage := -1
+	pageNo := 0
+	for i, obj := range gp.pdfObjs {
+		objtype := obj.GetType()
+		if objtype == "Page" {
+			pageNo++
+			if pageNo == n {
+				indexOfPage = i
+			} else {
+				skips[i] = true
+			}
+		} else if objtype == "Content" && pageNo != n {
+			skips[i] = true
+		}
+	}
+	//form field ของหน้าอื่นและ appearance ของมัน
+	for _, index := range gp.indexOfFormFields {
+		field := gp.pdfObjs[index].(*FormFieldObj)
+		if field.indexOfPage != indexOfPage {
+			skips[index] = true
+			for _, appearance := range field.appearances() {
+				skips[appearance] = true
+			}
+		}
+	}
+	//bookmark ที่ชี้ไปหน้าอื่น (ถ้าไม่เหลือเลยก็ไม่มี /Outlines)
+	if gp.indexOfOutlinesObj != -1 {
+		outlines := gp.pdfObjs[gp.indexOfOutlinesObj].(*OutlinesObj)
+		count := 0
+		for _, index := range outlines.indexOfItems {
+			if gp.pdfObjs[index].(*OutlineObj).indexOfPage != indexOfPage {
+				skips[index] = true
+				count++
+			}
+		}
+		if count == len(outlines.indexOfItems) {
+			skips[gp.indexOfOutlinesObj] = true
+		}
+	}
+
+	kids := pagesObj.Kids
+	pageCount := pagesObj.PageCount
+	pagesObj.Kids = fmt.Sprintf("%d 0 R ", indexOfPage+1)
+	pagesObj.PageCount = 1
+	defer func() {
+		pagesObj.Kids = kids
+		pagesObj.PageCount = pageCount
+	}()
+	skips, err := gp.unusedObjSkips(indexOfPage, skips)
+	if err != nil {
+		return nil, err
+	}
+	return gp.compile(context.Background(), skips)
+}
+
+//unusedObjSkips : add objs that are not referred from catalog to skips ,
+//layers that page (index of page obj) does not use are removed from catalog too
+func (gp *GoPdf) unusedObjSkips(indexOfPage int, skips map[int]bool) (map[int]bool, error) {
+	skips = gp.buildSkips(skips)
+	gp.skipObjs = skips
+	defer func() {
+		gp.skipObjs = nil
+	}()
+	max := len(gp.pdfObjs)
+	refs := make([][]int, max)
+	for i, pdfObj := range gp.pdfObjs {
+		if skips[i] {
+			continue
+		}
+		pdfObj.GetObjBuff().Reset()
+		if err := pdfObj.Build(); err != nil {
+			return nil, err
+		}
+		refs[i] = objRefsOf(pdfObj.GetObjBuff().Bytes(), max)
+	}
+
+	merged := make(map[int]bool)
+	for index := range skips {
+		merged[index] = true
+	}
+	used := map[int]bool{indexOfPage: true}
+	gp.collectObjRefs(indexOfPage, refs, used)
+	for i, pdfObj := range gp.pdfObjs {
+		if pdfObj.GetType() == "OCG" && !used[i] {
+			merged[i] = true
+		}
+	}
+
+	//catalog เปลี่ยนเมื่อตัด layer ออก
+	gp.skipObjs = merged
+	catalog := gp.pdfObjs[0]
+	catalog.GetObjBuff().Reset()
+	if err := catalog.Build(); err != nil {
+		return nil, err
+	}
+	refs[0] = objRefsOf(catalog.GetObjBuff().Bytes(), max)
+
+	reachable := map[int]bool{0: true}
+	stack := []int{0}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, ref := range refs[index] {
+			if !reachable[ref] && !merged[ref] {
+				reachable[ref] = true
+				stack = append(stack, ref)
 			}
-			i++
 		}
 	}
-
-	if !found {
-		return errors.New("not found font family")
+	for i := 0; i < max; i++ {
+		if !reachable[i] {
+			merged[i] = true
+		}
 	}
+	return merged, nil
+}
 
-	return nil
+//keptObjs : indexes that are not skipped in pdf being built
+func (gp *GoPdf) keptObjs(indexes []int) []int {
+	if len(gp.skipObjs) == 0 {
+		return indexes
+	}
+	var kept []int
+	for _, index := range indexes {
+		if !gp.skipObjs[index] {
+			kept = append(kept, index)
+		}
+	}
+	return kept
 }
 
-//WritePdf : wirte pdf file
-func (gp *GoPdf) WritePdf(pdfPath string) {
-	ioutil.WriteFile(pdfPath, gp.GetBytesPdf(), 0644)
+//buildSkips : add objs that are never written (see measureObjSkips , formObjSkips , simpleFontSkips , procSetObjSkips
+//and mergedContentSkips) to skips
+func (gp *GoPdf) buildSkips(skips map[int]bool) map[int]bool {
+	return gp.mergedContentSkips(gp.procSetObjSkips(gp.simpleFontSkips(gp.formObjSkips(gp.measureObjSkips(skips)))))
 }
 
-//GetBytesPdfReturnErr : get bytes of pdf file
-func (gp *GoPdf) GetBytesPdfReturnErr() ([]byte, error) {
-	gp.prepare()
+//compile : build all obj (except skips) into pdf file , stop if ctx is done
+func (gp *GoPdf) compile(ctx context.Context, skips map[int]bool) ([]byte, error) {
 	buff := new(bytes.Buffer)
 	i := 0
 	max := len(gp.pdfObjs)
 	buff.WriteString("%PDF-1.7\n\n")
 	linelens := make([]int, max)
+	var infos []PdfObjectInfo
+	skips = gp.buildSkips(skips)
+	err := gp.checkGraphicsState(skips)
+	if err != nil {
+		return nil, err
+	}
+	gp.skipObjs = skips
+	defer func() {
+		gp.skipObjs = nil
+	}()
 	for i < max {
+		if skips[i] {
+			linelens[i] = -1 //free obj
+			i++
+			continue
+		}
 		linelens[i] = buff.Len()
 		pdfObj := gp.pdfObjs[i]
+		if pdfObj.GetType() == "Page" {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		pdfObj.GetObjBuff().Reset()
 		err := pdfObj.Build()
 		if err != nil {
 			return nil, err
@@ -260,9 +1189,12 @@ func (gp *GoPdf) GetBytesPdfReturnErr() ([]byte, error) {
 		buffbyte := pdfObj.GetObjBuff().Bytes()
 		buff.Write(buffbyte)
 		buff.WriteString("endobj\n\n")
+		infos = append(infos, gp.objectInfo(i, i+1, buffbyte, buff.Len()-linelens[i]-2))
 		i++
 	}
 	gp.xref(linelens, buff, &i)
+	gp.xrefOffsets = linelens
+	gp.objectInfos = infos
 	return buff.Bytes(), nil
 }
 
@@ -275,43 +1207,366 @@ func (gp *GoPdf) GetBytesPdf() []byte {
 	return b
 }
 
-//Cell : create cell of text
-//Note that this has no effect on Rect.H pdf (now). Fix later :-)
+//Cell : create cell of text ,
+//text is placed vertically in rectangle (top at current y) by SetCellVerticalAlign (default "middle" center cap-height of text in cell),
+//if rectangle is nil or Rect.H is 0 the height of cell is the line height of font
 func (gp *GoPdf) Cell(rectangle *Rect, text string) {
+	gp.applyDefaultFont()
 
 	//undelineOffset := ContentObj_CalTextHeight(gp.Curr.Font_Size) + 1
 	startX := gp.Curr.X
-	startY := gp.Curr.Y
+	baseline := gp.cellBaseline(rectangle)
 	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_IFONT {
-		gp.getContent().AppendStream(rectangle, text)
+		gp.getContent().AppendStream(rectangle, gp.currEncodingObj().EncodeText(text))
 	} else if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
 		gp.Curr.Font_ISubset.AddChars(text)
 		gp.getContent().AppendStreamSubsetFont(rectangle, text)
 	}
 	endX := gp.Curr.X
 	endY := gp.Curr.Y
+	if gp.measure != nil {
+		_, ascender, descender := gp.currFontMetrics()
+		h := ascender - descender
+		if rectangle != nil && rectangle.H > 0 {
+			h = rectangle.H
+		}
+		gp.measureArea(startX, endY, endX-startX, h)
+	}
 
 	//underline
 	if strings.Contains(strings.ToUpper(gp.Curr.Font_Style), "U") {
 		//gp.Line(x1,y1+undelineOffset,x2,y2+undelineOffset)
-		gp.getContent().AppendUnderline(startX, startY, endX, endY, text)
+		gp.getContent().AppendUnderline(startX, baseline, endX, endY, text)
+	}
+
+}
+
+//CellWithOption : create cell of text (same as Cell) and draw its borders ,
+//the borders are drawn with opt.BorderDash or the current dash pattern
+func (gp *GoPdf) CellWithOption(rectangle *Rect, text string, opt CellOption) {
+	x := gp.Curr.X
+	y := gp.Curr.Y
+	_, ascender, descender := gp.currFontMetrics()
+	h := ascender - descender
+	if rectangle != nil && rectangle.H > 0 {
+		h = rectangle.H
+	}
+	gp.Cell(rectangle, text)
+	gp.getContent().AppendStreamBorder(x, y, gp.Curr.X-x, h, opt.Border, opt.BorderDash, opt.BorderDashPhase)
+}
+
+//MultiCell : draw text wrapped into lines of width w start at current position ,
+//h is the line height (0 = compute from ascender , descender and line gap of font times SetLeadingFactor) ,
+//text is one paragraph or paragraphs separated by "\n\n" when SetParagraphSpacing is set
+func (gp *GoPdf) MultiCell(w float64, h float64, text string) error {
+	if h <= 0 {
+		h = gp.autoLineHeight()
+	}
+	paragraphs := []string{text}
+	if gp.paragraphSpaceBefore != 0 || gp.paragraphSpaceAfter != 0 {
+		paragraphs = strings.Split(text, "\n\n")
+	}
+	startX := gp.Curr.X
+	for _, paragraph := range paragraphs {
+		gp.Curr.Y += gp.paragraphSpaceBefore
+		startY := gp.Curr.Y
+		lines, err := gp.splitTextToLinesFunc(paragraph, func(i int) float64 {
+			_, lineW := gp.floatLine(startX, startY+float64(i)*h, w, h)
+			return lineW
+		})
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			x, lineW := gp.floatLine(startX, gp.Curr.Y, w, h)
+			gp.Curr.X = x
+			gp.Cell(&Rect{W: lineW, H: h}, line)
+			gp.Curr.X = startX
+			gp.Curr.Y += h
+		}
+		gp.Curr.Y += gp.paragraphSpaceAfter
+	}
+	return nil
+}
+
+//SetParagraphSpacing : space above and below each paragraph of MultiCell (each call and each part of text separated by "\n\n") ,
+//space between two paragraphs is after + before , not depend on line height
+func (gp *GoPdf) SetParagraphSpacing(before float64, after float64) {
+	gp.paragraphSpaceBefore = before
+	gp.paragraphSpaceAfter = after
+}
+
+//DrawLeader : fill space from x0 to x1 with leader char (0 = '.') in current font (sample dots between title and page number of TOC) ,
+//y is the top of line (same as Cell) , gap of one space is left at both ends and leaders end at the same x for the same x1 ,
+//current x is moved to x1
+func (gp *GoPdf) DrawLeader(x0 float64, x1 float64, y float64, char rune) error {
+	if char == 0 {
+		char = '.'
+	}
+	charW, err := gp.MeasureTextWidth(string(char))
+	if err != nil {
+		return err
+	}
+	gap, err := gp.MeasureTextWidth(" ")
+	if err != nil {
+		return err
+	}
+	n := 0
+	if charW > 0 {
+		n = int((x1 - x0 - 2*gap) / charW)
+	}
+	if n > 0 {
+		leader := strings.Repeat(string(char), n)
+		gp.Curr.X = x1 - gap - float64(n)*charW
+		gp.Curr.Y = y
+		gp.Cell(nil, leader)
+	}
+	gp.Curr.X = x1
+	gp.Curr.Y = y
+	return nil
+}
+
+//WrapText : lines of text wrapped to width (same as MultiCell) and width of each line in current font , nothing is drawn ,
+//error if font is not set
+func (gp *GoPdf) WrapText(text string, width float64) ([]string, []float64, error) {
+	lines, err := gp.splitTextToLines(text, width)
+	if err != nil {
+		return nil, nil, err
+	}
+	widths := make([]float64, len(lines))
+	for i, line := range lines {
+		widths[i], err = gp.MeasureTextWidth(line)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return lines, widths, nil
+}
+
+//SetLeadingFactor : factor of line height computed from font metrics (MultiCell with h = 0) , default 1
+func (gp *GoPdf) SetLeadingFactor(factor float64) {
+	gp.leadingFactor = factor
+}
+
+//SetSpaceWidthFactor : scale advance of space (and no-break space) in measuring , wrapping and drawing text , default 1
+func (gp *GoPdf) SetSpaceWidthFactor(factor float64) {
+	gp.spaceWidthFactor = factor
+}
+
+//MeasureTextWidth : width of text in current font and font size (widths of recent texts are cached , see SetMeasureCacheSize)
+func (gp *GoPdf) MeasureTextWidth(text string) (float64, error) {
+	gp.applyDefaultFont()
+	key := measureKey{size: gp.Curr.Font_Size, spaceFactor: gp.currSpaceWidthFactor(), text: text}
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET && gp.Curr.Font_ISubset != nil {
+		key.font = gp.Curr.Font_ISubset
+	} else if gp.Curr.Font_IFont != nil {
+		key.font = gp.Curr.Font_IFont
+	}
+	if width, ok := gp.cachedTextWidth(key); ok {
+		return width, nil
+	}
+	width, err := gp.measureTextWidth(text)
+	if err == nil {
+		gp.cacheTextWidth(key, width)
+	}
+	return width, err
+}
+
+//measureTextWidth : width of text in current font (not cached)
+func (gp *GoPdf) measureTextWidth(text string) (float64, error) {
+	fontSize := float64(gp.Curr.Font_Size)
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET && gp.Curr.Font_ISubset != nil {
+		sumWidth := 0.0
+		for _, glyph := range gp.shapeCurrText(text) {
+			width := float64(glyph.width)
+			if len(glyph.runes) == 1 && isSpaceRune(glyph.runes[0]) {
+				width *= gp.currSpaceWidthFactor()
+			}
+			sumWidth += width
+		}
+		return sumWidth * fontSize / 1000.0, nil
+	} else if gp.Curr.Font_IFont != nil {
+		code := gp.currEncodingObj().EncodeText(text)
+		return StrHelperGetStringWidth(code, gp.Curr.Font_Size, gp.Curr.Font_IFont) + float64(strings.Count(code, " "))*gp.wordSpacing(), nil
+	}
+	return 0, ErrFontNotSet
+}
+
+//TextBounds : width (MeasureTextWidth) , ascent and descent (ascender and descender of font , descent is positive) of text in current font ,
+//fast but box is higher than ink of most text , use TextBoundsTight for ink extents
+func (gp *GoPdf) TextBounds(text string) (float64, float64, float64, error) {
+	w, err := gp.MeasureTextWidth(text)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	_, ascender, descender := gp.currFontMetrics()
+	return w, ascender, -descender, nil
+}
+
+//TextBoundsTight : width from left to right of ink , ascent above and descent below baseline (positive) of ink of text in current font
+//from bounding box of each glyph , fonts that are not ttf (AddTTFFont) use TextBounds
+func (gp *GoPdf) TextBoundsTight(text string) (float64, float64, float64, error) {
+	sub, ok := gp.Curr.Font_ISubset.(*SubsetFontObj)
+	if gp.Curr.Font_Type != CURRENT_FONT_TYPE_SUBSET || !ok {
+		return gp.TextBounds(text)
+	}
+	ttfp := sub.GetTTFParser()
+	scale := float64(gp.Curr.Font_Size) / 1000.0
+	found := false
+	var left, right, ascent, descent, x float64
+	for _, glyph := range gp.shapeCurrText(text) {
+		if bounds, ok := ttfp.GlyphBounds(glyph.glyphIndex); ok {
+			glyphLeft := x + sub.scaleToPDF(bounds.XMin)*scale
+			glyphRight := x + sub.scaleToPDF(bounds.XMax)*scale
+			if !found || glyphLeft < left {
+				left = glyphLeft
+			}
+			if !found || glyphRight > right {
+				right = glyphRight
+			}
+			ascent = math.Max(ascent, sub.scaleToPDF(bounds.YMax)*scale)
+			descent = math.Max(descent, -sub.scaleToPDF(bounds.YMin)*scale)
+			found = true
+		}
+		width := float64(glyph.width)
+		if len(glyph.runes) == 1 && isSpaceRune(glyph.runes[0]) {
+			width *= gp.currSpaceWidthFactor()
+		}
+		x += width * scale
+	}
+	return right - left, ascent, descent, nil
+}
+
+//RawContent : append pdf operators to content stream of current page as is ,
+//nothing is escaped or checked , the caller must keep the page valid (balance q/Q and BT/ET , use pdf coordinate)
+//and register every resource that ops use by AddResource
+func (gp *GoPdf) RawContent(ops string) {
+	gp.getContent().AppendStreamRaw(ops)
+}
+
+//AddResource : add obj to pdf and register it in resources as /kind << /name obj >> (etc. kind = "ExtGState" , name = "GS1")
+func (gp *GoPdf) AddResource(kind string, name string, obj IObj) {
+	obj.Init(func() *GoPdf {
+		return gp
+	})
+	index := gp.addObj(obj)
+	if gp.indexOfProcSet != -1 {
+		procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+		procset.RealteResources = append(procset.RealteResources, RealteResource{Kind: kind, Name: name, IndexOfObj: index})
+	}
+}
+
+//TextOnCircle : draw text along a circle centered at cx,cy ,
+//startAngle is the position of the first glyph in degrees (counter-clockwise from 3 o'clock)
+func (gp *GoPdf) TextOnCircle(cx float64, cy float64, radius float64, text string, startAngle float64, opts ...TextOnCircleOption) error {
+
+	var opt TextOnCircleOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
+		gp.Curr.Font_ISubset.AddChars(text)
 	}
 
+	//รัศมีของ baseline
+	textH := ContentObj_CalTextHeight(gp.Curr.Font_Size)
+	r := radius
+	if !opt.CounterClockwise && opt.Inside {
+		r = radius - textH
+	} else if opt.CounterClockwise && !opt.Inside {
+		r = radius + textH
+	}
+	if r <= 0 {
+		return ErrTextOnCircleRadius
+	}
+
+	pageH := gp.config.PageSize.H
+	theta := startAngle * math.Pi / 180.0
+	for _, c := range text {
+		glyph, width, err := gp.glyphOf(c)
+		if err != nil {
+			return err
+		}
+		x := cx + r*math.Cos(theta)
+		y := pageH - cy + r*math.Sin(theta)
+		if opt.CounterClockwise {
+			gp.getContent().AppendStreamRotatedText(x, y, theta+math.Pi/2, glyph)
+			theta += width / r
+		} else {
+			gp.getContent().AppendStreamRotatedText(x, y, theta-math.Pi/2, glyph)
+			theta -= width / r
+		}
+	}
+	return nil
 }
 
 //AddTTFFont : font use subtype font
 func (gp *GoPdf) AddTTFFont(family string, ttfpath string) error {
+	return gp.AddTTFFontWithOption(family, ttfpath, FontOption{})
+}
+
+//AddTTFFontWithOption : same as AddTTFFont , option override values of font descriptor ,
+//ErrFontOptionConflict if same family and ttfpath were added before with other option
+func (gp *GoPdf) AddTTFFontWithOption(family string, ttfpath string, option FontOption) error {
 
 	if _, err := os.Stat(ttfpath); os.IsNotExist(err) {
 		return err
 	}
 
+	//font เดิมใช้ได้ทุกหน้า ไม่ต้อง embed ซ้ำ
+	if sub := gp.findSubsetFont(family); sub != nil && sub.GetTTFPath() == ttfpath {
+		if !sub.fontOption.equal(option) {
+			return ErrFontOptionConflict
+		}
+		return nil
+	}
+	return gp.addTTFFont(family, option, func(subsetFont *SubsetFontObj) error {
+		return subsetFont.SetTTFByPath(ttfpath)
+	})
+}
+
+//AddTTFFontByReader : same as AddTTFFont , read ttf from r and use it by alias (sample "body" , "heading") in SetFont ,
+//font with same data as font added before is not embedded again (alias is added to that font)
+func (gp *GoPdf) AddTTFFontByReader(alias string, r io.Reader) error {
+	fontData, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, obj := range gp.pdfObjs {
+		if sub, ok := obj.(*SubsetFontObj); ok && bytes.Equal(sub.GetTTFParser().FontData(), fontData) {
+			if !sub.hasFamily(alias) {
+				sub.addAlias(alias)
+			}
+			return nil
+		}
+	}
+	return gp.addTTFFont(alias, FontOption{}, func(subsetFont *SubsetFontObj) error {
+		return subsetFont.SetTTFByReader(bytes.NewReader(fontData))
+	})
+}
+
+//AddTTFFontFS : same as AddTTFFontByReader , read ttf at path of fsys (sample embed.FS) and use it by name in SetFont
+func (gp *GoPdf) AddTTFFontFS(name string, fsys fs.FS, path string) error {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gp.AddTTFFontByReader(name, f)
+}
+
+//addTTFFont : add objs of subset font , load read ttf into subsetFont
+func (gp *GoPdf) addTTFFont(family string, option FontOption, load func(subsetFont *SubsetFontObj) error) error {
 	subsetFont := new(SubsetFontObj)
 	subsetFont.Init(func() *GoPdf {
 		return gp
 	})
 	subsetFont.SetFamily(family)
-	err := subsetFont.SetTTFByPath(ttfpath)
+	subsetFont.SetSubsetOption(option.Subset)
+	subsetFont.SetPreferSimple(option.PreferSimple)
+	subsetFont.fontOption = option
+	err := load(subsetFont)
 	if err != nil {
 		return err
 	}
@@ -323,12 +1578,17 @@ func (gp *GoPdf) AddTTFFont(family string, ttfpath string) error {
 	unicodemap.SetPtrToSubsetFontObj(subsetFont)
 	unicodeindex := gp.addObj(unicodemap)
 
-	pdfdic := new(PdfDictionaryObj)
-	pdfdic.Init(func() *GoPdf {
-		return gp
-	})
-	pdfdic.SetPtrToSubsetFontObj(subsetFont)
-	pdfdicindex := gp.addObj(pdfdic)
+	pdfdicindex := -1
+	if option.embed() {
+		pdfdic := new(PdfDictionaryObj)
+		pdfdic.Init(func() *GoPdf {
+			return gp
+		})
+		pdfdic.SetPtrToSubsetFontObj(subsetFont)
+		pdfdicindex = gp.addObj(pdfdic)
+	} else {
+		subsetFont.SetEmbed(false)
+	}
 
 	subfontdesc := new(SubfontDescriptorObj)
 	subfontdesc.Init(func() *GoPdf {
@@ -336,6 +1596,7 @@ func (gp *GoPdf) AddTTFFont(family string, ttfpath string) error {
 	})
 	subfontdesc.SetPtrToSubsetFontObj(subsetFont)
 	subfontdesc.SetIndexObjPdfDictionary(pdfdicindex)
+	subfontdesc.SetFontOption(option)
 	subfontdescindex := gp.addObj(subfontdesc)
 
 	cidfont := new(CIDFontObj)
@@ -347,6 +1608,7 @@ func (gp *GoPdf) AddTTFFont(family string, ttfpath string) error {
 	cidindex := gp.addObj(cidfont)
 
 	subsetFont.SetIndexObjCIDFont(cidindex)
+	subsetFont.SetIndexObjFontDescriptor(subfontdescindex)
 	subsetFont.SetIndexObjUnicodeMap(unicodeindex)
 	index := gp.addObj(subsetFont) //add หลังสุด
 
@@ -361,8 +1623,32 @@ func (gp *GoPdf) AddTTFFont(family string, ttfpath string) error {
 	return nil
 }
 
+//UsedGlyphs : sorted glyph indexes drawn so far with ttf font family (AddTTFFont) , nil if family is not found
+func (gp *GoPdf) UsedGlyphs(family string) []uint64 {
+	sub := gp.findSubsetFont(family)
+	if sub == nil {
+		return nil
+	}
+	return sub.UsedGlyphs()
+}
+
 //AddFont : user embed font in zfont file ( deprecated remove sogp day )
 func (gp *GoPdf) AddFont(family string, ifont IFont, zfontpath string) {
+	gp.AddFontWithOption(family, ifont, zfontpath, FontOption{})
+}
+
+//AddFontWithOption : same as AddFont , option override values of GetDesc
+func (gp *GoPdf) AddFontWithOption(family string, ifont IFont, zfontpath string, option FontOption) {
+	embedfont := new(EmbedFontObj)
+	embedfont.Init(func() *GoPdf {
+		return gp
+	})
+	embedfont.SetFont(ifont, zfontpath)
+	gp.addSimpleFont(family, ifont, embedfont, option)
+}
+
+//addSimpleFont : add objs of simple font (encoding , widths , descriptor , embedfont and font in this order)
+func (gp *GoPdf) addSimpleFont(family string, ifont IFont, embedfont *EmbedFontObj, option FontOption) {
 	encoding := new(EncodingObj)
 	ifont.Init()
 	ifont.SetFamily(family)
@@ -381,16 +1667,13 @@ func (gp *GoPdf) AddFont(family string, ifont IFont, zfontpath string) {
 		return gp
 	})
 	fontDesc.SetFont(ifont)
+	fontDesc.SetFontOption(option)
 	gp.addObj(fontDesc) //2
 
-	embedfont := new(EmbedFontObj)
-	embedfont.Init(func() *GoPdf {
-		return gp
-	})
-	embedfont.SetFont(ifont, zfontpath)
-	index := gp.addObj(embedfont) //3
-
-	fontDesc.SetFontFileObjRelate(strconv.Itoa(index+1) + " 0 R")
+	if option.embed() {
+		index := gp.addObj(embedfont) //3
+		fontDesc.SetFontFileObjRelate(strconv.Itoa(index+1) + " 0 R")
+	}
 
 	//start add font obj
 	font := new(FontObj)
@@ -399,7 +1682,7 @@ func (gp *GoPdf) AddFont(family string, ifont IFont, zfontpath string) {
 	})
 	font.Family = family
 	font.Font = ifont
-	index = gp.addObj(font) //4
+	index := gp.addObj(font) //4
 	if gp.indexOfProcSet != -1 {
 		procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
 		if !procset.Realtes.IsContainsFamily(family) {
@@ -427,17 +1710,265 @@ func (gp *GoPdf) init() {
 	gp.Curr.CountOfL = 0
 	gp.Curr.CountOfImg = 0 //img
 	gp.Curr.ImgCaches = *new([]ImageCache)
+	gp.imageKeys = nil
+	gp.measureDrops = nil
+	gp.measureCacheSize = defaultMeasureCacheSize
+	gp.ClearMeasureCache()
 
 	//init index
 	gp.indexOfPagesObj = -1
 	gp.indexOfFirstPageObj = -1
 	gp.indexOfContent = -1
+	gp.indexOfOutlinesObj = -1
+	gp.indexOfGuideOCG = -1
 
 	//No underline
 	//gp.IsUnderline = false
 
 }
 
+//SetGlyphUnicodeMap : unicode of glyphs (glyph index -> rune) written to ToUnicode of ttf font family instead of unicode from cmap ,
+//for fonts with wrong cmap (copy and paste of text in viewer get the right characters)
+func (gp *GoPdf) SetGlyphUnicodeMap(family string, m map[uint64]rune) error {
+	sub := gp.findSubsetFont(family)
+	if sub == nil {
+		return ErrFontFamilyNotFound
+	}
+	sub.SetGlyphUnicodeMap(m)
+	return nil
+}
+
+//findSubsetFont : first SubsetFontObj of family (nil if not found)
+func (gp *GoPdf) findSubsetFont(family string) *SubsetFontObj {
+	i := 0
+	max := len(gp.pdfObjs)
+	for i < max {
+		if gp.pdfObjs[i].GetType() == "SubsetFont" {
+			sub, ok := gp.pdfObjs[i].(*SubsetFontObj)
+			if ok && sub.hasFamily(family) {
+				return sub
+			}
+		}
+		i++
+	}
+	return nil
+}
+
+//autoLineHeight : line height from metrics of current font (typo metrics of OS/2 if font set USE_TYPO_METRICS)
+func (gp *GoPdf) autoLineHeight() float64 {
+	_, ascender, descender := gp.currFontMetrics()
+	lineGap := 0.0
+	if sub, ok := gp.Curr.Font_ISubset.(*SubsetFontObj); ok && gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
+		ttfp := sub.GetTTFParser()
+		scale := float64(gp.Curr.Font_Size) / 1000.0
+		lineGap = sub.scaleToPDF(ttfp.LineGap()) * scale
+		if ttfp.UseTypoMetrics() {
+			//font บอกให้ใช้ typo metrics (fsSelection bit 7)
+			ascender = sub.scaleToPDF(ttfp.TypoAscender()) * scale
+			descender = sub.scaleToPDF(ttfp.TypoDescender()) * scale
+			lineGap = sub.scaleToPDF(ttfp.TypoLineGap()) * scale
+		}
+	}
+	factor := gp.leadingFactor
+	if factor <= 0 {
+		factor = 1
+	}
+	return (ascender - descender + lineGap) * factor
+}
+
+//splitTextToLines : wrap text into lines that fit width (break at space , at break point of SetHyphenation with hyphen ,
+//or at any char if a word is too long)
+func (gp *GoPdf) splitTextToLines(text string, width float64) ([]string, error) {
+	return gp.splitTextToLinesFunc(text, func(int) float64 {
+		return width
+	})
+}
+
+//splitTextToLinesFunc : same as splitTextToLines , widthOf return width of line i (start at 0)
+func (gp *GoPdf) splitTextToLinesFunc(text string, widthOf func(i int) float64) ([]string, error) {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := []rune(paragraph)
+		for len(runes) > 0 {
+			width := widthOf(len(lines))
+			lineWidth := 0.0
+			breakAt := -1 //index หลัง space สุดท้ายที่ตัดได้
+			end := len(runes)
+			hyphen := ""
+			for i, r := range runes {
+				w, err := gp.MeasureTextWidth(string(r))
+				if err != nil {
+					return nil, err
+				}
+				if lineWidth+w > width && i > 0 {
+					end = i
+					if breakAt > 0 && r != ' ' {
+						end = breakAt
+					}
+					if gp.hyphenator != nil && r != ' ' {
+						wordStart := 0
+						if breakAt > 0 {
+							wordStart = breakAt
+						}
+						hyphenAt, ok, err := gp.hyphenateLine(runes, wordStart, i, width)
+						if err != nil {
+							return nil, err
+						}
+						if ok {
+							end = hyphenAt
+							hyphen = "-"
+						}
+					}
+					break
+				}
+				lineWidth += w
+				if r == ' ' {
+					breakAt = i + 1
+				}
+			}
+			lines = append(lines, strings.TrimRight(string(runes[:end]), " ")+hyphen)
+			runes = runes[end:]
+			for len(runes) > 0 && runes[0] == ' ' {
+				runes = runes[1:]
+			}
+		}
+		if len(paragraph) == 0 {
+			lines = append(lines, "")
+		}
+	}
+	return lines, nil
+}
+
+//cellBaseline : y of text baseline in cell (from top of page)
+func (gp *GoPdf) cellBaseline(rectangle *Rect) float64 {
+	capHeight, ascender, descender := gp.currFontMetrics()
+	h := ascender - descender
+	if rectangle != nil && rectangle.H > 0 {
+		h = rectangle.H
+	}
+	switch gp.cellVerticalAlign {
+	case "top":
+		return gp.Curr.Y + capHeight
+	case "baseline":
+		return gp.Curr.Y
+	case "bottom":
+		return gp.Curr.Y + h + descender
+	}
+	return gp.Curr.Y + (h+capHeight)/2.0
+}
+
+//currFontMetrics : cap-height , ascender and descender (negative) of current font scaled to font size
+func (gp *GoPdf) currFontMetrics() (float64, float64, float64) {
+	fontSize := float64(gp.Curr.Font_Size)
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
+		if sub, ok := gp.Curr.Font_ISubset.(*SubsetFontObj); ok {
+			ttfp := sub.GetTTFParser()
+			return sub.scaleToPDF(ttfp.CapHeight()) * fontSize / 1000.0,
+				sub.scaleToPDF(ttfp.Ascender()) * fontSize / 1000.0,
+				sub.scaleToPDF(ttfp.Descender()) * fontSize / 1000.0
+		}
+	} else if gp.Curr.Font_IFont != nil {
+		var capHeight, ascender, descender float64
+		for _, desc := range gp.Curr.Font_IFont.GetDesc() {
+			val, err := strconv.ParseFloat(desc.Val, 64)
+			if err != nil {
+				continue
+			}
+			if desc.Key == "CapHeight" {
+				capHeight = val * fontSize / 1000.0
+			} else if desc.Key == "Ascent" {
+				ascender = val * fontSize / 1000.0
+			} else if desc.Key == "Descent" {
+				descender = val * fontSize / 1000.0
+			}
+		}
+		return capHeight, ascender, descender
+	}
+	//ไม่รู้ metrics ใช้ค่าประมาณเดิม
+	textH := ContentObj_CalTextHeight(gp.Curr.Font_Size)
+	return textH, textH, textH - fontSize
+}
+
+//glyphOf : encoded glyph (ready for Tj) and advance width of char in current font
+func (gp *GoPdf) glyphOf(c rune) (string, float64, error) {
+	fontSize := float64(gp.Curr.Font_Size)
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
+		index, err := gp.Curr.Font_ISubset.CharIndex(c)
+		if err != nil {
+			return "", 0, err
+		}
+		width, err := gp.Curr.Font_ISubset.CharWidth(c)
+		if err != nil {
+			return "", 0, err
+		}
+		return fmt.Sprintf("<%04X>", index), float64(width) * fontSize / 1000.0, nil
+	}
+	if gp.Curr.Font_IFont == nil {
+		return "", 0, ErrCharNotFound
+	}
+	code := gp.currEncodingObj().EncodeText(string(c))
+	return "(" + escapePdfString(code) + ")", StrHelperGetStringWidth(code, gp.Curr.Font_Size, gp.Curr.Font_IFont), nil
+}
+
+//currSpaceWidthFactor : factor of space advance (SetSpaceWidthFactor)
+func (gp *GoPdf) currSpaceWidthFactor() float64 {
+	if gp.spaceWidthFactor <= 0 {
+		return 1
+	}
+	return gp.spaceWidthFactor
+}
+
+//wordSpacing : extra advance of space of simple font (Tw) by SetSpaceWidthFactor
+func (gp *GoPdf) wordSpacing() float64 {
+	if gp.Curr.Font_IFont == nil {
+		return 0
+	}
+	factor := gp.currSpaceWidthFactor()
+	return float64(gp.Curr.Font_IFont.GetCw()[' ']) * (factor - 1) * float64(gp.Curr.Font_Size) / 1000.0
+}
+
+//isSpaceRune : space and no-break space (no-break space is never a line break point)
+func isSpaceRune(r rune) bool {
+	return r == ' ' || r == 0xA0
+}
+
+//currEncodingObj : encoding of current simple font
+func (gp *GoPdf) currEncodingObj() *EncodingObj {
+	for _, index := range gp.indexEncodingObjFonts {
+		encoding := gp.pdfObjs[index].(*EncodingObj)
+		if encoding.GetFont() == gp.Curr.Font_IFont {
+			return encoding
+		}
+	}
+	return &EncodingObj{font: gp.Curr.Font_IFont}
+}
+
+//setExtGState : use ExtGState of params (create once per params) on current page
+func (gp *GoPdf) setExtGState(params extGStateParams) {
+	if gp.extGStateNames == nil {
+		gp.extGStateNames = make(map[string]string)
+	}
+	name, ok := gp.extGStateNames[params.key()]
+	if !ok {
+		name = fmt.Sprintf("GS%d", len(gp.extGStateNames)+1)
+		gp.extGStateNames[params.key()] = name
+		gp.AddResource("ExtGState", name, &ExtGStateObj{params: params})
+	}
+	gp.extGState = params
+	gp.getContent().AppendStreamRaw("/" + name + " gs")
+}
+
+func (gp *GoPdf) drawPageTemplate() {
+	if gp.pageTemplate == nil || gp.isDrawPageTemplate {
+		return
+	}
+	gp.isDrawPageTemplate = true
+	gp.pageTemplate()
+	gp.isDrawPageTemplate = false
+	//template ต้องไม่เปลี่ยนตำแหน่งเริ่มต้นของ user
+	gp.resetCurrXY()
+}
+
 func (gp *GoPdf) resetCurrXY() {
 	gp.Curr.X = gp.leftMargin
 	gp.Curr.Y = gp.topMargin
@@ -449,18 +1980,23 @@ func (gp *GoPdf) prepare() {
 		indexCurrPage := -1
 		var pagesObj *PagesObj
 		pagesObj = gp.pdfObjs[gp.indexOfPagesObj].(*PagesObj)
+		pagesObj.Kids = ""
+		pagesObj.PageCount = 0
 		i := 0 //gp.indexOfFirstPageObj
 		max := len(gp.pdfObjs)
 		for i < max {
 			objtype := gp.pdfObjs[i].GetType()
 			//fmt.Printf(" objtype = %s , %d \n", objtype , i)
 			if objtype == "Page" {
+				gp.pdfObjs[i].(*PageObj).Contents = ""
+				gp.pdfObjs[i].(*PageObj).indexOfContents = nil
 				pagesObj.Kids = fmt.Sprintf("%s %d 0 R ", pagesObj.Kids, i+1)
 				pagesObj.PageCount++
 				indexCurrPage = i
 			} else if objtype == "Content" {
 				if indexCurrPage != -1 {
 					gp.pdfObjs[indexCurrPage].(*PageObj).Contents = fmt.Sprintf("%s %d 0 R ", gp.pdfObjs[indexCurrPage].(*PageObj).Contents, i+1)
+					gp.pdfObjs[indexCurrPage].(*PageObj).indexOfContents = append(gp.pdfObjs[indexCurrPage].(*PageObj).indexOfContents, i)
 				}
 			} else if objtype == "Font" {
 				tmpfont := gp.pdfObjs[i].(*FontObj)
@@ -480,18 +2016,90 @@ func (gp *GoPdf) prepare() {
 			}
 			i++
 		}
+		gp.indexOfMergedContents = nil
+		for _, obj := range gp.pdfObjs {
+			if page, ok := obj.(*PageObj); ok {
+				gp.arrangePageContents(page)
+			}
+		}
+	}
+}
+
+//arrangePageContents : /Contents of page that has more than one content stream , array of streams (perPage mode)
+//or streams merged into the first stream (single mode)
+func (gp *GoPdf) arrangePageContents(page *PageObj) {
+	if len(page.indexOfContents) < 2 {
+		return
+	}
+	if gp.contentStreamMode == "single" {
+		if gp.indexOfMergedContents == nil {
+			gp.indexOfMergedContents = make(map[int]bool)
+		}
+		first := gp.pdfObjs[page.indexOfContents[0]].(*ContentObj)
+		for _, index := range page.indexOfContents[1:] {
+			content := gp.pdfObjs[index].(*ContentObj)
+			first.stream.Write(content.stream.Bytes())
+			content.stream.Reset()
+			gp.indexOfMergedContents[index] = true
+			first.flattenOps = append(first.flattenOps, content.flattenOps...)
+			content.flattenOps = nil
+		}
+		page.indexOfContents = page.indexOfContents[:1]
+		page.Contents = fmt.Sprintf("%d 0 R", page.indexOfContents[0]+1)
+		return
+	}
+	page.Contents = "[" + page.Contents + "]"
+}
+
+//mergedContentSkips : content streams that are merged into first stream of their page (single mode) are empty and not referred
+func (gp *GoPdf) mergedContentSkips(skips map[int]bool) map[int]bool {
+	if len(gp.indexOfMergedContents) == 0 {
+		return skips
+	}
+	merged := make(map[int]bool)
+	for index := range skips {
+		merged[index] = true
+	}
+	for index := range gp.indexOfMergedContents {
+		merged[index] = true
+	}
+	return merged
+}
+
+//checkGraphicsState : in strict mode , error if q/Q of any page (except skips) is not balanced
+func (gp *GoPdf) checkGraphicsState(skips map[int]bool) error {
+	if !gp.isStrictGraphicsState {
+		return nil
+	}
+	pageNo := 0
+	for i, obj := range gp.pdfObjs {
+		objtype := obj.GetType()
+		if objtype == "Page" {
+			pageNo++
+		} else if objtype == "Content" && !skips[i] {
+			missing, extra := graphicsStateBalance(obj.(*ContentObj).stream.Bytes())
+			if missing > 0 || extra > 0 {
+				return fmt.Errorf("%w on page %d", ErrUnbalancedGraphicsState, pageNo)
+			}
+		}
 	}
+	return nil
 }
 
 func (gp *GoPdf) xref(linelens []int, buff *bytes.Buffer, i *int) {
 	buff.WriteString("xref\n")
 	buff.WriteString("0 " + strconv.Itoa((*i)+1) + "\n")
-	buff.WriteString("0000000000 65535 f\n")
+	//free obj ต่อกันเป็น linked list : แต่ละอันชี้ไปที่ obj number ของ free obj ถัดไป อันสุดท้ายชี้กลับไปที่ 0
+	buff.WriteString(fmt.Sprintf("%010d 65535 f\n", nextFreeObj(linelens, 0)))
 	j := 0
 	max := len(linelens)
 	for j < max {
 		linelen := linelens[j]
-		buff.WriteString(gp.formatXrefline(linelen) + " 00000 n\n")
+		if linelen < 0 {
+			buff.WriteString(fmt.Sprintf("%010d 00001 f\n", nextFreeObj(linelens, j+1)))
+		} else {
+			buff.WriteString(gp.formatXrefline(linelen) + " 00000 n\n")
+		}
 		j++
 	}
 	buff.WriteString("trailer\n")
@@ -502,6 +2110,16 @@ func (gp *GoPdf) xref(linelens []int, buff *bytes.Buffer, i *int) {
 	(*i)++
 }
 
+//nextFreeObj : obj number of first free obj from obj number start+1 , 0 if none
+func nextFreeObj(linelens []int, start int) int {
+	for j := start; j < len(linelens); j++ {
+		if linelens[j] < 0 {
+			return j + 1
+		}
+	}
+	return 0
+}
+
 //ปรับ xref ให้เป็น 10 หลัก
 func (gp *GoPdf) formatXrefline(n int) string {
 	str := strconv.Itoa(n)
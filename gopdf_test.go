@@ -0,0 +1,2660 @@
+package gopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+//testFontPath : extract res/fonts/Loma.z into a temp ttf file
+func testFontPath(t testing.TB) string {
+	return testFontPathOf(t, "Loma")
+}
+
+//testFontPathOf : extract res/fonts/<name>.z into a temp ttf file
+func testFontPathOf(t testing.TB, name string) string {
+	z, err := ioutil.ReadFile("res/fonts/" + name + ".z")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	r, err := zlib.NewReader(bytes.NewReader(z))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), name+".ttf")
+	err = ioutil.WriteFile(path, b, 0644)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return path
+}
+
+//newTestPdf : A4 document with one page and font "loma" size 14 selected
+func newTestPdf(t *testing.T) *GoPdf {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	err := pdf.AddTTFFont("loma", testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	err = pdf.SetFont("loma", "", 14)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return &pdf
+}
+
+func TestTextOnCircle(t *testing.T) {
+	pdf := newTestPdf(t)
+	err := pdf.TextOnCircle(200, 200, 80, "ABC", 135)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	content := pdf.getContent().stream.String()
+	if n := strings.Count(content, " cm\n"); n != 3 {
+		t.Errorf("expect 3 glyph transforms but got %d", n)
+	}
+	if n := strings.Count(content, "> Tj\n"); n != 3 {
+		t.Errorf("expect 3 glyphs but got %d", n)
+	}
+	//first glyph sits at 135 degree on the circle, rotated tangent to it
+	if !strings.Contains(content, "0.7071 0.7071 -0.7071 0.7071 143.43 698.46 cm\n") {
+		t.Errorf("unexpected transform of first glyph\n%s", content)
+	}
+
+	err = pdf.TextOnCircle(200, 200, 5, "ABC", 0, TextOnCircleOption{Inside: true})
+	if err != ErrTextOnCircleRadius {
+		t.Errorf("expect ErrTextOnCircleRadius")
+	}
+}
+
+func TestSetPageTemplate(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.SetPageTemplate(func() {
+		pdf.Line(10, 10, 585.28, 10)
+		pdf.Line(585.28, 10, 585.28, 831.89)
+		pdf.Line(585.28, 831.89, 10, 831.89)
+		pdf.Line(10, 831.89, 10, 10)
+	})
+	border := "10.00 831.89 m 585.28 831.89 l s\n"
+	for i := 0; i < 3; i++ {
+		pdf.AddPage()
+		if !strings.HasPrefix(pdf.getContent().stream.String(), border) {
+			t.Errorf("page %d not start with template border", i+1)
+		}
+		pdf.Line(20, 20, 30, 30)
+	}
+	pdf.AddPageNoTemplate()
+	if strings.Contains(pdf.getContent().stream.String(), border) {
+		t.Errorf("template must not be drawn on AddPageNoTemplate")
+	}
+	if n := strings.Count(string(pdf.GetBytesPdf()), border); n != 3 {
+		t.Errorf("expect border 3 times but got %d", n)
+	}
+}
+
+//indexOfObj : index of obj in pdfObjs (-1 if not found)
+func indexOfObj(pdf *GoPdf, obj IObj) int {
+	for i, o := range pdf.pdfObjs {
+		if o == obj {
+			return i
+		}
+	}
+	return -1
+}
+
+//checkXref : every in use xref entry must point to its "n 0 obj"
+func checkXref(t *testing.T, pdf []byte) {
+	s := string(pdf)
+	start := strings.LastIndex(s, "xref\n")
+	if start == -1 {
+		t.Fatalf("xref not found")
+	}
+	lines := strings.Split(s[start:], "\n")
+	var count int
+	fmt.Sscanf(lines[1], "0 %d", &count)
+	for i := 1; i < count; i++ {
+		var offset, gen int
+		var kind string
+		fmt.Sscanf(lines[2+i], "%d %d %s", &offset, &gen, &kind)
+		if kind != "n" {
+			continue
+		}
+		if !strings.HasPrefix(s[offset:], fmt.Sprintf("%d 0 obj\n", i)) {
+			t.Errorf("xref of obj %d point to wrong offset %d", i, offset)
+		}
+	}
+}
+
+//checkFreeList : free entries of xref must be a linked list that start and end at obj 0
+func checkFreeList(t *testing.T, pdf []byte) {
+	s := string(pdf)
+	lines := strings.Split(s[strings.LastIndex(s, "xref\n"):], "\n")
+	var count int
+	fmt.Sscanf(lines[1], "0 %d", &count)
+	next := make(map[int]int)
+	for i := 0; i < count; i++ {
+		var n, gen int
+		var kind string
+		fmt.Sscanf(lines[2+i], "%d %d %s", &n, &gen, &kind)
+		if kind == "f" {
+			next[i] = n
+		}
+	}
+	visited := 0
+	for i := next[0]; i != 0; i = next[i] {
+		if _, ok := next[i]; !ok || visited >= len(next) {
+			t.Fatalf("free entry %d is not in free list", i)
+		}
+		visited++
+	}
+	if visited != len(next)-1 {
+		t.Errorf("expect %d free objs in free list but got %d", len(next)-1, visited)
+	}
+}
+
+func TestExtractPage(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var img bytes.Buffer
+	if err := png.Encode(&img, m); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), "img.png")
+	if err := ioutil.WriteFile(path, img.Bytes(), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	pdf := newTestPdf(t)
+	pdf.Cell(nil, "page1")
+	pdf.Line(1, 1, 2, 2)
+	pdf.Image(path, 10, 10, nil)
+	pdf.AddPage()
+	pdf.Cell(nil, "page2")
+	pdf.Line(3, 3, 4, 4)
+	pdf.AddPage()
+	pdf.Cell(nil, "page3")
+	pdf.Line(5, 5, 6, 6)
+
+	b, err := pdf.ExtractPage(2)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(b)
+	if strings.Count(s, "/Type /Page\n") != 1 || !strings.Contains(s, "/Count 1\n") {
+		t.Errorf("extracted pdf must have a single page")
+	}
+	if !strings.Contains(s, "3.00 838.89 m") || strings.Contains(s, "1.00 840.89 m") || strings.Contains(s, "5.00 836.89 m") {
+		t.Errorf("extracted pdf must contain only content of page 2")
+	}
+	if !strings.Contains(s, "/FontFile2 ") {
+		t.Errorf("extracted pdf must contain the font")
+	}
+	if strings.Contains(s, "/Subtype /Image") {
+		t.Errorf("extracted pdf must not contain objs that page 2 does not use")
+	}
+	checkXref(t, b)
+	checkFreeList(t, b)
+
+	//document itself must stay intact
+	full := string(pdf.GetBytesPdf())
+	if !strings.Contains(full, "/Count 3\n") {
+		t.Errorf("original document must still have 3 pages")
+	}
+	checkXref(t, []byte(full))
+
+	if _, err := pdf.ExtractPage(4); err != ErrPageOutOfRange {
+		t.Errorf("expect ErrPageOutOfRange")
+	}
+}
+
+func TestExtractPageOutlines(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.AddOutline("Cover")
+	pdf.AddPage()
+	pdf.AddOutline("Chapter 1")
+	pdf.AddOutline("Section 1.1")
+	pdf.AddPage()
+	pdf.AddOutline("Chapter 2")
+
+	b, err := pdf.ExtractPage(2)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(b)
+	if strings.Contains(s, "Cover") || strings.Contains(s, "Chapter 2") {
+		t.Errorf("bookmarks of other pages must be removed")
+	}
+	if !strings.Contains(s, "Chapter 1") || !strings.Contains(s, "Section 1.1") || !strings.Contains(s, "/Count 2\n") {
+		t.Errorf("bookmarks of page 2 must be kept")
+	}
+	checkXref(t, b)
+
+	pdf = newTestPdf(t)
+	pdf.AddPage()
+	pdf.AddOutline("Chapter 1")
+	b, err = pdf.ExtractPage(1)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if strings.Contains(string(b), "/Outlines") {
+		t.Errorf("pdf without bookmarks must not have /Outlines")
+	}
+	checkXref(t, b)
+}
+
+//textYs : y of every TD operator in current content
+func textYs(pdf *GoPdf) []float64 {
+	var ys []float64
+	for _, line := range strings.Split(pdf.getContent().stream.String(), "\n") {
+		var x, y float64
+		if n, _ := fmt.Sscanf(line, "%f %f TD", &x, &y); n == 2 {
+			ys = append(ys, y)
+		}
+	}
+	return ys
+}
+
+func TestCellVerticalAlign(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetY(100)
+	pdf.SetCellVerticalAlign("baseline")
+	pdf.SetFont("loma", "", 10)
+	pdf.Cell(&Rect{W: 100, H: 30}, "Small")
+	pdf.SetFont("loma", "", 24)
+	pdf.Cell(&Rect{W: 100, H: 30}, "Big")
+	ys := textYs(pdf)
+	if len(ys) != 2 || ys[0] != ys[1] || ys[0] != 741.89 {
+		t.Errorf("baselines of cells must align at y but got %v", ys)
+	}
+
+	pdf.SetCellVerticalAlign("middle")
+	pdf.Cell(&Rect{W: 100, H: 30}, "Mid")
+	capHeight := 1450.0 * 24 / 2048 //top of glyph H (Loma has no sCapHeight) , 2048 units per em
+	ys = textYs(pdf)
+	if want := 841.89 - (100 + (30+capHeight)/2); fmt.Sprintf("%0.2f", ys[2]) != fmt.Sprintf("%0.2f", want) {
+		t.Errorf("middle expect baseline %0.2f but got %0.2f", want, ys[2])
+	}
+
+	pdf.SetCellVerticalAlign("top")
+	pdf.Cell(&Rect{W: 100, H: 30}, "Top")
+	ys = textYs(pdf)
+	if want := 841.89 - (100 + capHeight); fmt.Sprintf("%0.2f", ys[3]) != fmt.Sprintf("%0.2f", want) {
+		t.Errorf("top expect baseline %0.2f but got %0.2f", want, ys[3])
+	}
+}
+
+func TestMultiCellAutoLineHeight(t *testing.T) {
+	pdf := newTestPdf(t)
+	err := pdf.MultiCell(60, 0, "one two three four five six")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	ttfp := pdf.Curr.Font_ISubset.(*SubsetFontObj).GetTTFParser()
+	want := float64(ttfp.Ascender()-ttfp.Descender()+ttfp.LineGap()) * 14 / float64(ttfp.UnitsPerEm())
+	ys := textYs(pdf)
+	if len(ys) < 2 {
+		t.Fatalf("expect text wrapped into lines but got %d line", len(ys))
+	}
+	for i := 1; i < len(ys); i++ {
+		if fmt.Sprintf("%0.1f", ys[i-1]-ys[i]) != fmt.Sprintf("%0.1f", want) {
+			t.Errorf("expect line height %0.2f but got %0.2f", want, ys[i-1]-ys[i])
+		}
+	}
+	if pdf.GetY() != 10+want*float64(len(ys)) {
+		t.Errorf("y must move down by auto line height")
+	}
+
+	pdf.SetLeadingFactor(1.5)
+	if h := pdf.autoLineHeight(); fmt.Sprintf("%0.4f", h) != fmt.Sprintf("%0.4f", want*1.5) {
+		t.Errorf("expect leading factor applied but got %f", h)
+	}
+}
+
+func TestAddTTFFontOncePerDocument(t *testing.T) {
+	fontPath := testFontPath(t)
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	for i := 0; i < 3; i++ {
+		pdf.AddPage()
+		err := pdf.AddTTFFont("loma", fontPath)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		err = pdf.SetFont("loma", "", 14)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		pdf.Cell(nil, fmt.Sprintf("page %d", i+1))
+	}
+	s := string(pdf.GetBytesPdf())
+	if n := strings.Count(s, "/FontFile2 "); n != 1 {
+		t.Errorf("expect 1 font file but got %d", n)
+	}
+	if n := strings.Count(s, "/Subtype /Type0\n"); n != 1 {
+		t.Errorf("expect 1 font but got %d", n)
+	}
+	font := fmt.Sprintf("/F1 %d 0 R\n", indexOfObj(&pdf, pdf.findSubsetFont("loma"))+1)
+	if n := strings.Count(s, "/Resources <<\n/ProcSet [/PDF /Text /ImageB /ImageC /ImageI]\n/Font <<\n      "+font); n != 3 {
+		t.Errorf("expect every page use the same font but got %d", n)
+	}
+}
+
+func TestRawContent(t *testing.T) {
+	pdf := newTestPdf(t)
+	gs := new(BasicObj)
+	gs.Data = "<< /Type /ExtGState /CA 0.5 /ca 0.5 >>\n"
+	pdf.AddResource("ExtGState", "GS1", gs)
+	pdf.RawContent("q /GS1 gs 10 10 50 50 re f Q")
+
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "q /GS1 gs 10 10 50 50 re f Q\n") {
+		t.Errorf("raw operators not found in stream")
+	}
+	if !strings.Contains(s, fmt.Sprintf("/ExtGState <<\n/GS1 %d 0 R\n>>\n", indexOfObj(pdf, gs)+1)) {
+		t.Errorf("resource not found in resources dictionary")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestUnbalancedGraphicsState(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.AddPage()
+	pdf.SaveGraphicsState()
+	pdf.SaveGraphicsState()
+	pdf.SetGrayFill(0.5)
+	pdf.RestoreGraphicsState() //forget one RestoreGraphicsState
+
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "q\nq\n0.50 g\nQ\nQ\nendstream") {
+		t.Errorf("missing Q must be added")
+	}
+
+	pdf.SetStrictGraphicsState(true)
+	_, err := pdf.GetBytesPdfReturnErr()
+	if !errors.Is(err, ErrUnbalancedGraphicsState) || !strings.Contains(err.Error(), "page 2") {
+		t.Errorf("expect ErrUnbalancedGraphicsState on page 2 but got %v", err)
+	}
+
+	pdf.RestoreGraphicsState()
+	_, err = pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+}
+
+func TestPageResourcesScope(t *testing.T) {
+	pdf := newTestPdf(t)
+	err := pdf.AddTTFFont("sarabun", testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetFont("loma", "", 14)
+	pdf.Cell(nil, "font A")
+	pdf.AddPage()
+	pdf.SetFont("sarabun", "", 14)
+	pdf.Cell(nil, "font B")
+
+	b := pdf.GetBytesPdf()
+	//resource ของทุกหน้าเขียนในหน้า obj รวมจึงไม่ถูกเขียน
+	if bytes.Contains(b, []byte(fmt.Sprintf("\n%d 0 obj\n", pdf.indexOfProcSet+1))) || pdf.XrefOffsets()[pdf.indexOfProcSet] != -1 {
+		t.Errorf("shared resources obj must not be written")
+	}
+	checkXref(t, b)
+	fontA := fmt.Sprintf("/F1 %d 0 R", indexOfObj(pdf, pdf.findSubsetFont("loma"))+1)
+	fontB := fmt.Sprintf("/F2 %d 0 R", indexOfObj(pdf, pdf.findSubsetFont("sarabun"))+1)
+	var pages []string
+	for _, obj := range pdf.pdfObjs {
+		if obj.GetType() == "Page" {
+			pages = append(pages, obj.GetObjBuff().String())
+		}
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expect 2 pages")
+	}
+	if !strings.Contains(pages[0], fontA) || strings.Contains(pages[0], fontB) {
+		t.Errorf("resources of page 1 must contain only font A\n%s", pages[0])
+	}
+	if !strings.Contains(pages[1], fontB) || strings.Contains(pages[1], fontA) {
+		t.Errorf("resources of page 2 must contain only font B\n%s", pages[1])
+	}
+}
+
+func TestDashedCellBorder(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetX(50)
+	pdf.SetY(100)
+	pdf.CellWithOption(&Rect{W: 100, H: 20}, "cut here", CellOption{Border: AllBorders, BorderDash: []float64{3, 2}})
+	pdf.CellWithOption(&Rect{W: 100, H: 20}, "solid", CellOption{Border: Bottom})
+
+	content := pdf.getContent().stream.String()
+	dashed := "q\n[3.00 2.00] 0.00 d\n" +
+		"50.00 741.89 m 50.00 721.89 l\n" +
+		"50.00 741.89 m 150.00 741.89 l\n" +
+		"150.00 741.89 m 150.00 721.89 l\n" +
+		"50.00 721.89 m 150.00 721.89 l\n" +
+		"S\nQ\n"
+	if !strings.Contains(content, dashed) {
+		t.Errorf("dashed border not found\n%s", content)
+	}
+	if !strings.HasSuffix(content, "ET\n150.00 721.89 m 250.00 721.89 l\nS\n") {
+		t.Errorf("border without dash must use current dash pattern\n%s", content)
+	}
+
+	pdf.SetDashPattern([]float64{1}, 0)
+	pdf.Rectangle(10, 10, 20, 20)
+	if !strings.HasSuffix(pdf.getContent().stream.String(), "[1.00] 0.00 d\n10.00 811.89 20.00 20.00 re S\n") {
+		t.Errorf("unexpected dashed rectangle")
+	}
+}
+
+func TestScaleToPDF2048Em(t *testing.T) {
+	pdf := newTestPdf(t)
+	sub := pdf.Curr.Font_ISubset.(*SubsetFontObj)
+	ttfp := sub.GetTTFParser()
+	if ttfp.UnitsPerEm() != 2048 {
+		t.Fatalf("Loma must be 2048 units/em")
+	}
+	glyphIndex := sub.CharCodeToGlyphIndex('W')
+	designWidth := float64(ttfp.Widths()[glyphIndex])
+	expect := uint64(designWidth*1000.0/2048.0 + 0.5)
+	if w := sub.GlyphIndexToPdfWidth(glyphIndex); w != expect {
+		t.Errorf("width of W must be %d but got %d", expect, w)
+	}
+	width, err := pdf.MeasureTextWidth("WW")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if width != float64(2*expect)*14/1000.0 {
+		t.Errorf("unexpected text width %f", width)
+	}
+	if ut := sub.GetUt(); ut != int64(float64(ttfp.UnderlineThickness())*1000.0/2048.0+0.5) {
+		t.Errorf("underline thickness must be scaled to 1000 units/em but got %d", ut)
+	}
+}
+
+func TestSetCropBox(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	pdf.AddPage()
+	err := pdf.SetCropBox(10, 10, 575.28, 821.89)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if pdf.SetCropBox(10, 10, 600, 100) != ErrCropBoxOutOfMediaBox {
+		t.Errorf("expect ErrCropBoxOutOfMediaBox")
+	}
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "  /CropBox [ 0 0 595.28 841.89 ]\n") {
+		t.Errorf("crop box of first page must default to media box")
+	}
+	if !strings.Contains(s, "  /CropBox [ 10.00 10.00 585.28 831.89 ]\n") {
+		t.Errorf("crop box of second page must be inset by 10pt")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestNoBreakSpace(t *testing.T) {
+	pdf := newTestPdf(t)
+	width, err := pdf.MeasureTextWidth("aaaa 5\u00a0k")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	lines, err := pdf.splitTextToLines("aaaa 5\u00a0kg", width)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if len(lines) != 2 || lines[0] != "aaaa" || lines[1] != "5\u00a0kg" {
+		t.Errorf("must not break at no-break space but got %q", lines)
+	}
+
+	space, _ := pdf.MeasureTextWidth(" ")
+	nbsp, _ := pdf.MeasureTextWidth("\u00a0")
+	if space != nbsp || space == 0 {
+		t.Errorf("no-break space must have advance of space (%f , %f)", space, nbsp)
+	}
+	//no-break space ต้อง map กลับเป็น U+00A0 ไม่ใช่ space
+	pdf.Cell(nil, "5\u00a0kg")
+	sub := pdf.Curr.Font_ISubset.(*SubsetFontObj)
+	nbspGlyph := sub.CharCodeToGlyphIndex(0xA0)
+	if nbspGlyph == sub.CharCodeToGlyphIndex(' ') {
+		t.Fatalf("Loma has glyph of no-break space")
+	}
+	if s := string(pdf.GetBytesPdf()); !strings.Contains(s, fmt.Sprintf("<%04X><%04X><00A0>\n", nbspGlyph, nbspGlyph)) {
+		t.Errorf("ToUnicode must map glyph of no-break space to U+00A0")
+	}
+	pdf.SetSpaceWidthFactor(2)
+	wide, _ := pdf.MeasureTextWidth("a a")
+	a, _ := pdf.MeasureTextWidth("a")
+	if math.Abs(wide-(2*a+2*space)) > 0.001 {
+		t.Errorf("space advance must be scaled by factor")
+	}
+	startX := pdf.GetX()
+	pdf.Cell(nil, "a a")
+	if math.Abs(pdf.GetX()-startX-wide) > 0.001 || !strings.Contains(pdf.getContent().stream.String(), "] TJ\n") {
+		t.Errorf("drawn space must be scaled by factor")
+	}
+}
+
+//countdownContext : context that is canceled after Err is called n times
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	c.n--
+	if c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestWriteContext(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	for i := 0; i < 100; i++ {
+		pdf.AddPage()
+		pdf.Line(10, 10, 20, 20)
+	}
+
+	var buff bytes.Buffer
+	ctx := &countdownContext{Context: context.Background(), n: 10}
+	err := pdf.WriteContext(ctx, &buff)
+	if err != context.Canceled {
+		t.Errorf("expect context.Canceled but got %v", err)
+	}
+	if buff.Len() != 0 || ctx.n >= 0 {
+		t.Errorf("build must stop in the middle and write nothing")
+	}
+
+	err = pdf.WriteContext(context.Background(), &buff)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	checkXref(t, buff.Bytes())
+}
+
+func TestSetOverprint(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetOverprint(true, false)
+	pdf.Cell(nil, "black over color")
+	pdf.SetOverprint(false, false)
+	pdf.SetOverprint(true, false)
+	if !strings.Contains(pdf.getContent().stream.String(), "/GS1 gs\n") || strings.Contains(pdf.getContent().stream.String(), "/GS3") {
+		t.Errorf("ExtGState of same parameters must be reused")
+	}
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "/Type /ExtGState\n/OP false\n/op true\n/OPM 1\n") {
+		t.Errorf("ExtGState with fill overprint not found")
+	}
+	if !strings.Contains(s, "/ExtGState <<\n/GS1 ") {
+		t.Errorf("ExtGState not in page resources")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestSubsetFontName(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.Cell(nil, "subset")
+	s := string(pdf.GetBytesPdf())
+	//tag ต่อหน้า PostScript name ไม่ใช่ชื่อ family ที่ตั้งเอง (loma)
+	baseFont := regexp.MustCompile(`/BaseFont /([A-Z]{6})\+Loma\n`).FindAllStringSubmatch(s, -1)
+	fontName := regexp.MustCompile(`/FontName /([A-Z]{6})\+Loma\n`).FindStringSubmatch(s)
+	if len(baseFont) != 2 || fontName == nil {
+		t.Fatalf("subset tag not found")
+	}
+	if baseFont[0][1] != fontName[1] || baseFont[1][1] != fontName[1] {
+		t.Errorf("subset tag of /BaseFont and /FontName must be the same")
+	}
+	if !strings.Contains(string(pdf.GetBytesPdf()), fontName[0]) {
+		t.Errorf("subset tag must be deterministic")
+	}
+	pdf.Cell(nil, "xyz")
+	if strings.Contains(string(pdf.GetBytesPdf()), fontName[0]) {
+		t.Errorf("subset tag must change with glyphs")
+	}
+}
+
+func TestImageTiled(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	texture := testImagePath(t, 4, 4, color.RGBA{G: 255, A: 255})
+	if err := pdf.ImageTiled(texture, 0, 0, 595.28, 841.89, 10, 10); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.ImageTiled(texture, 10, 10, 100, 100, 20, 20); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Image(texture, 10, 10, nil)
+	if err := pdf.ImageTiled(texture, 10, 10, 100, 100, 0, 20); err != ErrInvalidTileSize {
+		t.Errorf("expect ErrInvalidTileSize but got %v", err)
+	}
+	if err := pdf.ImageTiled(filepath.Join(t.TempDir(), "missing.png"), 10, 10, 100, 100, 20, 20); err == nil {
+		t.Errorf("expect error of missing image")
+	}
+
+	s := string(pdf.GetBytesPdf())
+	if n := strings.Count(s, "/Subtype /Image\n"); n != 1 {
+		t.Errorf("image must be embedded once but found %d", n)
+	}
+	if n := strings.Count(s, "/PatternType 1\n"); n != 2 {
+		t.Errorf("expect 2 tiling patterns but found %d", n)
+	}
+	if !strings.Contains(s, "q /Pattern cs /P1 scn 0.00 0.00 595.28 841.89 re f Q\n") {
+		t.Errorf("rect not filled with pattern")
+	}
+	if !strings.Contains(s, "/Pattern <<\n/P1 ") || !strings.Contains(s, "/XStep 20.00\n/YStep 20.00\n") {
+		t.Errorf("pattern not in resources")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestWrapText(t *testing.T) {
+	pdf := newTestPdf(t)
+	word, _ := pdf.MeasureTextWidth("word")
+	space, _ := pdf.MeasureTextWidth(" ")
+	lines, widths, err := pdf.WrapText("word word word word word", 2*word+space+1)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if len(lines) != 3 || len(widths) != 3 {
+		t.Fatalf("expect 3 lines but got %q", lines)
+	}
+	if lines[0] != "word word" || lines[2] != "word" {
+		t.Errorf("unexpected lines %q", lines)
+	}
+	if math.Abs(widths[0]-(2*word+space)) > 0.001 || math.Abs(widths[2]-word) > 0.001 {
+		t.Errorf("unexpected widths %v", widths)
+	}
+	if strings.Contains(pdf.getContent().stream.String(), "Tj") {
+		t.Errorf("WrapText must not draw")
+	}
+
+	noFont := GoPdf{}
+	noFont.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	noFont.AddPage()
+	if _, _, err := noFont.WrapText("word", 100); err == nil {
+		t.Errorf("expect error when font is not set")
+	}
+}
+
+func TestSetMinCompressSize(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.SetCompressLevel(zlib.BestCompression)
+	pdf.SetMinCompressSize(100)
+	pdf.AddPage()
+	pdf.Line(1, 1, 2, 2)
+	pdf.AddPage()
+	for i := 0; i < 100; i++ {
+		pdf.Line(10, 10, 20, 20)
+	}
+
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "stream\n1.00 840.89 m 2.00 839.89 l s\nendstream\n") {
+		t.Errorf("tiny stream must stay uncompressed")
+	}
+	if n := strings.Count(s, "/Filter /FlateDecode\n>>\nstream\n"); n != 1 || strings.Contains(s, "10.00 831.89 m") {
+		t.Errorf("large stream must be compressed")
+	}
+	checkXref(t, []byte(s))
+
+	pdf.SetMinCompressSize(0)
+	pdf.AddPage()
+	pdf.Line(1, 1, 2, 2)
+	if !strings.Contains(string(pdf.GetBytesPdf()), "stream\n1.00 840.89 m 2.00 839.89 l s\nendstream\n") {
+		t.Errorf("stream must stay uncompressed when compressed data is not smaller")
+	}
+}
+
+func TestSetPageCompression(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.SetCompressLevel(9)
+	pdf.AddPage()
+	for i := 0; i < 100; i++ {
+		pdf.Line(10, 10, 20, 20)
+	}
+	pdf.AddPage()
+	pdf.SetPageCompression(false)
+	for i := 0; i < 100; i++ {
+		pdf.Line(30, 30, 40, 40)
+	}
+	s := string(pdf.GetBytesPdf())
+	if strings.Count(s, "/Filter /FlateDecode\n") != 1 || strings.Contains(s, "10.00 831.89 m") {
+		t.Errorf("first page must be compressed")
+	}
+	if !strings.Contains(s, ">>\nstream\n30.00 811.89 m") {
+		t.Errorf("second page must not be compressed")
+	}
+
+	pdf.SetCompressLevel(0)
+	pdf.SetPageCompression(true)
+	s = string(pdf.GetBytesPdf())
+	if strings.Count(s, "/Filter /FlateDecode\n") != 1 || strings.Contains(s, "30.00 811.89 m") || !strings.Contains(s, "10.00 831.89 m") {
+		t.Errorf("only second page must be compressed")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestRichTextColors(t *testing.T) {
+	pdf := newTestPdf(t)
+	startX := pdf.GetX()
+	err := pdf.RichText([]RichSegment{
+		{Text: "func", FillColor: &RichColor{R: 0, G: 0, B: 255}},
+		{Text: " main()", FillColor: &RichColor{R: 255, G: 0, B: 0}, Size: 10},
+	})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if pdf.Curr.Font_Size != 14 {
+		t.Errorf("font size must be restored after rich text")
+	}
+	content := pdf.getContent().stream.String()
+	re := regexp.MustCompile(`(?s)q\nBT\n.*/F1 14 Tf\n0\.000 0\.000 1\.000 rg\n<[0-9A-F]+> Tj\n/F1 10 Tf\n1\.000 0\.000 0\.000 rg\n<[0-9A-F]+> Tj\nET\nQ\n$`)
+	if !re.MatchString(content) {
+		t.Errorf("color operators must be between runs in one text object\n%s", content)
+	}
+	pdf.SetFont("loma", "", 14)
+	w1, _ := pdf.MeasureTextWidth("func")
+	pdf.SetFont("loma", "", 10)
+	w2, _ := pdf.MeasureTextWidth(" main()")
+	if math.Abs(pdf.GetX()-startX-(w1+w2)) > 0.001 {
+		t.Errorf("advance of rich text must be the sum of runs")
+	}
+
+	//run ที่เปลี่ยนแค่ style ก็ต้องเปลี่ยน font และ run ที่ไม่ระบุกลับไปใช้ font เดิม
+	pdf.SetFont("loma", "", 14)
+	var fonts []string
+	err = pdf.RichText([]RichSegment{
+		{Text: "a", Size: 10},
+		{Text: "b", Style: "U"},
+		{Text: "c"},
+	})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	for _, line := range strings.Split(pdf.getContent().stream.String(), "\n") {
+		if strings.HasSuffix(line, " Tf") {
+			fonts = append(fonts, line)
+		}
+	}
+	if expected := []string{"/F1 10 Tf", "/F1 14 Tf", "/F1 14 Tf"}; !reflect.DeepEqual(fonts[len(fonts)-3:], expected) {
+		t.Errorf("expect fonts %q but got %q", expected, fonts)
+	}
+	if pdf.Curr.Font_Style != "" {
+		t.Errorf("font style must be restored after rich text")
+	}
+}
+
+func TestSetDocumentJavaScript(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	pdf.SetDocumentJavaScript("zinit", "app.alert(\"(hello)\");")
+	pdf.SetDocumentJavaScript("check", "var a = 1;")
+
+	s := string(pdf.GetBytesPdf())
+	re := regexp.MustCompile(`/Names << /JavaScript << /Names \[ \(check\) (\d+) 0 R \(zinit\) (\d+) 0 R \] >> >>`)
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		t.Fatalf("javascript name tree not found in catalog")
+	}
+	if !strings.Contains(s, m[2]+" 0 obj\n<<\n/S /JavaScript\n/JS (app.alert\\(\"\\(hello\\)\"\\);)\n>>\n") {
+		t.Errorf("javascript action not found")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestSpaceWidth(t *testing.T) {
+	pdf := newTestPdf(t)
+	sub := pdf.Curr.Font_ISubset.(*SubsetFontObj)
+	widths := sub.GetTTFParser().Widths()
+	spaceGlyph := sub.CharCodeToGlyphIndex(' ')
+	if spaceGlyph == 0 {
+		t.Fatalf("Loma must have space glyph")
+	}
+	a, _ := pdf.MeasureTextWidth("aa")
+	spaced, _ := pdf.MeasureTextWidth("a a")
+	expect := float64(sub.GlyphIndexToPdfWidth(spaceGlyph)) * 14 / 1000.0
+	if math.Abs(spaced-a-expect) > 0.001 || expect == 0 {
+		t.Errorf("space must contribute its hmtx advance")
+	}
+
+	//font that space has zero width
+	widths[spaceGlyph] = 0
+	pdf.ClearMeasureCache()
+	spaced, _ = pdf.MeasureTextWidth("a a")
+	if math.Abs(spaced-a-250*14/1000.0) > 0.001 {
+		t.Errorf("space without width must fall back to 1/4 em")
+	}
+	lines, _, _ := pdf.WrapText("a a a a", a)
+	if len(lines) != 4 {
+		t.Errorf("line must wrap at space but got %q", lines)
+	}
+}
+
+func TestCIDSystemInfo(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.Cell(nil, "cid")
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "/CIDSystemInfo\n<<\n  /Ordering (Identity)\n  /Registry (Adobe)\n  /Supplement 0\n>>\n") {
+		t.Errorf("CIDSystemInfo of Adobe-Identity-0 not found")
+	}
+	if !strings.Contains(s, "/Encoding /Identity-H\n/Subtype /Type0\n") {
+		t.Errorf("Identity-H encoding not found")
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetX(50)
+	pdf.SetY(60)
+	err := pdf.BarChart(50, 100, 300, 200, []float64{10, 20, 40}, ChartOption{
+		FillColor:  &RichColor{R: 255},
+		ShowValues: true,
+		Labels:     []string{"a", "b", "c"},
+	})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if pdf.GetX() != 50 || pdf.GetY() != 60 {
+		t.Errorf("current position must not be changed by chart")
+	}
+	stream := pdf.getContent().stream.String()
+	if n := strings.Count(stream, "h f\n"); n != 3 {
+		t.Errorf("expect 3 filled bars but got %d", n)
+	}
+	//highest bar fill whole chart height
+	if !strings.Contains(stream, "260.00 541.89 m\n340.00 541.89 l\n340.00 741.89 l\n260.00 741.89 l\nh f\n") {
+		t.Errorf("bar of max value must be full height\n%s", stream)
+	}
+	if !strings.Contains(stream, "1.000 0.000 0.000 rg\n") {
+		t.Errorf("fill color of bars not found")
+	}
+
+	//เส้นของ LineChart กับแกนวาดด้วย Line จึงวัดขนาดได้
+	pdf.BeginMeasure()
+	err = pdf.LineChart(50, 100, 300, 200, []float64{10, 20, 40}, ChartOption{LineColor: &RichColor{B: 255}})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	x, y, size := pdf.EndMeasure()
+	if x != 50 || y != 100 || size.W != 300 || size.H != 200 {
+		t.Errorf("expect chart area 50,100 300x200 but got %f,%f %v", x, y, size)
+	}
+	err = pdf.LineChart(50, 100, 300, 200, []float64{10, 20, 40}, ChartOption{LineColor: &RichColor{B: 255}})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	stream = pdf.getContent().stream.String()
+	if !strings.Contains(stream, "0.000 0.000 1.000 RG\n100.00 591.89 m 200.00 641.89 l s\n200.00 641.89 m 300.00 741.89 l s\n") {
+		t.Errorf("segments of line chart not found\n%s", stream)
+	}
+}
+
+func TestFontOptionItalicAngle(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	italicAngle := -12.5
+	stemV := 80
+	fontPath := testFontPath(t)
+	err := pdf.AddTTFFontWithOption("loma", fontPath, FontOption{ItalicAngle: &italicAngle, StemV: &stemV})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	err = pdf.SetFont("loma", "", 14)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Cell(nil, "italic")
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "/ItalicAngle -12.5\n/StemV 80\n") {
+		t.Errorf("overridden italic angle and stem v not found in font descriptor")
+	}
+	if !strings.Contains(s, "/Flags ") || strings.Count(s, "/ItalicAngle ") != 1 {
+		t.Errorf("values that are not overridden must come from font")
+	}
+
+	//เพิ่ม font เดิมซ้ำด้วย option เดิมได้ แต่ option อื่นต้อง error
+	sameAngle := -12.5
+	sameStemV := 80
+	err = pdf.AddTTFFontWithOption("loma", fontPath, FontOption{ItalicAngle: &sameAngle, StemV: &sameStemV})
+	if err != nil {
+		t.Errorf("same option must be accepted but got %v", err)
+	}
+	otherAngle := 10.0
+	err = pdf.AddTTFFontWithOption("loma", fontPath, FontOption{ItalicAngle: &otherAngle})
+	if err != ErrFontOptionConflict {
+		t.Errorf("expect ErrFontOptionConflict but got %v", err)
+	}
+}
+
+func TestFontOptionNotEmbedded(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	embed := false
+	err := pdf.AddTTFFontWithOption("loma", testFontPath(t), FontOption{Embed: &embed})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	err = pdf.SetFont("loma", "", 14)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Cell(nil, "not embedded")
+	b := pdf.GetBytesPdf()
+	s := string(b)
+	if strings.Contains(s, "/FontFile") {
+		t.Errorf("font file must not be written")
+	}
+	if !strings.Contains(s, "/BaseFont /Loma\n") || !strings.Contains(s, "/FontName /Loma\n") {
+		t.Errorf("font must be referenced by PostScript name without subset tag")
+	}
+	checkXref(t, b)
+}
+
+func TestAddTTFFontByReader(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	b, err := ioutil.ReadFile(testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	for _, alias := range []string{"body", "heading"} {
+		if err := pdf.AddTTFFontByReader(alias, bytes.NewReader(b)); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+	for _, alias := range []string{"body", "heading"} {
+		if err := pdf.SetFont(alias, "", 14); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		pdf.Cell(nil, alias)
+	}
+	s := string(pdf.GetBytesPdf())
+	if n := strings.Count(s, "/FontFile2"); n != 1 {
+		t.Errorf("expect 1 font file but got %d", n)
+	}
+}
+
+func TestGeneratePages(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	err := pdf.AddTTFFont("loma", testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetPageTemplate(func() {
+		pdf.SetLineWidth(2)
+	})
+	var renders []func(p *GoPdf) error
+	for _, title := range []string{"one", "two", "three"} {
+		title := title
+		renders = append(renders, func(p *GoPdf) error {
+			if err := p.SetFont("loma", "", 14); err != nil {
+				return err
+			}
+			p.Cell(nil, title)
+			return nil
+		})
+	}
+	err = pdf.GeneratePages(renders)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	streams := make(map[string]bool)
+	for _, obj := range pdf.pdfObjs {
+		if content, ok := obj.(*ContentObj); ok {
+			stream := content.stream.String()
+			if !strings.HasPrefix(stream, "2.00 w\n") {
+				t.Errorf("page template must run before render\n%s", stream)
+			}
+			streams[stream] = true
+		}
+	}
+	if len(streams) != 3 {
+		t.Fatalf("expect 3 pages with distinct content but got %d", len(streams))
+	}
+
+	errRender := errors.New("render failed")
+	err = pdf.GeneratePages([]func(p *GoPdf) error{
+		func(p *GoPdf) error { return errRender },
+		func(p *GoPdf) error { return nil },
+	})
+	pdf.prepare()
+	if pageCount := pdf.pdfObjs[pdf.indexOfPagesObj].(*PagesObj).PageCount; err != errRender || pageCount != 4 {
+		t.Errorf("expect error of render and no more pages but got %v and %d pages", err, pageCount)
+	}
+}
+
+func TestAddGuide(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.AddGuide(10, 20, 200, 20)
+	pdf.AddGuide(20, 10, 20, 300)
+	stream := pdf.getContent().stream.String()
+	if strings.Count(stream, "/OC /OCGuides BDC\n") != 2 || strings.Count(stream, "EMC\n") != 2 {
+		t.Errorf("guides must be marked as optional content\n%s", stream)
+	}
+	b := pdf.GetBytesPdf()
+	s := string(b)
+	if strings.Count(s, "/Type /OCG\n") != 1 {
+		t.Fatalf("guides must share one optional content group")
+	}
+	ref := fmt.Sprintf("%d 0 R", pdf.indexOfGuideOCG+1)
+	if !strings.Contains(s, "/Usage << /Print << /PrintState /OFF >> /View << /ViewState /ON >> >>") {
+		t.Errorf("guide group must not be printed")
+	}
+	if !strings.Contains(s, "/OCProperties << /OCGs [ "+ref+" ]") || !strings.Contains(s, "/Properties <<\n/OCGuides "+ref) {
+		t.Errorf("guide group must be in catalog and page resources")
+	}
+	checkXref(t, b)
+}
+
+func TestSubsetReplaceNotdefWithEmpty(t *testing.T) {
+	glyfOf := func(option FontOption) ([]byte, []int, []byte) {
+		pdf := GoPdf{}
+		pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+		pdf.AddPage()
+		err := pdf.AddTTFFontWithOption("loma", testFontPath(t), option)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		pdf.SetFont("loma", "", 14)
+		pdf.Cell(nil, "notdef")
+		b := pdf.GetBytesPdf()
+		checkXref(t, b)
+		dic := &PdfDictionaryObj{PtrToSubsetFontObj: pdf.findSubsetFont("loma")}
+		glyf, loca, err := dic.makeGlyfAndLocaTable()
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		return glyf, loca, b
+	}
+	keptGlyf, keptLoca, kept := glyfOf(FontOption{})
+	emptyGlyf, emptyLoca, empty := glyfOf(FontOption{Subset: SubsetOption{ReplaceNotdefWithEmpty: true}})
+	if keptLoca[1] == 0 || emptyLoca[1] != 0 {
+		t.Errorf("expect outline of .notdef only when it is kept but got loca %d and %d", keptLoca[1], emptyLoca[1])
+	}
+	if len(emptyLoca) != len(keptLoca) || len(emptyGlyf) >= len(keptGlyf) || len(empty) >= len(kept) {
+		t.Errorf("subset with empty .notdef must keep every glyph and be smaller")
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.MultiCell(500, 0, strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20))
+	pdf.Image(testImagePath(t, 64, 64, color.RGBA{R: 255, A: 255}), 10, 300, nil)
+	pdf.AddPage()
+	pdf.Cell(nil, "page two")
+	estimate := pdf.EstimateSize()
+	actual := int64(len(pdf.GetBytesPdf()))
+	if estimate < actual*7/10 || estimate > actual*13/10 {
+		t.Errorf("estimate %d is too far from actual size %d", estimate, actual)
+	}
+}
+
+func TestTextBoundsTight(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetFont("loma", "", 20)
+	w, ascent, descent, err := pdf.TextBounds("Ag")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	tightW, tightAscent, tightDescent, err := pdf.TextBoundsTight("Ag")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	sub := pdf.findSubsetFont("loma")
+	ttfp := sub.GetTTFParser()
+	a, _ := ttfp.GlyphBounds(ttfp.Chars()['A'])
+	g, _ := ttfp.GlyphBounds(ttfp.Chars()['g'])
+	//top of 'A' and bottom of 'g'
+	wantAscent := sub.scaleToPDF(a.YMax) * 20 / 1000
+	wantDescent := -sub.scaleToPDF(g.YMin) * 20 / 1000
+	if tightAscent != wantAscent || tightDescent != wantDescent || tightDescent <= 0 {
+		t.Errorf("expect tight ascent %0.2f descent %0.2f but got %0.2f %0.2f", wantAscent, wantDescent, tightAscent, tightDescent)
+	}
+	if tightAscent >= ascent || tightDescent >= descent || tightW <= 0 || tightW > w {
+		t.Errorf("tight bounds %0.2f %0.2f %0.2f must be inside font metrics %0.2f %0.2f %0.2f", tightW, tightAscent, tightDescent, w, ascent, descent)
+	}
+}
+
+func TestSetContentStreamMode(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.SetContentStreamMode("array"); err != ErrUnknownContentStreamMode {
+		t.Errorf("expect ErrUnknownContentStreamMode but got %v", err)
+	}
+	if err := pdf.SetContentStreamMode("perPage"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Cell(nil, "page one")
+	pdf.AddPage()
+	pdf.Cell(nil, "page two")
+	pdf.AddPage()
+	pdf.Cell(nil, "page three")
+	//stream ที่สองของหน้าสาม
+	pdf.indexOfContent = -1
+	pdf.Line(10, 10, 100, 10)
+
+	pdf.GetBytesPdf()
+	var pages []*PageObj
+	for _, obj := range pdf.pdfObjs {
+		if page, ok := obj.(*PageObj); ok {
+			pages = append(pages, page)
+		}
+	}
+	seen := make(map[int]bool)
+	for _, page := range pages[:2] {
+		if len(page.indexOfContents) != 1 || seen[page.indexOfContents[0]] {
+			t.Fatalf("every page must have its own content stream")
+		}
+		seen[page.indexOfContents[0]] = true
+		if !strings.Contains(page.GetObjBuff().String(), fmt.Sprintf("/Contents  %d 0 R \n", page.indexOfContents[0]+1)) {
+			t.Errorf("/Contents must refer to stream of page\n%s", page.GetObjBuff().String())
+		}
+	}
+	if !strings.Contains(pages[2].GetObjBuff().String(), "/Contents [") {
+		t.Errorf("page with two streams must refer to them as array\n%s", pages[2].GetObjBuff().String())
+	}
+
+	pdf.SetContentStreamMode("single")
+	b := pdf.GetBytesPdf()
+	third := pages[2].indexOfContents
+	if len(third) != 1 || !strings.Contains(pdf.pdfObjs[third[0]].(*ContentObj).stream.String(), " l s\n") {
+		t.Errorf("streams of page must be merged into one stream")
+	}
+	//stream ที่ถูกรวมแล้วต้องไม่ถูกเขียน
+	if pdf.XrefOffsets()[pdf.indexOfContent] != -1 {
+		t.Errorf("merged stream must not be written")
+	}
+	checkXref(t, b)
+
+	pdf.SetContentStreamMode("perPage")
+	b = pdf.GetBytesPdf()
+	if pdf.XrefOffsets()[pdf.indexOfContent] == -1 || !strings.Contains(pages[2].GetObjBuff().String(), "/Contents [") {
+		t.Errorf("stream that is referred again in perPage mode must be written")
+	}
+	checkXref(t, b)
+}
+
+func TestSubsetStripHinting(t *testing.T) {
+	tagsOf := func(option FontOption) map[string]bool {
+		pdf := GoPdf{}
+		pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+		pdf.AddPage()
+		err := pdf.AddTTFFontWithOption("loma", testFontPath(t), option)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		pdf.SetFont("loma", "", 14)
+		pdf.Cell(nil, "hinting")
+		b, err := (&PdfDictionaryObj{PtrToSubsetFontObj: pdf.findSubsetFont("loma")}).makeFont()
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		//table directory ของ subset
+		tags := make(map[string]bool)
+		numTables := int(b[4])<<8 | int(b[5])
+		for i := 0; i < numTables; i++ {
+			tags[string(b[12+i*16:16+i*16])] = true
+		}
+		return tags
+	}
+	tags := tagsOf(FontOption{})
+	if !tags["fpgm"] || !tags["prep"] || !tags["cvt "] || !tags["glyf"] {
+		t.Errorf("subset must keep hinting tables but got %v", tags)
+	}
+	tags = tagsOf(FontOption{Subset: SubsetOption{StripHinting: true}})
+	if tags["fpgm"] || tags["prep"] || tags["cvt "] || !tags["glyf"] {
+		t.Errorf("subset must not have hinting tables but got %v", tags)
+	}
+}
+
+func TestSetGlyphUnicodeMap(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.SetGlyphUnicodeMap("notfound", nil); err != ErrFontFamilyNotFound {
+		t.Errorf("expect ErrFontFamilyNotFound but got %v", err)
+	}
+	pdf.Cell(nil, "AB")
+	sub := pdf.findSubsetFont("loma")
+	glyphA := sub.CharacterToGlyphIndex['A']
+	glyphB := sub.CharacterToGlyphIndex['B']
+	err := pdf.SetGlyphUnicodeMap("loma", map[uint64]rune{glyphA: 'X'})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	unicodeMap := &UnicodeMap{PtrToSubsetFontObj: sub}
+	stream := unicodeMap.pdfToUnicodeMap().String()
+	if !strings.Contains(stream, fmt.Sprintf("<%04X><%04X><0058>\n", glyphA, glyphA)) {
+		t.Errorf("glyph of A must map to X\n%s", stream)
+	}
+	if !strings.Contains(stream, fmt.Sprintf("<%04X><%04X><0042>\n", glyphB, glyphB)) {
+		t.Errorf("glyph of B must keep unicode from cmap\n%s", stream)
+	}
+}
+
+func TestSetDefaultColorSpace(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.SetDefaultColorSpace("HSV"); err != ErrUnknownColorSpace {
+		t.Errorf("expect ErrUnknownColorSpace but got %v", err)
+	}
+	if err := pdf.SetDefaultColorSpace("CMYK"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetFillColor(255, 0, 0)
+	pdf.SetStrokeColor(0, 0, 0)
+	pdf.Cell(nil, "cmyk")
+	pdf.SetDefaultColorSpace("Gray")
+	pdf.SetFillColor(255, 255, 255)
+	stream := pdf.getContent().stream.String()
+	if !strings.Contains(stream, "0.000 1.000 1.000 0.000 k\n") || !strings.Contains(stream, "0.000 0.000 0.000 1.000 K\n") {
+		t.Errorf("color must be emitted as cmyk\n%s", stream)
+	}
+	if strings.Contains(stream, " rg\n") || strings.Contains(stream, " RG\n") {
+		t.Errorf("rgb color must not be emitted")
+	}
+	if !strings.HasSuffix(stream, "1.000 g\n") {
+		t.Errorf("color must be emitted as gray")
+	}
+}
+
+func TestImageFit(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	wide := testImagePath(t, 40, 20, color.RGBA{B: 255, A: 255})
+	pdf.SetX(100)
+	pdf.SetY(100)
+	err := pdf.ImageFit(wide, Rect{W: 100, H: 100}, "")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	err = pdf.ImageFit(wide, Rect{W: 100, H: 100}, "top")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	stream := pdf.getContent().stream.String()
+	//100x50 centered in 100x100 box , 25 above and below
+	if !strings.Contains(stream, "q 100.00 0 0 50.00 100.00 666.89 cm /I1 Do Q\n") {
+		t.Errorf("image must be centered in box\n%s", stream)
+	}
+	if !strings.Contains(stream, "q 100.00 0 0 50.00 100.00 691.89 cm /I1 Do Q\n") {
+		t.Errorf("image must be at top of box\n%s", stream)
+	}
+	if err := pdf.ImageFit("notfound.jpg", Rect{W: 100, H: 100}, ""); err == nil {
+		t.Errorf("expect error of missing image")
+	}
+}
+
+func TestImageWithColorKey(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	img := testImagePath(t, 8, 8, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	pdf.Image(img, 10, 10, nil)
+	pdf.ImageWithColorKey(img, 10, 100, nil, [3]uint8{240, 240, 240}, [3]uint8{255, 255, 255})
+	s := string(pdf.GetBytesPdf())
+	if strings.Count(s, "/Subtype /Image\n") != 2 {
+		t.Errorf("image with color key must be separate image obj")
+	}
+	if strings.Count(s, "/Mask [240 255 240 255 240 255]\n") != 1 {
+		t.Errorf("color key range not found")
+	}
+}
+
+func TestImageRotated(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	img := testImagePath(t, 8, 8, color.RGBA{G: 255, A: 255})
+	x, y, box := pdf.ImageRotated(img, 100, 100, &Rect{W: 40, H: 20}, 45)
+	//กรอบของภาพที่หมุน 45 องศารอบจุดกลาง 120,110
+	side := 60 / math.Sqrt2
+	if math.Abs(x-(120-side/2)) > 0.001 || math.Abs(y-(110-side/2)) > 0.001 || math.Abs(box.W-side) > 0.001 || math.Abs(box.H-side) > 0.001 {
+		t.Errorf("unexpected bounding box %f,%f %v", x, y, box)
+	}
+	if pdf.GetX() != x || pdf.GetY() != y+box.H {
+		t.Errorf("current position must be lower left of bounding box but got %f,%f", pdf.GetX(), pdf.GetY())
+	}
+	pdf.BeginMeasure()
+	x, y, box = pdf.ImageRotated(img, 100, 100, &Rect{W: 40, H: 20}, 90, ImageRotatedOption{Anchor: "topleft"})
+	mx, my, measured := pdf.EndMeasure()
+	if math.Abs(x-100) > 0.001 || math.Abs(y-60) > 0.001 || math.Abs(box.W-20) > 0.001 || math.Abs(box.H-40) > 0.001 {
+		t.Errorf("unexpected bounding box of rotation around top left %f,%f %v", x, y, box)
+	}
+	if mx != x || my != y || measured != box {
+		t.Errorf("measure must use bounding box but got %f,%f %v", mx, my, measured)
+	}
+	pdf.ImageRotated(img, 100, 100, &Rect{W: 40, H: 20}, 90, ImageRotatedOption{Anchor: "topleft"})
+	stream := pdf.getContent().stream.String()
+	//หมุนรอบจุดกลาง 120,731.89 (pdf space)
+	center := "q\n0.7071 0.7071 -0.7071 0.7071 552.67 129.51 cm\nq 40.00 0 0 20.00 100.00 721.89 cm /I1 Do Q\nQ\n"
+	if !strings.Contains(stream, center) {
+		t.Errorf("rotation around center must precede image\n%s", stream)
+	}
+	//หมุนรอบมุมบนซ้าย 100,741.89
+	topLeft := "q\n0.0000 1.0000 -1.0000 0.0000 841.89 641.89 cm\nq 40.00 0 0 20.00 100.00 721.89 cm /I1 Do Q\nQ\n"
+	if !strings.Contains(stream, topLeft) {
+		t.Errorf("rotation around top left corner must precede image\n%s", stream)
+	}
+}
+
+func TestImageMask(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	//ตัว L ดำ 10x10 บนพื้นโปร่งใส
+	mask := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < 10; i++ {
+		mask.Set(0, i, color.Black)
+		mask.Set(i, 9, color.Black)
+	}
+	pdf.SetFillColor(255, 0, 0)
+	pdf.ImageMask(mask, 10, 10, &Rect{W: 20, H: 20})
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "/ImageMask true\n/BitsPerComponent 1\n") {
+		t.Errorf("image mask must be 1 bit stencil")
+	}
+	if strings.Contains(s, "/ColorSpace") {
+		t.Errorf("image mask must not have color space")
+	}
+	bits := (&ImageMaskObj{mask: mask}).bits()
+	//แถวละ 2 bytes , bit 0 = paint
+	if len(bits) != 20 || bits[0] != 0x7F || bits[1] != 0xC0 || bits[18] != 0x00 || bits[19] != 0x00 {
+		t.Errorf("unexpected mask bits %x", bits)
+	}
+}
+
+func TestUsedGlyphs(t *testing.T) {
+	pdf := newTestPdf(t)
+	if glyphs := pdf.UsedGlyphs("loma"); len(glyphs) != 0 {
+		t.Errorf("no glyph is used before drawing but got %v", glyphs)
+	}
+	pdf.MeasureTextWidth("measured")
+	pdf.Cell(nil, "hello")
+	pdf.Cell(nil, "world")
+
+	sub := pdf.findSubsetFont("loma")
+	var expect []uint64
+	for _, r := range "dehlorw" {
+		expect = append(expect, sub.CharCodeToGlyphIndex(r))
+	}
+	sort.Slice(expect, func(i, j int) bool { return expect[i] < expect[j] })
+	glyphs := pdf.UsedGlyphs("loma")
+	if fmt.Sprint(glyphs) != fmt.Sprint(expect) {
+		t.Errorf("expect used glyphs %v but got %v", expect, glyphs)
+	}
+	if pdf.UsedGlyphs("notfound") != nil {
+		t.Errorf("unknown family must return nil")
+	}
+}
+
+func TestSetFontFeatures(t *testing.T) {
+	pdf := newTestPdf(t)
+	sub := pdf.findSubsetFont("loma")
+	f := sub.CharCodeToGlyphIndex('f')
+	i := sub.CharCodeToGlyphIndex('i')
+	var fi uint64
+	for _, lookup := range sub.GetTTFParser().GSUBLookups([]string{"liga"}) {
+		for _, ligature := range lookup.Ligatures[f] {
+			if len(ligature.Components) == 1 && ligature.Components[0] == i {
+				fi = ligature.Glyph
+			}
+		}
+	}
+	if fi == 0 {
+		t.Fatalf("fi ligature not found in liga of test font")
+	}
+	separated := fmt.Sprintf("<%04X%04X> Tj\n", f, i)
+	ligature := fmt.Sprintf("<%04X> Tj\n", fi)
+
+	pdf.Cell(nil, "fi")
+	pdf.SetFontFeatures(map[string]bool{"liga": true, "smcp": true, "none": true})
+	width, _ := pdf.MeasureTextWidth("fi")
+	if expect := float64(sub.GlyphIndexToPdfWidth(fi)) * 14 / 1000; math.Abs(width-expect) > 0.001 {
+		t.Errorf("width of ligature must be %f but got %f", expect, width)
+	}
+	pdf.Cell(nil, "fi")
+	pdf.SetFontFeatures(map[string]bool{"liga": false})
+	pdf.Cell(nil, "fi")
+
+	stream := pdf.getContent().stream.String()
+	if n := strings.Count(stream, separated); n != 2 {
+		t.Errorf("expect fi drawn as separate glyphs twice but found %d", n)
+	}
+	if n := strings.Count(stream, ligature); n != 1 {
+		t.Errorf("expect fi ligature once but found %d", n)
+	}
+	used := fmt.Sprint(pdf.UsedGlyphs("loma"))
+	if !strings.Contains(used, fmt.Sprint(fi)) {
+		t.Errorf("ligature glyph must be in subset %s", used)
+	}
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, fmt.Sprintf("<%04X><%04X><00660069>\n", fi, fi)) {
+		t.Errorf("ligature must map to fi in ToUnicode")
+	}
+	checkXref(t, []byte(s))
+}
+
+func TestFontBBox(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.Cell(nil, "bbox")
+	ttfp := pdf.findSubsetFont("loma").GetTTFParser()
+	upem := ttfp.UnitsPerEm()
+	if upem == 1000 {
+		t.Fatalf("test font must not use 1000 units per em")
+	}
+	scale := func(v int64) float64 {
+		return math.Round(float64(v) * 1000 / float64(upem))
+	}
+	expect := fmt.Sprintf("/FontBBox [%.0f %.0f %.0f %.0f]\n", scale(ttfp.XMin()), scale(ttfp.YMin()), scale(ttfp.XMax()), scale(ttfp.YMax()))
+	if expect != "/FontBBox [-743 -440 1338 1146]\n" {
+		t.Fatalf("unexpected head table bounds %q", expect)
+	}
+	if s := string(pdf.GetBytesPdf()); !strings.Contains(s, expect) {
+		t.Errorf("FontBBox must be head table bounds scaled to 1000 units per em")
+	}
+}
+
+func TestSetPageLabel(t *testing.T) {
+	pdf := newTestPdf(t)
+	for i := 0; i < 5; i++ {
+		pdf.AddPage()
+	}
+	if err := pdf.SetPageLabel(1, "greek", ""); err != ErrUnknownPageLabelStyle {
+		t.Errorf("expect ErrUnknownPageLabelStyle but got %v", err)
+	}
+	if err := pdf.SetPageLabel(1, "lowerRoman", ""); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.SetPageLabel(4, "decimal", "P-"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "  /PageLabels << /Nums [ 0 << /S /r >> 3 << /S /D /P (P-) >> ] >>\n") {
+		t.Errorf("page labels not found")
+	}
+
+	//first range that not start at first page get decimal labels before it
+	pdf = newTestPdf(t)
+	pdf.AddPage()
+	pdf.SetPageLabel(2, "upperAlpha", "")
+	s = string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "  /PageLabels << /Nums [ 0 << /S /D >> 1 << /S /A >> ] >>\n") {
+		t.Errorf("page labels must start at first page")
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetLanguage("en-US")
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "  /Lang (en-US)\n") {
+		t.Errorf("/Lang of catalog not found")
+	}
+}
+
+func TestAddImageFloat(t *testing.T) {
+	pdf := newTestPdf(t)
+	img := testImagePath(t, 128, 128, color.RGBA{R: 255, A: 255}) //72 x 72 pt
+	if err := pdf.AddImageFloat(img, "top", 8); err != ErrUnknownFloatSide {
+		t.Errorf("expect ErrUnknownFloatSide but got %v", err)
+	}
+	if err := pdf.AddImageFloat(img, "left", 8); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if pdf.GetX() != 10 || pdf.GetY() != 10 {
+		t.Errorf("current position must not be changed")
+	}
+	text := strings.Repeat("float text wraps around image ", 20)
+	if err := pdf.MultiCell(200, 20, text); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	//4 lines beside image (y 10 to 90) start after image and margin , later lines use whole width
+	re := regexp.MustCompile(`(\d+\.\d+) \d+\.\d+ TD\n/F1 14 Tf\n<([0-9A-F]*)> Tj\n`)
+	matches := re.FindAllStringSubmatch(pdf.getContent().stream.String(), -1)
+	if len(matches) < 6 {
+		t.Fatalf("expect more than 6 lines but got %d", len(matches))
+	}
+	for i, match := range matches {
+		expect := "10.00"
+		if i < 4 {
+			expect = "90.00"
+		}
+		if match[1] != expect {
+			t.Errorf("line %d must start at %s but start at %s", i, expect, match[1])
+		}
+	}
+	if len(matches[0][2]) >= len(matches[4][2]) {
+		t.Errorf("lines beside image must be narrowed")
+	}
+}
+
+func TestAddOutlineWithPosition(t *testing.T) {
+	empty := GoPdf{}
+	empty.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	if err := empty.AddOutlineWithPosition("No page", Destination{}); err != ErrPageOutOfRange {
+		t.Errorf("expect ErrPageOutOfRange before first page but got %v", err)
+	}
+	empty.AddOutline("No page")
+	empty.AddPage()
+	if s := string(empty.GetBytesPdf()); strings.Contains(s, "/Outlines") || strings.Contains(s, "[0 0 R") {
+		t.Errorf("outline without page must not be added")
+	}
+
+	pdf := newTestPdf(t)
+	pdf.AddOutline("Cover")
+	pdf.AddPage()
+	if err := pdf.AddOutlineWithPosition("Chapter", Destination{Kind: "FitB"}); err != ErrUnknownDestination {
+		t.Errorf("expect ErrUnknownDestination but got %v", err)
+	}
+	if err := pdf.AddOutlineWithPosition("Chapter", Destination{Kind: "FitH", Top: 100}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.AddOutlineWithPosition("บทที่ 2", Destination{Kind: "Fit"}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	page2 := pdf.Curr.IndexOfPageObj + 1
+	b := pdf.GetBytesPdf()
+	s := string(b)
+	if !strings.Contains(s, "  /Outlines ") || !strings.Contains(s, "/Type /Outlines\n") || !strings.Contains(s, "/Count 3\n") {
+		t.Errorf("outlines not found")
+	}
+	if !strings.Contains(s, "/Title (Cover)\n") || !strings.Contains(s, " 0 R /XYZ null 841.89 null]\n") {
+		t.Errorf("default bookmark must open top of page")
+	}
+	if !strings.Contains(s, fmt.Sprintf("/Dest [%d 0 R /FitH 741.89]\n", page2)) {
+		t.Errorf("FitH destination not found")
+	}
+	if !strings.Contains(s, "/Title <FEFF0E1A0E170E170E350E4800200032>\n") || !strings.Contains(s, fmt.Sprintf("/Dest [%d 0 R /Fit]\n", page2)) {
+		t.Errorf("Fit bookmark with unicode title not found")
+	}
+	checkXref(t, b)
+}
+
+func TestParagraphInBox(t *testing.T) {
+	pdf := newTestPdf(t)
+	box := Rect{W: 200, H: 300}
+	text := "First line\nSecond line"
+	pdf.SetY(100)
+	overflow, err := pdf.ParagraphInBox(box, text, "center", "top")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if overflow != "" {
+		t.Errorf("expect no overflow but got %q", overflow)
+	}
+	if pdf.GetY() != 400 {
+		t.Errorf("expect y below box 400 but got %f", pdf.GetY())
+	}
+	pdf.SetY(100)
+	if _, err := pdf.ParagraphInBox(box, text, "center", "middle"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	ys := textYs(pdf)
+	if len(ys) != 4 {
+		t.Fatalf("expect 4 lines but got %v", ys)
+	}
+	want := (box.H - 2*pdf.autoLineHeight()) / 2
+	if got := ys[0] - ys[2]; math.Abs(got-want) > 0.02 {
+		t.Errorf("expect middle block %f below top block but got %f", want, got)
+	}
+
+	overflow, err = pdf.ParagraphInBox(Rect{W: 200, H: pdf.autoLineHeight() * 1.5}, text, "left", "top")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if overflow != "Second line" {
+		t.Errorf("expect overflow %q but got %q", "Second line", overflow)
+	}
+}
+
+func TestColor(t *testing.T) {
+	pdf := newTestPdf(t)
+	orange, err := Hex("#ff8800")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if orange != RGB(255, 136, 0) {
+		t.Errorf("expect rgb 255,136,0 but got %v", orange)
+	}
+	if _, err := Hex("#ff88"); err != ErrInvalidHexColor {
+		t.Errorf("expect ErrInvalidHexColor but got %v", err)
+	}
+	pdf.SetFill(orange)
+	pdf.SetStroke(CMYK(0, 0.5, 1, 0))
+	pdf.SetFill(Gray(0.25))
+	s := pdf.getContent().stream.String()
+	for _, op := range []string{"1.000 0.533 0.000 rg\n", "0.000 0.500 1.000 0.000 K\n", "0.250 g\n"} {
+		if !strings.Contains(s, op) {
+			t.Errorf("%q not found in content stream", op)
+		}
+	}
+}
+
+func TestBeginMeasure(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetX(50)
+	pdf.SetY(100)
+	text := "measure this block of text that is long enough to wrap into several lines"
+	before := pdf.getContent().stream.Len()
+	pdf.BeginMeasure()
+	if err := pdf.MultiCell(120, 0, text); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	x, y, size := pdf.EndMeasure()
+	if pdf.getContent().stream.Len() != before {
+		t.Errorf("nothing must be drawn in measure")
+	}
+	if pdf.GetX() != 50 || pdf.GetY() != 100 {
+		t.Errorf("position must be restored but got %f,%f", pdf.GetX(), pdf.GetY())
+	}
+	if x != 50 || y != 100 || size.W > 120 || size.H < 2*pdf.autoLineHeight() {
+		t.Errorf("unexpected extents %f,%f %v", x, y, size)
+	}
+	if err := pdf.MultiCell(120, 0, text); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if h := pdf.GetY() - 100; math.Abs(h-size.H) > 0.001 {
+		t.Errorf("expect height %f of real draw but got %f", h, size.H)
+	}
+}
+
+func TestBeginMeasureImage(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var img bytes.Buffer
+	if err := png.Encode(&img, m); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), "img.png")
+	if err := ioutil.WriteFile(path, img.Bytes(), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	pdf := newTestPdf(t)
+	pdf.BeginMeasure()
+	if err := pdf.ImageReturnErr(path, 10, 10, &Rect{W: 20, H: 30}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	x, y, size := pdf.EndMeasure()
+	if x != 10 || y != 10 || size.W != 20 || size.H != 30 {
+		t.Errorf("unexpected extents %f,%f %v", x, y, size)
+	}
+	if pdf.Curr.CountOfImg != 0 || len(pdf.Curr.ImgCaches) != 0 {
+		t.Errorf("image of measure must be removed from image cache")
+	}
+	b := pdf.GetBytesPdf()
+	if strings.Contains(string(b), "/Subtype /Image") {
+		t.Errorf("image that is drawn only in measure must not be embedded")
+	}
+	checkXref(t, b)
+
+	//รูปเดียวกันวาดจริงหลัง measure ต้องยังใช้ได้
+	if err := pdf.ImageReturnErr(path, 10, 10, nil); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	b = pdf.GetBytesPdf()
+	if !strings.Contains(string(b), "/Subtype /Image") || !strings.Contains(string(b), "/I1 ") {
+		t.Errorf("image drawn after measure not found")
+	}
+	checkXref(t, b)
+}
+
+func TestRadialGradient(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.RadialGradient(100, 200, 10, 50, [3]uint8{255, 255, 255}, [3]uint8{0, 0, 255}, RadialGradientOption{
+		ExtendEnd: true,
+		Clip:      &Rect{W: 120, H: 120},
+		ClipX:     40,
+		ClipY:     140,
+	})
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "/ShadingType 3\n") || !strings.Contains(s, "/Coords [100.00 641.89 10.00 100.00 641.89 50.00]\n") {
+		t.Errorf("radial shading not found")
+	}
+	if !strings.Contains(s, "/C0 [1.000 1.000 1.000] /C1 [0.000 0.000 1.000]") || !strings.Contains(s, "/Extend [false true]\n") {
+		t.Errorf("colors or extend of shading not found")
+	}
+	if !strings.Contains(s, "/Shading <<") || !strings.Contains(s, "q 40.00 581.89 120.00 120.00 re W n /Sh1 sh Q\n") {
+		t.Errorf("shading must be painted in clip and be in page resources")
+	}
+}
+
+func TestRadialGradientGrayscale(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetGrayscaleOutput(true)
+	pdf.RadialGradient(100, 200, 10, 50, [3]uint8{255, 255, 255}, [3]uint8{255, 0, 0})
+	s := string(pdf.GetBytesPdf())
+	if strings.Contains(s, "/DeviceRGB") {
+		t.Errorf("grayscale pdf must not have rgb shading")
+	}
+	gray := luminance(1, 0, 0)
+	if !strings.Contains(s, "/ColorSpace /DeviceGray\n") || !strings.Contains(s, fmt.Sprintf("/C0 [1.000] /C1 [%.3f]", gray)) {
+		t.Errorf("shading with gray colors not found")
+	}
+}
+
+func TestResetGraphicsState(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetFillColor(255, 0, 0)
+	pdf.SetStrokeColor(0, 0, 255)
+	pdf.SetLineWidth(3)
+	pdf.SetDashPattern([]float64{4, 2}, 0)
+	pdf.SetOverprint(true, true)
+	pdf.SetSpaceWidthFactor(1.5)
+	pdf.ResetGraphicsState()
+	s := pdf.getContent().stream.String()
+	reset := "0.000 g\n0.000 G\n1.00 w\n[] 0.00 d\n/GS2 gs\n0 Tc 0 Tw 0 Ts\n"
+	if !strings.HasSuffix(s, reset) {
+		t.Errorf("expect stream end with %q but got %q", reset, s)
+	}
+	if pdf.currSpaceWidthFactor() != 1 {
+		t.Errorf("space width factor must be reset")
+	}
+	b := string(pdf.GetBytesPdf())
+	if !strings.Contains(b, "/CA 1\n/ca 1\n/BM /Normal\n") {
+		t.Errorf("ExtGState with default alpha and blend mode not found")
+	}
+}
+
+func TestSmartWrite(t *testing.T) {
+	var visual []string
+	for _, segment := range visualOrder(scriptSegments("مرحبا hello")) {
+		visual = append(visual, string(segment.text))
+	}
+	if expected := []string{"hello", " ", "ابحرم"}; !reflect.DeepEqual(visual, expected) {
+		t.Errorf("expect %q but got %q", expected, visual)
+	}
+	for text, expected := range map[string]string{
+		"مرحبا 2024 عالم":        "ملاع 2024 ابحرم",
+		"Hello مرحبا 2024 world": "Hello 2024 ابحرم world",
+		"عدد 1,234.5":            "1,234.5 ددع",
+		"Total 2024":             "Total 2024",
+	} {
+		visual := ""
+		for _, segment := range visualOrder(scriptSegments(text)) {
+			visual += string(segment.text)
+		}
+		if visual != expected {
+			t.Errorf("expect %q but got %q", expected, visual)
+		}
+	}
+
+	pdf := newTestPdf(t)
+	if err := pdf.AddTTFFont("arabic", testFontPath(t)); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetScriptFont("Arabic", "arabic")
+	if err := pdf.SmartWrite(10, 100, "Hello مرحبا world"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	var fonts []string
+	for _, line := range strings.Split(pdf.getContent().stream.String(), "\n") {
+		if strings.HasSuffix(line, " Tf") {
+			fonts = append(fonts, line)
+		}
+	}
+	if expected := []string{"/F1 14 Tf", "/F1 14 Tf", "/F2 14 Tf", "/F2 14 Tf", "/F1 14 Tf"}; !reflect.DeepEqual(fonts, expected) {
+		t.Errorf("expect fonts %q but got %q", expected, fonts)
+	}
+	if pdf.Curr.Font_ISubset.(*SubsetFontObj).GetFamily() != "loma" {
+		t.Errorf("current font must not be changed")
+	}
+}
+
+func TestFlattenForms(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.AddTextField("name", 50, 100, 200, 20, "John"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "/AcroForm << /Fields [") || !strings.Contains(s, "/Subtype /Widget\n") || !strings.Contains(s, "/V (John)\n") {
+		t.Fatalf("text field not found")
+	}
+	if strings.Contains(pdf.getContent().stream.String(), "BT") {
+		t.Errorf("value must be drawn only in appearance stream")
+	}
+
+	pdf.FlattenForms()
+	b := pdf.GetBytesPdf()
+	s = string(b)
+	if strings.Contains(s, "/AcroForm") || strings.Contains(s, "/Annots") || strings.Contains(s, "/Widget") {
+		t.Errorf("form must be removed when flattened")
+	}
+	//value ที่ encode ด้วย glyph ของ subset อยู่ใน content ของหน้า
+	ops := string(pdf.getContent().flattenOps[0])
+	if !strings.Contains(ops, " Tf\n") || !strings.Contains(s, "q\n"+ops+"Q\n") {
+		t.Errorf("value must be drawn in page content")
+	}
+	checkXref(t, b)
+}
+
+func TestFormFieldAppearances(t *testing.T) {
+	pdf := newTestPdf(t)
+	long := "one two three four five six seven eight nine ten"
+	if err := pdf.AddTextField("note", 50, 100, 80, 80, long, FormFieldOption{Multiline: true}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.AddCheckBox("agree", 50, 200, 12, true); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.AddCheckBox("news", 50, 220, 12, false); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.AddChoiceField("size", 50, 240, 80, 20, []string{"S", "M", "L"}, "M"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.AddChoiceField("size2", 50, 270, 80, 20, []string{"S", "M"}, "XL"); err != ErrUnknownChoice {
+		t.Errorf("expect ErrUnknownChoice but got %v", err)
+	}
+	b := pdf.GetBytesPdf()
+	s := string(b)
+	if !strings.Contains(s, "/FT /Tx\n") || !strings.Contains(s, "/Ff 4096\n") {
+		t.Errorf("expect multiline text field")
+	}
+	note := pdf.pdfObjs[pdf.indexOfFormFields[0]].(*FormFieldObj)
+	if lines := strings.Count(string(pdf.pdfObjs[note.indexOfAppearance].(*FormXObj).ops), "BT\n"); lines < 2 {
+		t.Errorf("multiline value must be wrapped into lines but got %d", lines)
+	}
+	if !strings.Contains(s, "/FT /Btn\n/T (agree)\n/V /Yes\n/AS /Yes\n") || !strings.Contains(s, "/FT /Btn\n/T (news)\n/V /Off\n/AS /Off\n") {
+		t.Errorf("expect check boxes with state")
+	}
+	if strings.Count(s, "/AP << /N << /Yes ") != 2 {
+		t.Errorf("check box must have appearance of both states")
+	}
+	if !strings.Contains(s, "/FT /Ch\n/T (size)\n/V (M)\n/Ff 131072\n/Opt [ (S) (M) (L) ]\n") {
+		t.Errorf("expect combo box with options")
+	}
+	for _, index := range pdf.indexOfFormFields {
+		for _, appearance := range pdf.pdfObjs[index].(*FormFieldObj).appearances() {
+			if len(pdf.pdfObjs[appearance].(*FormXObj).ops) == 0 {
+				t.Errorf("appearance of field must not be empty")
+			}
+		}
+	}
+	checkXref(t, b)
+}
+
+func TestFormFieldActions(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.AddTextField("a", 50, 100, 100, 20, "1"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	err := pdf.AddTextField("total", 50, 130, 100, 20, "", FormFieldOption{
+		Keystroke: "AFNumber_Keystroke(0, 0, 0, 0, \"\", true);",
+		Calculate: "event.value = this.getField(\"a\").value * 2;",
+	})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(pdf.GetBytesPdf())
+	aa := "/AA << /K << /S /JavaScript /JS (AFNumber_Keystroke\\(0, 0, 0, 0, \"\", true\\);) >>" +
+		" /C << /S /JavaScript /JS (event.value = this.getField\\(\"a\"\\).value * 2;) >> >>\n"
+	if strings.Count(s, "/AA ") != 1 || !strings.Contains(s, aa) {
+		t.Errorf("expect keystroke and calculate actions only on field total")
+	}
+	if !strings.Contains(s, " /CO [") {
+		t.Errorf("field with calculate action must be in calculation order")
+	}
+}
+
+func TestFormFieldTransform(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SaveGraphicsState()
+	pdf.RawContent("1 0 0 1 50 -20 cm")
+	if err := pdf.AddTextField("moved", 10, 10, 100, 20, "x"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SaveGraphicsState()
+	pdf.Scale(2, 2, 0, 0)
+	if err := pdf.AddCheckBox("scaled", 10, 10, 10, true); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.RestoreGraphicsState()
+	pdf.RestoreGraphicsState()
+	if err := pdf.AddTextField("plain", 10, 10, 100, 20, "y"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(pdf.GetBytesPdf())
+	//Rect เดิม 10 811.89 110 831.89 เลื่อนไป 50,-20
+	if !strings.Contains(s, "/T (moved)\n/V (x)\n/Rect [60.00 791.89 160.00 811.89]\n") {
+		t.Errorf("rect of field must be translated\n%s", s)
+	}
+	//scale 2 รอบ 0,841.89 (มุมบนซ้าย) ภายใต้การเลื่อน
+	if !strings.Contains(s, "/Rect [70.00 781.89 90.00 801.89]\n") {
+		t.Errorf("rect of field must be scaled and translated")
+	}
+	if !strings.Contains(s, "/T (plain)\n/V (y)\n/Rect [10.00 811.89 110.00 831.89]\n") {
+		t.Errorf("rect of field after restore must not be transformed")
+	}
+
+	pdf.FlattenForms()
+	s = string(pdf.GetBytesPdf())
+	if !strings.Contains(s, "q\n1.0000 0.0000 0.0000 1.0000 50.00 -20.00 cm\n") {
+		t.Errorf("flattened value must be drawn with transform of field")
+	}
+}
+
+func TestFormFieldsOfKeptPages(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.AddTextField("first", 50, 100, 200, 20, "John"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.AddPage()
+	if err := pdf.AddTextField("second", 50, 100, 200, 20, "Jane"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	b, err := pdf.ExtractPage(2)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(b)
+	if strings.Contains(s, "/T (first)") || strings.Contains(s, "(John)") || strings.Count(s, "/Subtype /Widget\n") != 1 {
+		t.Errorf("fields of other pages must be removed")
+	}
+	if !strings.Contains(s, "/T (second)") || !strings.Contains(s, "/AcroForm << /Fields [") {
+		t.Errorf("fields of page 2 must be kept")
+	}
+	checkXref(t, b)
+
+	pdf.FlattenForms()
+	pdf.SetLinearized(true)
+	b, err = pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if strings.Contains(string(b), "/Widget") || strings.Contains(string(b), "/AcroForm") {
+		t.Errorf("flattened fields must be removed from linearized pdf")
+	}
+}
+
+func TestSetDefaultFont(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.SetDefaultFont("loma", 16)
+	if err := pdf.AddTTFFont("loma", testFontPath(t)); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.AddPage()
+	if _, err := pdf.MeasureTextWidth("default"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Cell(nil, "default")
+	if !strings.Contains(pdf.getContent().stream.String(), "/F1 16 Tf\n") {
+		t.Errorf("text must be drawn with default font")
+	}
+}
+
+func TestImageDedupByContent(t *testing.T) {
+	pdf := newTestPdf(t)
+	logo := testImagePath(t, 32, 32, color.RGBA{B: 255, A: 255})
+	copyOfLogo := testImagePath(t, 32, 32, color.RGBA{B: 255, A: 255})
+	if logo == copyOfLogo {
+		t.Fatalf("images must be in different files")
+	}
+	pdf.SetPageTemplate(func() {
+		pdf.Image(logo, 10, 10, nil)
+	})
+	pdf.AddPage()
+	pdf.Image(copyOfLogo, 100, 100, nil)
+	if err := pdf.ImageTiled(copyOfLogo, 100, 200, 100, 100, 20, 20); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(pdf.GetBytesPdf())
+	if n := strings.Count(s, "/Subtype /Image"); n != 1 {
+		t.Errorf("expect 1 image obj but got %d", n)
+	}
+}
+
+func TestRoundedRectExt(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.RoundedRectExt(50, 100, 200, 80, 10, 10, 0, 0, "X"); err != ErrUnknownPaintStyle {
+		t.Errorf("expect ErrUnknownPaintStyle but got %v", err)
+	}
+	if err := pdf.RoundedRectExt(50, 100, 200, 80, 10, 10, 0, 0, "F"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := pdf.getContent().stream.String()
+	if n := strings.Count(s, " c\n"); n != 2 {
+		t.Errorf("expect 2 curves but got %d", n)
+	}
+	//มุมล่างเป็นมุมฉาก
+	for _, corner := range []string{"250.00 661.89 l\n", "50.00 661.89 l\n"} {
+		if !strings.Contains(s, corner) {
+			t.Errorf("sharp corner %q not found", corner)
+		}
+	}
+	if !strings.HasPrefix(s, "60.00 741.89 m\n") || !strings.HasSuffix(s, "h f\n") {
+		t.Errorf("unexpected path %q", s)
+	}
+
+	//radius ที่รวมกันเกินความกว้างถูกย่อ
+	pdf = newTestPdf(t)
+	pdf.RoundedRectExt(0, 0, 100, 300, 80, 80, 0, 0, "D")
+	if s := pdf.getContent().stream.String(); !strings.HasPrefix(s, "50.00 841.89 m\n50.00 841.89 l\n") {
+		t.Errorf("radii must be clamped to half of width but got %q", s)
+	}
+}
+
+func TestSetPostProcessor(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.Cell(nil, "post")
+	var offsets []int
+	pdf.SetPostProcessor(func(b []byte) ([]byte, error) {
+		offsets = pdf.XrefOffsets()
+		return append(b, "%processed\n"...), nil
+	})
+	b := pdf.GetBytesPdf()
+	if !bytes.HasSuffix(b, []byte("%processed\n")) {
+		t.Errorf("comment of post processor not found")
+	}
+	if len(offsets) != len(pdf.pdfObjs) || !bytes.HasPrefix(b[offsets[0]:], []byte("1 0 obj\n")) {
+		t.Errorf("offset of obj 1 must point to obj 1")
+	}
+
+	pdf.SetPostProcessor(func(b []byte) ([]byte, error) {
+		return nil, ErrPageOutOfRange
+	})
+	if _, err := pdf.GetBytesPdfReturnErr(); err != ErrPageOutOfRange {
+		t.Errorf("expect error of post processor but got %v", err)
+	}
+}
+
+func TestDrawLeader(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.DrawLeader(100, 300, 50, 0); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	dotW, _ := pdf.MeasureTextWidth(".")
+	spaceW, _ := pdf.MeasureTextWidth(" ")
+	n := int((200 - 2*spaceW) / dotW)
+	leader, _ := pdf.MeasureTextWidth(strings.Repeat(".", n))
+	if n < 1 || leader > 200-2*spaceW || leader+dotW <= 200-2*spaceW {
+		t.Fatalf("%d leaders must fill the width", n)
+	}
+	xs := tableTextXs(t, pdf.getContent().stream.String())
+	if len(xs) != 1 || math.Abs(xs[0]-(300-spaceW-leader)) > 0.01 {
+		t.Errorf("leader must end one space before x1 but start at %v", xs)
+	}
+	if pdf.GetX() != 300 {
+		t.Errorf("current x must be moved to x1")
+	}
+}
+
+func TestParagraphInBoxJustifyType0(t *testing.T) {
+	pdf := newTestPdf(t)
+	box := Rect{W: 150, H: 100}
+	text := "justify words of this long line with a Type0 font"
+	lines, err := pdf.splitTextToLines(text, box.W)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	lineW, _ := pdf.MeasureTextWidth(lines[0])
+	if _, err := pdf.ParagraphInBox(box, text, "justify", "top"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := pdf.getContent().stream.String()
+	if strings.Contains(s, " Tw\n") {
+		t.Errorf("Tw must not be used with Type0 font")
+	}
+	//ผลรวมของ TJ adjustment ของบรรทัดแรกเท่ากับที่ต้องขยาย
+	first := s[strings.Index(s, "[<"):strings.Index(s, "] TJ")]
+	stretch := 0.0
+	for _, match := range regexp.MustCompile(`> (-?\d+\.\d+) <`).FindAllStringSubmatch(first, -1) {
+		adjustment, _ := strconv.ParseFloat(match[1], 64)
+		stretch -= adjustment * float64(pdf.Curr.Font_Size) / 1000
+	}
+	if math.Abs(stretch-(box.W-lineW)) > 0.05 {
+		t.Errorf("expect TJ stretch %f but got %f", box.W-lineW, stretch)
+	}
+}
+
+func TestCanRender(t *testing.T) {
+	pdf := newTestPdf(t)
+	if !pdf.CanRender('ก') || pdf.CanRender('Ö') {
+		t.Fatalf("loma has glyph of ก but not Ö")
+	}
+	if err := pdf.AddTTFFont("sarabun", testFontPathOf(t, "THSarabunNew")); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetScriptFont("Latin", "sarabun")
+	if !pdf.CanRender('Ö') {
+		t.Errorf("Ö must be rendered by fallback font")
+	}
+	if pdf.CanRender('😀') {
+		t.Errorf("😀 is not in any font")
+	}
+	if missing := pdf.MissingRunes("Ö ก😀x😀"); !reflect.DeepEqual(missing, []rune{'😀'}) {
+		t.Errorf("expect missing [😀] but got %q", missing)
+	}
+
+	//SmartWrite วาด Ö ด้วย font ของ Latin (loma) ไม่ใช่ font ปัจจุบัน (sarabun)
+	if err := pdf.SetFont("sarabun", "", 14); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetScriptFont("Latin", "loma")
+	if pdf.CanRender('Ö') {
+		t.Errorf("Ö is drawn with loma that does not have it")
+	}
+	if missing := pdf.MissingRunes("xÖ ก"); !reflect.DeepEqual(missing, []rune{'Ö'}) {
+		t.Errorf("expect missing [Ö] but got %q", missing)
+	}
+	if pdf.Curr.Font_ISubset != pdf.findSubsetFont("sarabun") {
+		t.Errorf("current font must not be changed")
+	}
+}
+
+func TestHairline(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SaveGraphicsState()
+	pdf.Scale(2, 2, 0, 0)
+	pdf.SetLineWidth(0)
+	pdf.Line(10, 10, 100, 10)
+	pdf.SetLineWidth(0.001)
+	pdf.RestoreGraphicsState()
+	expected := "q\n2.0000 0 0 2.0000 0.00 -841.89 cm\n0.00 w\n10.00 831.89 m 100.00 831.89 l s\n0.001 w\nQ\n"
+	if s := pdf.getContent().stream.String(); !strings.HasSuffix(s, expected) {
+		t.Errorf("expect %q but got %q", expected, s)
+	}
+}
+
+func TestPreferSimpleFont(t *testing.T) {
+	for _, text := range []string{"Hello", "Hello สวัสดี"} {
+		pdf := GoPdf{}
+		pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+		pdf.AddPage()
+		if err := pdf.AddTTFFontWithOption("loma", testFontPath(t), FontOption{PreferSimple: true}); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if err := pdf.SetFont("loma", "", 14); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		pdf.Cell(nil, text)
+		b := pdf.GetBytesPdf()
+		s := string(b)
+		simple := strings.Contains(s, "/Subtype /TrueType\n")
+		if text == "Hello" {
+			if !simple || strings.Contains(s, "/Type0") || strings.Contains(s, "/CIDFontType2") {
+				t.Errorf("latin text must use simple TrueType font")
+			}
+			if !strings.Contains(s, "<48656C6C6F> Tj") || !strings.Contains(s, "/Encoding /WinAnsiEncoding") {
+				t.Errorf("text must be written in 1 byte codes of WinAnsiEncoding")
+			}
+		} else if simple || !strings.Contains(s, "/Subtype /Type0") {
+			t.Errorf("thai text must use Type0 font")
+		}
+		checkXref(t, b)
+
+		pdf.SetLinearized(true)
+		s = string(pdf.GetBytesPdf())
+		if text == "Hello" && (!strings.Contains(s, "/Subtype /TrueType\n") || strings.Contains(s, "/CIDFontType2")) {
+			t.Errorf("linearized pdf must use simple TrueType font without CIDFont")
+		}
+	}
+}
+
+func TestMeasureCache(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetMeasureCacheSize(2)
+	for _, text := range []string{"a", "b", "a", "c"} {
+		if _, err := pdf.MeasureTextWidth(text); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+	key := measureKey{font: pdf.Curr.Font_ISubset, size: 14, spaceFactor: 1}
+	var kept []string
+	for _, text := range []string{"a", "b", "c"} {
+		key.text = text
+		if _, ok := pdf.measureCache.entries[key]; ok {
+			kept = append(kept, text)
+		}
+	}
+	if !reflect.DeepEqual(kept, []string{"a", "c"}) {
+		t.Errorf("expect least recently used b removed but got %q", kept)
+	}
+
+	uncached, _ := pdf.measureTextWidth("hello world")
+	pdf.MeasureTextWidth("hello world")
+	pdf.SetSpaceWidthFactor(2)
+	if w, _ := pdf.MeasureTextWidth("hello world"); w == uncached {
+		t.Errorf("width with other space factor must not come from cache")
+	}
+	pdf.ClearMeasureCache()
+	if pdf.measureCache != nil {
+		t.Errorf("cache must be cleared")
+	}
+}
+
+func BenchmarkMeasureTextWidth(b *testing.B) {
+	for _, size := range []int{0, defaultMeasureCacheSize} {
+		b.Run(fmt.Sprintf("cache%d", size), func(b *testing.B) {
+			pdf := GoPdf{}
+			pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+			if err := pdf.AddTTFFont("loma", testFontPath(b)); err != nil {
+				b.Fatalf("%s", err.Error())
+			}
+			pdf.SetFont("loma", "", 14)
+			pdf.SetMeasureCacheSize(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				pdf.MeasureTextWidth("Amount (THB)")
+			}
+		})
+	}
+}
+
+func TestStar(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.Star(100, 100, 50, 20, 5, 0, "F"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := pdf.getContent().stream.String()
+	if n := strings.Count(s, " m\n") + strings.Count(s, " l\n"); n != 10 || !strings.HasSuffix(s, "h f\n") {
+		t.Errorf("expect 10 vertices but got %d", n)
+	}
+	if !strings.Contains(s, "100.00 791.89 m\n") {
+		t.Errorf("first point must be at top of star")
+	}
+	if err := pdf.Star(100, 100, 50, 20, 1, 0, "F"); err != ErrTooFewSides {
+		t.Errorf("expect ErrTooFewSides but got %v", err)
+	}
+	if err := pdf.RegularPolygon(100, 100, 50, 2, 0, "D"); err != ErrTooFewSides {
+		t.Errorf("expect ErrTooFewSides but got %v", err)
+	}
+	if err := pdf.RegularPolygon(100, 100, 50, 6, 30, "X"); err != ErrUnknownPaintStyle {
+		t.Errorf("expect ErrUnknownPaintStyle but got %v", err)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	pdf := newTestPdf(t)
+	if pdf.Objects() != nil {
+		t.Errorf("objects must be nil before pdf is built")
+	}
+	pdf.Cell(nil, "hello")
+	b := pdf.GetBytesPdf()
+	counts := make(map[string]int)
+	offsets := pdf.XrefOffsets()
+	for _, info := range pdf.Objects() {
+		counts[info.Type+"/"+info.Subtype]++
+		obj := string(b[offsets[info.Number-1] : offsets[info.Number-1]+info.Length])
+		if !strings.HasPrefix(obj, fmt.Sprintf("%d 0 obj\n", info.Number)) || !strings.HasSuffix(obj, "endobj") {
+			t.Errorf("length of obj %d is wrong", info.Number)
+		}
+	}
+	expected := map[string]int{"PdfDictionary/": 1, "SubsetFont/Type0": 1, "CIDFont/CIDFontType2": 1, "Page/": 1, "Content/": 1, "Catalog/": 1}
+	for key, n := range expected {
+		if counts[key] != n {
+			t.Errorf("expect %d %s but got %d", n, key, counts[key])
+		}
+	}
+}
+
+func TestLineHeightUseTypoMetrics(t *testing.T) {
+	pdf := newTestPdf(t)
+	ttfp := pdf.Curr.Font_ISubset.(*SubsetFontObj).GetTTFParser()
+	normal := pdf.autoLineHeight()
+
+	//font เดียวกันที่ตั้ง USE_TYPO_METRICS (bit 7 ของ fsSelection)
+	b, err := ioutil.ReadFile(testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	b[ttfp.GetTables()["OS/2"].Offset+63] |= 0x80
+	path := filepath.Join(t.TempDir(), "typo.ttf")
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.AddTTFFont("typo", path); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetFont("typo", "", 14)
+	sub := pdf.Curr.Font_ISubset.(*SubsetFontObj)
+	expected := sub.scaleToPDF(ttfp.TypoAscender()-ttfp.TypoDescender()+ttfp.TypoLineGap()) * 14 / 1000
+	if typo := pdf.autoLineHeight(); math.Abs(typo-expected) > 0.001 || typo == normal {
+		t.Errorf("expect line height %f from typo metrics but got %f (without flag %f)", expected, typo, normal)
+	}
+}
+
+func TestDrawSVGPath(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.DrawSVGPath("M10 20 L30 40", 100, 100, 2, ""); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if s := pdf.getContent().stream.String(); !strings.HasSuffix(s, "120.00 701.89 m\n160.00 661.89 l\nS\n") {
+		t.Errorf("expect move and line of path but got %q", s)
+	}
+
+	//relative , implicit lineto , quadratic and arc
+	if err := pdf.DrawSVGPath("m0 0 10,0 10-10q5 5 10 0a5 5 0 0 1 10 0z", 0, 0, 1, "F"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := pdf.getContent().stream.String()
+	if !strings.Contains(s, "10.00 841.89 l\n20.00 851.89 l\n") || !strings.Contains(s, "30.00 851.89 c\n") || !strings.HasSuffix(s, "40.00 851.89 c\nh\nf\n") {
+		t.Errorf("wrong path %q", s)
+	}
+
+	if err := pdf.DrawSVGPath("M0 0 S1 1 2 2", 0, 0, 1, ""); !errors.Is(err, ErrUnsupportedSVGCommand) {
+		t.Errorf("expect ErrUnsupportedSVGCommand but got %v", err)
+	}
+	if err := pdf.DrawSVGPath("L1 1", 0, 0, 1, ""); err != ErrInvalidSVGPath {
+		t.Errorf("expect ErrInvalidSVGPath but got %v", err)
+	}
+	before := pdf.getContent().stream.Len()
+	for _, d := range []string{"", " , "} {
+		if err := pdf.DrawSVGPath(d, 0, 0, 1, ""); err != ErrInvalidSVGPath {
+			t.Errorf("expect ErrInvalidSVGPath of empty path %q but got %v", d, err)
+		}
+	}
+	if pdf.getContent().stream.Len() != before {
+		t.Errorf("empty path must not be painted")
+	}
+}
+
+func TestImageFilter(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range m.Pix {
+		m.Pix[i] = byte(i)
+	}
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, m); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), "img.png")
+	if err := ioutil.WriteFile(path, buff.Bytes(), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	pdf := newTestPdf(t)
+	pdf.Image(path, 10, 10, nil)
+	pdf.Image(path, 10, 100, nil, ImageOption{Filter: "dct", Quality: 80})
+	s := string(pdf.GetBytesPdf())
+	if strings.Count(s, "/Subtype /Image\n") != 2 {
+		t.Fatalf("expect one image obj for each filter")
+	}
+	if strings.Count(s, "/Filter /DCTDecode\n") != 1 || !strings.Contains(s, "/ColorSpace /DeviceRGB\n/BitsPerComponent 8\n/Filter /FlateDecode\n") {
+		t.Errorf("expect png as FlateDecode by default and DCTDecode when forced")
+	}
+	checkXref(t, []byte(s))
+
+	pdf = newTestPdf(t)
+	if err := pdf.ImageReturnErr(path, 10, 10, nil, ImageOption{Filter: "lzw"}); err != ErrUnknownImageFilter {
+		t.Errorf("expect ErrUnknownImageFilter but got %v", err)
+	}
+	if err := pdf.ImageFit(path, Rect{W: 50, H: 50}, "", ImageOption{Filter: "lzw"}); err != ErrUnknownImageFilter {
+		t.Errorf("expect ErrUnknownImageFilter but got %v", err)
+	}
+	pdf.Image(path, 10, 10, nil, ImageOption{Filter: "lzw"})
+	if _, err := pdf.GetBytesPdfReturnErr(); err != nil {
+		t.Errorf("image with invalid option must not be added but got %v", err)
+	}
+}
+
+func TestParagraphSpacing(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetY(100)
+	pdf.SetParagraphSpacing(6, 4)
+	if err := pdf.MultiCell(200, 20, "one\n\ntwo"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if y := pdf.GetY(); math.Abs(y-(100+2*(6+20+4))) > 0.001 {
+		t.Errorf("expect y after two paragraphs %f but got %f", 100.0+2*(6+20+4), y)
+	}
+	matches := regexp.MustCompile(`[\d.]+ ([\d.]+) TD\n`).FindAllStringSubmatch(pdf.getContent().stream.String(), -1)
+	if len(matches) != 2 {
+		t.Fatalf("expect 2 lines but got %d", len(matches))
+	}
+	first, _ := strconv.ParseFloat(matches[0][1], 64)
+	second, _ := strconv.ParseFloat(matches[1][1], 64)
+	//ระยะระหว่างย่อหน้า = บรรทัด + after + before
+	if gap := first - second - 20; math.Abs(gap-10) > 0.011 {
+		t.Errorf("expect gap between paragraphs 10 but got %f", gap)
+	}
+}
+
+func TestSubsetGasp(t *testing.T) {
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	if err := pdf.AddTTFFont("sarabun", testFontPathOf(t, "THSarabunNew")); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetFont("sarabun", "", 14)
+	pdf.Cell(nil, "gasp")
+	sub := pdf.findSubsetFont("sarabun")
+	b, err := (&PdfDictionaryObj{PtrToSubsetFontObj: sub}).makeFont()
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	ttfp := sub.GetTTFParser()
+	gasp := ttfp.GetTables()["gasp"]
+	original := ttfp.FontData()[gasp.Offset : gasp.Offset+gasp.Length]
+	numTables := int(b[4])<<8 | int(b[5])
+	for i := 0; i < numTables; i++ {
+		entry := b[12+i*16 : 28+i*16]
+		if string(entry[:4]) != "gasp" {
+			continue
+		}
+		offset := int(entry[8])<<24 | int(entry[9])<<16 | int(entry[10])<<8 | int(entry[11])
+		length := int(entry[12])<<24 | int(entry[13])<<16 | int(entry[14])<<8 | int(entry[15])
+		if !bytes.Equal(b[offset:offset+length], original) {
+			t.Errorf("gasp table of subset must be same as font")
+		}
+		return
+	}
+	t.Errorf("subset has no gasp table")
+}
+
+func TestAddLayer(t *testing.T) {
+	pdf := newTestPdf(t)
+	if _, err := pdf.AddLayer("Bad", "on", ""); err != ErrInvalidLayerState {
+		t.Errorf("expect ErrInvalidLayerState but got %v", err)
+	}
+	layer, err := pdf.AddLayer("Print only", "ON", "OFF")
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.BeginLayer(layer)
+	pdf.Cell(nil, "PRINT ONLY")
+	pdf.EndLayer()
+	if stream := pdf.getContent().stream.String(); !strings.HasPrefix(stream, "/OC /OC1 BDC\n") || !strings.HasSuffix(stream, "EMC\n") {
+		t.Errorf("content must be marked as optional content\n%s", stream)
+	}
+	b := pdf.GetBytesPdf()
+	s := string(b)
+	if !strings.Contains(s, "/Name (Print only)\n/Usage << /Print << /PrintState /ON >> /View << /ViewState /OFF >> >>") {
+		t.Errorf("layer must be printed and not shown")
+	}
+	ref := ""
+	for i, obj := range pdf.pdfObjs {
+		if _, ok := obj.(*OCGObj); ok {
+			ref = fmt.Sprintf("%d 0 R", i+1)
+		}
+	}
+	if !strings.Contains(s, "/Order [ "+ref+" ] /OFF [ "+ref+" ]") || !strings.Contains(s, "/OC1 "+ref) {
+		t.Errorf("layer must be off by default and in page resources")
+	}
+	checkXref(t, b)
+}
+
+func TestSignatureLine(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetX(30)
+	pdf.SetY(40)
+	if err := pdf.SignatureLine(50, 700, 200, "Signature", SignatureLineOption{DateWidth: 100}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if pdf.GetX() != 30 || pdf.GetY() != 40 || pdf.Curr.Font_Size != 14 {
+		t.Errorf("current position and font size must not be changed")
+	}
+	stream := pdf.getContent().stream.String()
+	if !strings.Contains(stream, "50.00 141.89 m 250.00 141.89 l s\n") || !strings.Contains(stream, "270.00 141.89 m 370.00 141.89 l s\n") {
+		t.Errorf("expect signature and date rule\n%s", stream)
+	}
+	if strings.Count(stream, "/F1 9 Tf\n") != 2 {
+		t.Errorf("labels must be drawn in smaller font\n%s", stream)
+	}
+	for _, label := range []string{"Signature", "Date"} {
+		op, _ := pdf.getContent().subsetFontTextOperator(label)
+		if !strings.Contains(stream, op) {
+			t.Errorf("label %q not found", label)
+		}
+	}
+}
+
+func TestSetHyphenation(t *testing.T) {
+	pdf := newTestPdf(t)
+	if lines, _, _ := pdf.WrapText("The hyphenation", 83); !reflect.DeepEqual(lines, []string{"The", "hyphenation"}) {
+		t.Errorf("expect break at space without hyphenation but got %q", lines)
+	}
+	patterns := HyphenationPatterns{Patterns: []string{"hy3ph", "he2n", "hena4", "hen5at", "1na", "n2at", "1tio", "2io", "o2n"}}
+	if points := newHyphenator(patterns).points([]rune("Hyphenation")); !reflect.DeepEqual(points, []int{2, 6}) {
+		t.Errorf("expect hy-phen-ation but got %v", points)
+	}
+	pdf.SetHyphenation(patterns)
+	if lines, _, _ := pdf.WrapText("The hyphenation", 83); !reflect.DeepEqual(lines, []string{"The hyphen-", "ation"}) {
+		t.Errorf("expect break at last point that fits but got %q", lines)
+	}
+	patterns.Exceptions = []string{"hy-phenation"}
+	pdf.SetHyphenation(patterns)
+	if lines, _, _ := pdf.WrapText("The hyphenation.", 83); !reflect.DeepEqual(lines, []string{"The hy-", "phenation."}) {
+		t.Errorf("expect break of exception but got %q", lines)
+	}
+	pdf.SetHyphenation(HyphenationPatterns{})
+	if lines, _, _ := pdf.WrapText("The hyphenation", 83); len(lines) != 2 || lines[0] != "The" {
+		t.Errorf("hyphenation must be turned off but got %q", lines)
+	}
+}
+
+//addTestFontTables : ttf b with tables added at end of file (tables of b are moved by size of new directory entries)
+func addTestFontTables(b []byte, tables map[string][]byte) []byte {
+	numTables := int(binary.BigEndian.Uint16(b[4:6]))
+	shift := uint32(16 * len(tables))
+	var out bytes.Buffer
+	out.Write(b[0:4])
+	binary.Write(&out, binary.BigEndian, uint16(numTables+len(tables)))
+	out.Write(b[6:12])
+	for i := 0; i < numTables; i++ {
+		entry := b[12+i*16 : 28+i*16]
+		out.Write(entry[:8])
+		binary.Write(&out, binary.BigEndian, binary.BigEndian.Uint32(entry[8:12])+shift)
+		out.Write(entry[12:16])
+	}
+	var data bytes.Buffer
+	offset := uint32(len(b)) + shift
+	for offset%4 != 0 {
+		data.WriteByte(0)
+		offset++
+	}
+	var tags []string
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		table := tables[tag]
+		out.WriteString(tag)
+		binary.Write(&out, binary.BigEndian, uint32(0))
+		binary.Write(&out, binary.BigEndian, offset+uint32(data.Len()))
+		binary.Write(&out, binary.BigEndian, uint32(len(table)))
+		data.Write(table)
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+	}
+	out.Write(b[12+numTables*16:])
+	out.Write(data.Bytes())
+	return out.Bytes()
+}
+
+func TestColorGlyph(t *testing.T) {
+	b, err := ioutil.ReadFile(testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	var parser core.TTFParser
+	if err := parser.ParseByReader(bytes.NewReader(b)); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	glyphOf := func(r rune) uint16 {
+		return uint16(parser.Chars()[int(r)])
+	}
+	//'A' มี 3 layer : 'B' สีแดง , 'C' สีน้ำเงิน และ 'D' สีของข้อความ
+	var colr bytes.Buffer
+	for _, v := range []interface{}{
+		uint16(0), uint16(1), uint32(14), uint32(20), uint16(3),
+		glyphOf('A'), uint16(0), uint16(3),
+		glyphOf('B'), uint16(0), glyphOf('C'), uint16(1), glyphOf('D'), uint16(core.ForegroundPaletteIndex),
+	} {
+		binary.Write(&colr, binary.BigEndian, v)
+	}
+	cpal := []byte{0, 0, 0, 2, 0, 1, 0, 2, 0, 0, 0, 14, 0, 0, 0, 0, 255, 255, 255, 0, 0, 255}
+	path := filepath.Join(t.TempDir(), "color.ttf")
+	if err := ioutil.WriteFile(path, addTestFontTables(b, map[string][]byte{"COLR": colr.Bytes(), "CPAL": cpal}), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	if err := pdf.AddTTFFont("color", path); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.SetFont("color", "", 14)
+	if layers := pdf.findSubsetFont("color").GetTTFParser().ColorLayers(uint64(glyphOf('B'))); layers != nil {
+		t.Errorf("B is not color glyph")
+	}
+	width, _ := pdf.MeasureTextWidth("xAx")
+	x := pdf.GetX()
+	pdf.Cell(nil, "xAx")
+	if math.Abs(pdf.GetX()-x-width) > 0.01 {
+		t.Errorf("expect x after text %f but got %f", x+width, pdf.GetX())
+	}
+	stream := pdf.getContent().stream.String()
+	layer := func(color string, r rune) string {
+		return fmt.Sprintf("q\n%sBT\n[\\d.]+ [\\d.]+ TD\n/F1 14 Tf\n<%04X> Tj\nET\nQ\n", color, glyphOf(r))
+	}
+	re := regexp.MustCompile("> Tj\nET\n" + layer("1\\.000 0\\.000 0\\.000 rg\n", 'B') + layer("0\\.000 0\\.000 1\\.000 rg\n", 'C') + layer("", 'D') + "BT\n")
+	if !re.MatchString(stream) {
+		t.Errorf("expect layers of color glyph in red , blue and text color\n%s", stream)
+	}
+	b = pdf.GetBytesPdf()
+	checkXref(t, b)
+
+	//font ที่ไม่มี COLR วาดเป็นสีเดียว
+	mono := newTestPdf(t)
+	mono.Cell(nil, "xAx")
+	if stream := mono.getContent().stream.String(); strings.Count(stream, "BT\n") != 1 {
+		t.Errorf("glyph of font without COLR must be drawn as usual\n%s", stream)
+	}
+}
@@ -0,0 +1,37 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//grayscaleContentStream : replace rg/RG/k/K color operators in content stream with g/G
+func grayscaleContentStream(stream []byte) []byte {
+	var buff bytes.Buffer
+	copied := 0
+	walkContentStream(stream, func(op string, operands []float64, operandStart int, end int) {
+		gray := -1.0
+		if (op == "rg" || op == "RG") && len(operands) == 3 {
+			gray = luminance(operands[0], operands[1], operands[2])
+		} else if (op == "k" || op == "K") && len(operands) == 4 {
+			k := operands[3]
+			gray = luminance((1-operands[0])*(1-k), (1-operands[1])*(1-k), (1-operands[2])*(1-k))
+		}
+		if gray < 0 {
+			return
+		}
+		grayOp := "g"
+		if op == "RG" || op == "K" {
+			grayOp = "G"
+		}
+		buff.Write(stream[copied:operandStart])
+		buff.WriteString(fmt.Sprintf("%.3f %s", fixRange10(gray), grayOp))
+		copied = end
+	})
+	buff.Write(stream[copied:])
+	return buff.Bytes()
+}
+
+func luminance(r float64, g float64, b float64) float64 {
+	return 0.299*r + 0.587*g + 0.114*b
+}
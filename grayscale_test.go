@@ -0,0 +1,61 @@
+package gopdf
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//testImagePath : create w x h jpeg filled with c
+func testImagePath(t *testing.T, w int, h int, c color.Color) string {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			m.Set(x, y, c)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "img.jpg")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	defer f.Close()
+	err = jpeg.Encode(f, m, nil)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return path
+}
+
+func TestGrayscaleOutput(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetFillColor(255, 0, 0)
+	pdf.SetStrokeColor(0, 0, 255)
+	pdf.Cell(nil, "red")
+	pdf.Line(10, 10, 100, 10)
+	pdf.getContent().stream.WriteString("0 0 0 1 k\n1 0 0 0 K\nBT (1 0 0 rg) Tj ET\n")
+	pdf.Image(testImagePath(t, 8, 8, color.RGBA{R: 255, A: 255}), 10, 100, nil)
+
+	colored := string(pdf.GetBytesPdf())
+	if !strings.Contains(colored, "1.000 0.000 0.000 rg\n") || !strings.Contains(colored, "/DeviceRGB") {
+		t.Fatalf("expect colored document")
+	}
+
+	pdf.SetGrayscaleOutput(true)
+	gray := string(pdf.GetBytesPdf())
+	for _, op := range []string{" rg\n", " RG\n", " k\n", " K\n", "/DeviceRGB"} {
+		if strings.Contains(gray, op) {
+			t.Errorf("%q must not remain in grayscale output", op)
+		}
+	}
+	for _, op := range []string{"0.299 g\n", "0.114 G\n", "0.000 g\n", "0.701 G\n", "(1 0 0 rg) Tj", "/DeviceGray"} {
+		if !strings.Contains(gray, op) {
+			t.Errorf("%q not found in grayscale output", op)
+		}
+	}
+	checkXref(t, []byte(gray))
+}
@@ -0,0 +1,148 @@
+package gopdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+//HyphenationPatterns : Liang (TeX) hyphenation patterns of a language , see SetHyphenation
+type HyphenationPatterns struct {
+	//Patterns : patterns like "hy3ph" , ".ach4" , "1tio" (digit is priority of break at its position , odd = break allowed ,
+	//"." = start or end of word)
+	Patterns []string
+	//Exceptions : words with every allowed break marked by "-" (sample "ta-ble") , used instead of patterns
+	Exceptions []string
+	//LeftMin , RightMin : min letters before and after break (0 = 2 and 3)
+	LeftMin  int
+	RightMin int
+}
+
+//hyphenator : compiled HyphenationPatterns
+type hyphenator struct {
+	//values : priorities of pattern by its letters
+	values     map[string][]int
+	exceptions map[string][]int
+	leftMin    int
+	rightMin   int
+	maxLen     int
+}
+
+func newHyphenator(patterns HyphenationPatterns) *hyphenator {
+	h := &hyphenator{
+		values:     make(map[string][]int),
+		exceptions: make(map[string][]int),
+		leftMin:    patterns.LeftMin,
+		rightMin:   patterns.RightMin,
+	}
+	if h.leftMin <= 0 {
+		h.leftMin = 2
+	}
+	if h.rightMin <= 0 {
+		h.rightMin = 3
+	}
+	for _, pattern := range patterns.Patterns {
+		var letters []rune
+		values := []int{0}
+		for _, r := range pattern {
+			if r >= '0' && r <= '9' {
+				values[len(values)-1] = int(r - '0')
+				continue
+			}
+			letters = append(letters, unicode.ToLower(r))
+			values = append(values, 0)
+		}
+		if len(letters) == 0 {
+			continue
+		}
+		h.values[string(letters)] = values
+		if len(letters) > h.maxLen {
+			h.maxLen = len(letters)
+		}
+	}
+	for _, exception := range patterns.Exceptions {
+		var points []int
+		n := 0
+		for _, r := range exception {
+			if r == '-' {
+				points = append(points, n)
+				continue
+			}
+			n++
+		}
+		h.exceptions[strings.ToLower(strings.Replace(exception, "-", "", -1))] = points
+	}
+	return h
+}
+
+//points : positions (index of rune) in word where it can be broken with hyphen , in ascending order
+func (h *hyphenator) points(word []rune) []int {
+	lower := strings.ToLower(string(word))
+	if points, ok := h.exceptions[lower]; ok {
+		return points
+	}
+	w := append(append([]rune{'.'}, []rune(lower)...), '.')
+	priorities := make([]int, len(w)+1)
+	for i := range w {
+		for j := i + 1; j <= len(w) && j-i <= h.maxLen; j++ {
+			values, ok := h.values[string(w[i:j])]
+			if !ok {
+				continue
+			}
+			for k, v := range values {
+				if v > priorities[i+k] {
+					priorities[i+k] = v
+				}
+			}
+		}
+	}
+	var points []int
+	for p := h.leftMin; p <= len(word)-h.rightMin; p++ {
+		//ตำแหน่งก่อน rune p ของคำคือก่อน w[p+1] เพราะมี "." นำหน้า
+		if priorities[p+1]%2 == 1 {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+//SetHyphenation : words that do not fit at end of line are broken at break points of patterns with hyphen ("-") added
+//(MultiCell , WrapText , ParagraphInBox) , HyphenationPatterns{} = no hyphenation
+func (gp *GoPdf) SetHyphenation(patterns HyphenationPatterns) {
+	if len(patterns.Patterns) == 0 && len(patterns.Exceptions) == 0 {
+		gp.hyphenator = nil
+		return
+	}
+	gp.hyphenator = newHyphenator(patterns)
+}
+
+//hyphenateLine : end of line (index of rune after break) when word that has rune overflow (first rune that does not fit)
+//is broken with hyphen , false if no break point of word fits width
+func (gp *GoPdf) hyphenateLine(runes []rune, wordStart int, overflow int, width float64) (int, bool, error) {
+	wordEnd := overflow
+	for wordEnd < len(runes) && runes[wordEnd] != ' ' {
+		wordEnd++
+	}
+	//ไม่ตัดตัวอักษรที่ไม่ใช่ตัวอักษรหัวและท้ายคำ (sample วงเล็บ , จุด)
+	letterStart, letterEnd := wordStart, wordEnd
+	for letterStart < letterEnd && !unicode.IsLetter(runes[letterStart]) {
+		letterStart++
+	}
+	for letterEnd > letterStart && !unicode.IsLetter(runes[letterEnd-1]) {
+		letterEnd--
+	}
+	points := gp.hyphenator.points(runes[letterStart:letterEnd])
+	for i := len(points) - 1; i >= 0; i-- {
+		end := letterStart + points[i]
+		if end > overflow {
+			continue
+		}
+		w, err := gp.MeasureTextWidth(string(runes[:end]) + "-")
+		if err != nil {
+			return 0, false, err
+		}
+		if w <= width {
+			return end, true, nil
+		}
+	}
+	return 0, false, nil
+}
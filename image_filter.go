@@ -0,0 +1,115 @@
+package gopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+)
+
+//ErrUnknownImageFilter : Filter of ImageOption is not "auto" , "flate" or "dct"
+var ErrUnknownImageFilter = errors.New("unknown image filter")
+
+//ImageOption : option of Image , ImageReturnErr and ImageFit
+type ImageOption struct {
+	//Filter : "auto" (default "" , jpeg is embedded as is with DCTDecode , other formats with FlateDecode) ,
+	//"flate" (lossless FlateDecode) or "dct" (jpeg , other formats are re-encoded with Quality)
+	Filter string
+	//Quality : jpeg quality (1-100) of re-encoded image of "dct" (0 = 90)
+	Quality int
+}
+
+//cacheKey : key of image with this option (image with other filter is other obj)
+func (o ImageOption) cacheKey(key string) string {
+	if o.Filter == "" || o.Filter == "auto" {
+		return key
+	}
+	return fmt.Sprintf("%s#%s%d", key, o.Filter, o.quality())
+}
+
+//validate : ErrUnknownImageFilter if Filter is unknown
+func (o ImageOption) validate() error {
+	switch o.Filter {
+	case "", "auto", "flate", "dct":
+		return nil
+	}
+	return ErrUnknownImageFilter
+}
+
+func (o ImageOption) quality() int {
+	if o.Quality <= 0 || o.Quality > 100 {
+		return 90
+	}
+	return o.Quality
+}
+
+//encodeImage : data , filter and color space of image m (format and raw file bytes are from image.Decode) ,
+//gray = convert to DeviceGray (SetGrayscaleOutput)
+func (o ImageOption) encodeImage(m image.Image, format string, raw []byte, gray bool) ([]byte, string, string, error) {
+	filter := o.Filter
+	switch filter {
+	case "", "auto":
+		filter = "flate"
+		if format == "jpeg" {
+			filter = "dct"
+		}
+	case "flate", "dct":
+	default:
+		return nil, "", "", ErrUnknownImageFilter
+	}
+
+	colorSpace := "DeviceRGB"
+	if gray {
+		colorSpace = "DeviceGray"
+	}
+	if filter == "dct" {
+		if format == "jpeg" && !gray {
+			return raw, "DCTDecode", colorSpace, nil
+		}
+		var src image.Image = m
+		if gray {
+			g := image.NewGray(m.Bounds())
+			draw.Draw(g, g.Bounds(), m, m.Bounds().Min, draw.Src)
+			src = g
+		}
+		var buff bytes.Buffer
+		if err := jpeg.Encode(&buff, src, &jpeg.Options{Quality: o.quality()}); err != nil {
+			return nil, "", "", err
+		}
+		return buff.Bytes(), "DCTDecode", colorSpace, nil
+	}
+
+	var zbuff bytes.Buffer
+	w := zlib.NewWriter(&zbuff)
+	if _, err := w.Write(imageSamples(m, gray)); err != nil {
+		return nil, "", "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", "", err
+	}
+	return zbuff.Bytes(), "FlateDecode", colorSpace, nil
+}
+
+//imageSamples : 8 bit samples of pixels of m (rgb or gray) , alpha is ignored
+func imageSamples(m image.Image, gray bool) []byte {
+	bounds := m.Bounds()
+	components := 3
+	if gray {
+		components = 1
+	}
+	data := make([]byte, 0, bounds.Dx()*bounds.Dy()*components)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := m.At(x, y).RGBA()
+			if gray {
+				data = append(data, byte(int(luminance(float64(r), float64(g), float64(b)))>>8))
+				continue
+			}
+			data = append(data, byte(r>>8), byte(g>>8), byte(b>>8))
+		}
+	}
+	return data
+}
@@ -0,0 +1,63 @@
+package gopdf
+
+import "errors"
+
+//ErrUnknownFloatSide : side of AddImageFloat is not "left" or "right"
+var ErrUnknownFloatSide = errors.New("float side must be left or right")
+
+//imageFloat : area of floated image (with margin) that MultiCell wraps lines around , top-down page coordinate
+type imageFloat struct {
+	side   string
+	left   float64
+	right  float64
+	top    float64
+	bottom float64
+}
+
+//AddImageFloat : draw image at its size with top at current y , on "left" (at current x) or "right" (at page width - left margin) ,
+//lines of MultiCell beside the image (until past its bottom + margin) are narrowed to keep margin from it , current position is not changed
+func (gp *GoPdf) AddImageFloat(picPath string, side string, margin float64) error {
+	if side != "left" && side != "right" {
+		return ErrUnknownFloatSide
+	}
+	imgobj := new(ImageObj)
+	imgobj.Init(func() *GoPdf {
+		return gp
+	})
+	imgobj.SetImagePath(picPath)
+	rect := imgobj.GetRect()
+	if rect == nil {
+		_, err := imgobj.bounds()
+		return err
+	}
+
+	x := gp.Curr.X
+	floatImg := imageFloat{side: side, left: x, right: x + rect.W + margin, top: gp.Curr.Y, bottom: gp.Curr.Y + rect.H + margin}
+	if side == "right" {
+		x = gp.config.PageSize.W - gp.leftMargin - rect.W
+		floatImg.left = x - margin
+		floatImg.right = x + rect.W
+	}
+	gp.imageFloats = append(gp.imageFloats, floatImg)
+	gp.Image(picPath, x, gp.Curr.Y, rect)
+	return nil
+}
+
+//floatLine : x and width of line at x,y with width w and height h after floated images are excluded
+func (gp *GoPdf) floatLine(x float64, y float64, w float64, h float64) (float64, float64) {
+	for _, floatImg := range gp.imageFloats {
+		if y >= floatImg.bottom || y+h <= floatImg.top || x >= floatImg.right || x+w <= floatImg.left {
+			continue
+		}
+		if floatImg.side == "left" {
+			w -= floatImg.right - x
+			x = floatImg.right
+		} else {
+			w = floatImg.left - x
+		}
+	}
+	if w < 0 {
+		w = 0
+	}
+	return x, w
+}
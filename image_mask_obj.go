@@ -0,0 +1,69 @@
+package gopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+)
+
+//ImageMaskObj : 1 bit stencil image (/ImageMask true) painted with current fill color
+type ImageMaskObj struct { //impl IObj
+	buffer bytes.Buffer
+	mask   image.Image
+}
+
+func (i *ImageMaskObj) Init(funcGetRoot func() *GoPdf) {}
+
+func (i *ImageMaskObj) Build() error {
+	bounds := i.mask.Bounds()
+	var zbuff bytes.Buffer
+	w := zlib.NewWriter(&zbuff)
+	_, err := w.Write(i.bits())
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	i.buffer.WriteString("<</Type /XObject\n")
+	i.buffer.WriteString("/Subtype /Image\n")
+	i.buffer.WriteString(fmt.Sprintf("/Width %d\n", bounds.Dx()))
+	i.buffer.WriteString(fmt.Sprintf("/Height %d\n", bounds.Dy()))
+	i.buffer.WriteString("/ImageMask true\n")
+	i.buffer.WriteString("/BitsPerComponent 1\n")
+	i.buffer.WriteString("/Filter /FlateDecode\n")
+	i.buffer.WriteString(fmt.Sprintf("/Length %d\n>>\n", zbuff.Len()))
+	i.buffer.WriteString("stream\n")
+	i.buffer.Write(zbuff.Bytes())
+	i.buffer.WriteString("\nendstream\n")
+	return nil
+}
+
+//bits : rows of 1 bit samples (each row start at new byte) , 0 = paint (pixel is dark and opaque) , 1 = transparent
+func (i *ImageMaskObj) bits() []byte {
+	bounds := i.mask.Bounds()
+	rowSize := (bounds.Dx() + 7) / 8
+	data := make([]byte, rowSize*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := i.mask.At(x, y).RGBA()
+			//สีที่ได้เป็น alpha-premultiplied จึงเทียบความมืดกับ alpha
+			isOn := a >= 0x8000 && luminance(float64(r), float64(g), float64(b)) < float64(a)/2
+			if !isOn {
+				index := (y-bounds.Min.Y)*rowSize + (x-bounds.Min.X)/8
+				data[index] |= 0x80 >> uint((x-bounds.Min.X)%8)
+			}
+		}
+	}
+	return data
+}
+
+func (i *ImageMaskObj) GetType() string {
+	return "Image"
+}
+
+func (i *ImageMaskObj) GetObjBuff() *bytes.Buffer {
+	return &i.buffer
+}
@@ -13,39 +13,55 @@ import (
 type ImageObj struct {
 	buffer    bytes.Buffer
 	imagepath string
+	getRoot   func() *GoPdf
+	//colorKey : range of colors (low , high) that are transparent (nil = no color key mask)
+	colorKey *[2][3]uint8
+	//option : filter of image stream
+	option ImageOption
 }
 
 func (i *ImageObj) Init(funcGetRoot func() *GoPdf) {
-	//me.getRoot = funcGetRoot
+	i.getRoot = funcGetRoot
 }
 
 func (i *ImageObj) Build() error {
 
+	if b, err := ioutil.ReadFile(i.imagepath); err == nil && isJPX(b) {
+		return i.buildJPX(b)
+	}
+
 	file, err := os.Open(i.imagepath)
 	if err != nil {
 		//fmt.Printf("0--%+v\n",err)
 		return err
 	}
 	defer file.Close()
-	
-	m, _, err := image.Decode(file)
+
+	m, format, err := image.Decode(file)
 	if err != nil {
 		return err
 	}
 
 	imageRect := m.Bounds()
 
-	b, _ := ioutil.ReadFile(i.imagepath)
+	raw, err := ioutil.ReadFile(i.imagepath)
+	if err != nil {
+		return err
+	}
+	gray := i.getRoot != nil && i.getRoot().isGrayscaleOutput
+	b, filter, colorSpace, err := i.option.encodeImage(m, format, raw, gray)
+	if err != nil {
+		return err
+	}
 
 	i.buffer.WriteString("<</Type /XObject\n")
 	i.buffer.WriteString("/Subtype /Image\n")
 	i.buffer.WriteString(fmt.Sprintf("/Width %d\n", imageRect.Dx()))  // /Width 675\n"
 	i.buffer.WriteString(fmt.Sprintf("/Height %d\n", imageRect.Dy())) //  /Height 942\n"
-	i.buffer.WriteString("/ColorSpace /DeviceRGB\n")                  //HARD CODE ไว้เป็น RGB
-	i.buffer.WriteString("/BitsPerComponent 8\n")                     //HARD CODE ไว้เป็น 8 bit
-	i.buffer.WriteString("/Filter /DCTDecode\n")
-	//me.buffer.WriteString("/Filter /FlateDecode\n")
-	//me.buffer.WriteString("/DecodeParms <</Predictor 15 /Colors 3 /BitsPerComponent 8 /Columns 675>>\n")
+	i.buffer.WriteString("/ColorSpace /" + colorSpace + "\n")
+	i.buffer.WriteString("/BitsPerComponent 8\n") //HARD CODE ไว้เป็น 8 bit
+	i.buffer.WriteString(i.colorKeyMask(colorSpace))
+	i.buffer.WriteString("/Filter /" + filter + "\n")
 	i.buffer.WriteString(fmt.Sprintf("/Length %d\n>>\n", len(b))) // /Length 62303>>\n
 	i.buffer.WriteString("stream\n")
 	i.buffer.Write(b)
@@ -54,6 +70,57 @@ func (i *ImageObj) Build() error {
 	return nil
 }
 
+//buildJPX : embed JPEG2000 image as is (/JPXDecode) , SetGrayscaleOutput does not convert it
+func (i *ImageObj) buildJPX(b []byte) error {
+	info, err := parseJPX(b)
+	if err != nil {
+		return err
+	}
+	i.buffer.WriteString("<</Type /XObject\n")
+	i.buffer.WriteString("/Subtype /Image\n")
+	i.buffer.WriteString(fmt.Sprintf("/Width %d\n", info.width))
+	i.buffer.WriteString(fmt.Sprintf("/Height %d\n", info.height))
+	i.buffer.WriteString("/ColorSpace /" + info.colorSpace() + "\n")
+	i.buffer.WriteString(fmt.Sprintf("/BitsPerComponent %d\n", info.bitsPerComponent))
+	if info.bitsPerComponent == 8 {
+		i.buffer.WriteString(i.colorKeyMask(info.colorSpace()))
+	}
+	i.buffer.WriteString("/Filter /JPXDecode\n")
+	i.buffer.WriteString(fmt.Sprintf("/Length %d\n>>\n", len(b)))
+	i.buffer.WriteString("stream\n")
+	i.buffer.Write(b)
+	i.buffer.WriteString("\nendstream\n")
+	return nil
+}
+
+//colorKeyMask : /Mask entry of color key in colorSpace of image ("" if no color key)
+func (i *ImageObj) colorKeyMask(colorSpace string) string {
+	if i.colorKey == nil {
+		return ""
+	}
+	low, high := i.colorKey[0], i.colorKey[1]
+	switch colorSpace {
+	case "DeviceRGB":
+		return fmt.Sprintf("/Mask [%d %d %d %d %d %d]\n", low[0], high[0], low[1], high[1], low[2], high[2])
+	case "DeviceGray":
+		gray := func(c [3]uint8) int {
+			return int(luminance(float64(c[0]), float64(c[1]), float64(c[2])) + 0.5)
+		}
+		return fmt.Sprintf("/Mask [%d %d]\n", gray(low), gray(high))
+	}
+	return ""
+}
+
+//SetColorKey : pixels with every component between low and high are not painted
+func (i *ImageObj) SetColorKey(low [3]uint8, high [3]uint8) {
+	i.colorKey = &[2][3]uint8{low, high}
+}
+
+//SetOption : set filter of image stream
+func (i *ImageObj) SetOption(option ImageOption) {
+	i.option = option
+}
+
 func (i *ImageObj) GetType() string {
 	return "Image"
 }
@@ -67,13 +134,10 @@ func (i *ImageObj) SetImagePath(path string) {
 }
 
 func (i *ImageObj) GetRect() *Rect {
-	file, err := os.Open(i.imagepath)
-	m, _, err := image.Decode(file)
+	imageRect, err := i.bounds()
 	if err != nil {
 		return nil
 	}
-
-	imageRect := m.Bounds()
 	k := 1
 	w := -128 //init
 	h := -128 //init
@@ -96,3 +160,23 @@ func (i *ImageObj) GetRect() *Rect {
 
 	return rect
 }
+
+//bounds : size of image in pixels
+func (i *ImageObj) bounds() (image.Rectangle, error) {
+	b, err := ioutil.ReadFile(i.imagepath)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	if isJPX(b) {
+		info, err := parseJPX(b)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		return image.Rect(0, 0, info.width, info.height), nil
+	}
+	m, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	return m.Bounds(), nil
+}
@@ -0,0 +1,22 @@
+package gopdf
+
+//ImageRotatedOption : option of ImageRotated
+type ImageRotatedOption struct {
+	//Anchor : point that image is rotated around , "center" (default "") , "topleft" , "topright" , "bottomleft" or "bottomright"
+	Anchor string
+}
+
+//anchorOf : anchor point of rect at x,y (upper left)
+func (o ImageRotatedOption) anchorOf(x float64, y float64, rect *Rect) (float64, float64) {
+	switch o.Anchor {
+	case "topleft":
+		return x, y
+	case "topright":
+		return x + rect.W, y
+	case "bottomleft":
+		return x, y + rect.H
+	case "bottomright":
+		return x + rect.W, y + rect.H
+	}
+	return x + rect.W/2, y + rect.H/2
+}
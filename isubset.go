@@ -9,4 +9,7 @@ type ISubset interface {
 	CharIndex(r rune) (uint64, error) //get char index
 	CharWidth(r rune) (uint64, error) //find chear width
 	GetUt() int64
+	CharCodeToGlyphIndex(r rune) uint64            //find glyph index without add char to subset
+	GlyphIndexToPdfWidth(glyphIndex uint64) uint64 //width of glyph in 1/1000 of font size
+	RuneWidth(r rune) uint64                       //width of rune in 1/1000 of font size without add char
 }
@@ -0,0 +1,96 @@
+package gopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+//ErrInvalidJPX : JPEG2000 image has no valid SIZ marker
+var ErrInvalidJPX = errors.New("invalid JPEG2000 image")
+
+//signature of raw JPEG2000 codestream (SOC and SIZ marker)
+var jpxCodestreamSignature = []byte{0xFF, 0x4F, 0xFF, 0x51}
+
+//signature box of JP2 file
+var jp2Signature = []byte{0x00, 0x00, 0x00, 0x0C, 0x6A, 0x50, 0x20, 0x20, 0x0D, 0x0A, 0x87, 0x0A}
+
+//jpxInfo : header of JPEG2000 image
+type jpxInfo struct {
+	width            int
+	height           int
+	components       int
+	bitsPerComponent int
+}
+
+//colorSpace : color space of image by count of components (JP2 file can also define it by itself)
+func (j jpxInfo) colorSpace() string {
+	switch j.components {
+	case 1:
+		return "DeviceGray"
+	case 4:
+		return "DeviceCMYK"
+	}
+	return "DeviceRGB"
+}
+
+//isJPX : b is raw JPEG2000 codestream (.j2k , .jpc) or JP2 file (.jp2)
+func isJPX(b []byte) bool {
+	return bytes.HasPrefix(b, jpxCodestreamSignature) || bytes.HasPrefix(b, jp2Signature)
+}
+
+//parseJPX : read size , components and bit depth from SIZ marker of codestream ,
+//codestream of JP2 file is found in its jp2c box (other boxes are not parsed)
+func parseJPX(b []byte) (jpxInfo, error) {
+	if bytes.HasPrefix(b, jp2Signature) {
+		codestream, ok := jp2Codestream(b)
+		if !ok {
+			return jpxInfo{}, ErrInvalidJPX
+		}
+		b = codestream
+	}
+	//SOC(2) SIZ(2) Lsiz(2) Rsiz(2) Xsiz(4) Ysiz(4) XOsiz(4) YOsiz(4) XTsiz(4) YTsiz(4) XTOsiz(4) YTOsiz(4) Csiz(2) Ssiz(1)...
+	if !bytes.HasPrefix(b, jpxCodestreamSignature) || len(b) < 43 {
+		return jpxInfo{}, ErrInvalidJPX
+	}
+	xsiz := binary.BigEndian.Uint32(b[8:])
+	ysiz := binary.BigEndian.Uint32(b[12:])
+	xosiz := binary.BigEndian.Uint32(b[16:])
+	yosiz := binary.BigEndian.Uint32(b[20:])
+	csiz := int(binary.BigEndian.Uint16(b[40:]))
+	if xsiz <= xosiz || ysiz <= yosiz || csiz == 0 {
+		return jpxInfo{}, ErrInvalidJPX
+	}
+	return jpxInfo{
+		width:            int(xsiz - xosiz),
+		height:           int(ysiz - yosiz),
+		components:       csiz,
+		bitsPerComponent: int(b[42]&0x7F) + 1,
+	}, nil
+}
+
+//jp2Codestream : content of jp2c box
+func jp2Codestream(b []byte) ([]byte, bool) {
+	for len(b) >= 8 {
+		length := uint64(binary.BigEndian.Uint32(b))
+		boxType := string(b[4:8])
+		header := uint64(8)
+		if length == 1 {
+			if len(b) < 16 {
+				return nil, false
+			}
+			length = binary.BigEndian.Uint64(b[8:])
+			header = 16
+		} else if length == 0 {
+			length = uint64(len(b)) //last box
+		}
+		if length < header || length > uint64(len(b)) {
+			return nil, false
+		}
+		if boxType == "jp2c" {
+			return b[header:length], true
+		}
+		b = b[length:]
+	}
+	return nil, false
+}
@@ -0,0 +1,76 @@
+package gopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//testJPXCodestream : main header of codestream (SOC , SIZ , EOC) of w x h image with 3 components of 8 bit
+func testJPXCodestream(w uint32, h uint32) []byte {
+	var buff bytes.Buffer
+	buff.Write([]byte{0xFF, 0x4F, 0xFF, 0x51})
+	binary.Write(&buff, binary.BigEndian, uint16(38+3*3)) //Lsiz
+	binary.Write(&buff, binary.BigEndian, uint16(0))      //Rsiz
+	for _, v := range []uint32{w, h, 0, 0, w, h, 0, 0} {  //Xsiz Ysiz XOsiz YOsiz XTsiz YTsiz XTOsiz YTOsiz
+		binary.Write(&buff, binary.BigEndian, v)
+	}
+	binary.Write(&buff, binary.BigEndian, uint16(3)) //Csiz
+	for i := 0; i < 3; i++ {
+		buff.Write([]byte{7, 1, 1}) //Ssiz XRsiz YRsiz
+	}
+	buff.Write([]byte{0xFF, 0xD9})
+	return buff.Bytes()
+}
+
+//testJP2 : codestream wrapped in JP2 file (signature , ftyp and jp2c box)
+func testJP2(codestream []byte) []byte {
+	var buff bytes.Buffer
+	buff.Write(jp2Signature)
+	binary.Write(&buff, binary.BigEndian, uint32(20))
+	buff.WriteString("ftypjp2 \x00\x00\x00\x00jp2 ")
+	binary.Write(&buff, binary.BigEndian, uint32(8+len(codestream)))
+	buff.WriteString("jp2c")
+	buff.Write(codestream)
+	return buff.Bytes()
+}
+
+func TestJPXImage(t *testing.T) {
+	dir := t.TempDir()
+	j2k := filepath.Join(dir, "img.j2k")
+	jp2 := filepath.Join(dir, "img.jp2")
+	if err := ioutil.WriteFile(j2k, testJPXCodestream(40, 30), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := ioutil.WriteFile(jp2, testJP2(testJPXCodestream(64, 48)), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	pdf.Image(j2k, 10, 10, nil)
+	pdf.Image(jp2, 10, 100, &Rect{W: 64, H: 48})
+	b, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(b)
+	if n := strings.Count(s, "/Filter /JPXDecode\n"); n != 2 {
+		t.Errorf("expect 2 JPXDecode images but found %d", n)
+	}
+	if !strings.Contains(s, "/Width 40\n/Height 30\n/ColorSpace /DeviceRGB\n/BitsPerComponent 8\n") {
+		t.Errorf("size of codestream not found")
+	}
+	if !strings.Contains(s, "/Width 64\n/Height 48\n") {
+		t.Errorf("size of jp2 not found")
+	}
+	//size of image without rect is same as other images (72/128 of pixels)
+	if !strings.Contains(s, "q 22.00 0 0 16.00 10.00 815.89 cm /I1 Do Q\n") {
+		t.Errorf("image without rect has wrong size")
+	}
+	checkXref(t, b)
+}
@@ -0,0 +1,39 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+)
+
+//ErrInvalidLayerState : print or view state of layer is not "ON" , "OFF" or ""
+var ErrInvalidLayerState = errors.New("invalid layer state")
+
+//Layer : optional content group made by AddLayer , content between BeginLayer and EndLayer belongs to it
+type Layer struct {
+	name string
+}
+
+//AddLayer : add layer (optional content group) named name that viewer list in its layers panel ,
+//printState and viewState are "ON" , "OFF" or "" (not set) and tell whether content of layer is printed and shown on screen
+//(sample printState "OFF" , viewState "ON" = screen only)
+func (gp *GoPdf) AddLayer(name string, printState string, viewState string) (Layer, error) {
+	for _, state := range []string{printState, viewState} {
+		if state != "" && state != "ON" && state != "OFF" {
+			return Layer{}, ErrInvalidLayerState
+		}
+	}
+	gp.countOfLayer++
+	layer := Layer{name: fmt.Sprintf("OC%d", gp.countOfLayer)}
+	gp.addOCG(layer.name, &OCGObj{name: name, printState: printState, viewState: viewState})
+	return layer, nil
+}
+
+//BeginLayer : content drawn until EndLayer on current page belongs to layer
+func (gp *GoPdf) BeginLayer(layer Layer) {
+	gp.getContent().AppendStreamRaw("/OC /" + layer.name + " BDC")
+}
+
+//EndLayer : end content of layer of BeginLayer
+func (gp *GoPdf) EndLayer() {
+	gp.getContent().AppendStreamRaw("EMC")
+}
@@ -0,0 +1,525 @@
+package gopdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var regexpObjRef = regexp.MustCompile(`\b(\d+) 0 R\b`)
+
+//linearizedPart : objects of one section of linearized file (index of gp.pdfObjs)
+type linearizedPart []int
+
+//compileLinearized : build pdf file that is organized for fast web view (PDF 32000-1 Annex F) ,
+//first page and its resources at the beginning of file , then other pages , shared objects and the rest
+func (gp *GoPdf) compileLinearized(ctx context.Context) ([]byte, error) {
+	//obj ที่ถูกตัดออกไม่ได้ใส่ใน part ใดเลย
+	skips := gp.buildSkips(nil)
+	err := gp.checkGraphicsState(skips)
+	if err != nil {
+		return nil, err
+	}
+	gp.xrefOffsets = nil
+	gp.skipObjs = skips
+	defer func() {
+		gp.skipObjs = nil
+	}()
+
+	max := len(gp.pdfObjs)
+	bodys := make([][]byte, max)
+	refs := make([][]int, max)
+	var pages []int
+	for i, pdfObj := range gp.pdfObjs {
+		if skips[i] {
+			continue
+		}
+		if pdfObj.GetType() == "Page" {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		pdfObj.GetObjBuff().Reset()
+		err := pdfObj.Build()
+		if err != nil {
+			return nil, err
+		}
+		bodys[i] = append([]byte(nil), pdfObj.GetObjBuff().Bytes()...)
+		refs[i] = objRefsOf(bodys[i], max)
+		if pdfObj.GetType() == "Page" {
+			pages = append(pages, i)
+		}
+	}
+	if len(pages) == 0 {
+		return gp.compile(ctx, nil)
+	}
+
+	//objects that each page needs
+	closures := make([]map[int]bool, len(pages))
+	for p, indexOfPage := range pages {
+		closures[p] = map[int]bool{}
+		gp.collectObjRefs(indexOfPage, refs, closures[p])
+	}
+
+	//part 6 : first page
+	part6 := linearizedPart{pages[0]}
+	for i := 0; i < max; i++ {
+		if i != pages[0] && closures[0][i] {
+			part6 = append(part6, i)
+		}
+	}
+	inPart6 := part6.indexes()
+
+	//object that used by more than one of other pages is shared (part 8)
+	usage := map[int]int{}
+	for p := 1; p < len(pages); p++ {
+		for i := range closures[p] {
+			if !inPart6[i] && i != pages[p] {
+				usage[i]++
+			}
+		}
+	}
+	var part7 linearizedPart
+	var pageParts []linearizedPart
+	for p := 1; p < len(pages); p++ {
+		pagePart := linearizedPart{pages[p]}
+		for i := 0; i < max; i++ {
+			if i != pages[p] && closures[p][i] && !inPart6[i] && usage[i] == 1 {
+				pagePart = append(pagePart, i)
+			}
+		}
+		pageParts = append(pageParts, pagePart)
+		part7 = append(part7, pagePart...)
+	}
+	var part8 linearizedPart
+	for i := 0; i < max; i++ {
+		if usage[i] > 1 {
+			part8 = append(part8, i)
+		}
+	}
+
+	//part 9 : everything else except catalog (part 4)
+	used := part6.indexes()
+	used[0] = true
+	for _, i := range part7 {
+		used[i] = true
+	}
+	for _, i := range part8 {
+		used[i] = true
+	}
+	var part9 linearizedPart
+	for i := 0; i < max; i++ {
+		if !used[i] && !skips[i] {
+			part9 = append(part9, i)
+		}
+	}
+
+	//renumber : main section (part 7,8,9) start at 1 , first page section start at linearization dictionary
+	objNums := make([]int, max)
+	n := 1
+	for _, part := range []linearizedPart{part7, part8, part9} {
+		for _, i := range part {
+			objNums[i] = n
+			n++
+		}
+	}
+	linObjNum := n
+	objNums[0] = linObjNum + 1
+	hintObjNum := linObjNum + 2
+	for j, i := range part6 {
+		objNums[i] = hintObjNum + 1 + j
+	}
+	size := hintObjNum + len(part6) + 1
+
+	objs := make([][]byte, max)
+	var infos []PdfObjectInfo
+	for i := range bodys {
+		if skips[i] {
+			continue
+		}
+		objs[i] = linearizedObj(objNums[i], renumberObjRefs(bodys[i], objNums))
+		infos = append(infos, gp.objectInfo(i, objNums[i], bodys[i], len(objs[i])-2))
+	}
+	gp.objectInfos = infos
+
+	//layout without hint stream , offsets in hint tables ignore hint stream
+	header := []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	firstXrefLen := len(linearizedFirstXref(linObjNum, nil, size, objNums[0], 0))
+	startOfHint := len(header) + len(linearizedDict(linObjNum, 0, 0, 0, 0, 0, len(pages), 0)) + firstXrefLen + len(objs[0])
+	offsets := make([]int, max)
+	offset := startOfHint
+	for _, part := range []linearizedPart{part6, part7, part8, part9} {
+		for _, i := range part {
+			offsets[i] = offset
+			offset += len(objs[i])
+		}
+	}
+
+	//hint stream
+	//content stream ของแต่ละหน้า : offset จากต้นหน้า (page obj) และความยาว
+	contentOffsets := make([]int, len(pages))
+	contentLengths := make([]int, len(pages))
+	for p, indexOfPage := range pages {
+		contents := gp.pdfObjs[indexOfPage].(*PageObj).indexOfContents
+		if len(contents) == 0 {
+			continue
+		}
+		start, end := offsets[contents[0]], 0
+		for _, i := range contents {
+			if offsets[i] < start {
+				start = offsets[i]
+			}
+			if offsets[i]+len(objs[i]) > end {
+				end = offsets[i] + len(objs[i])
+			}
+		}
+		contentOffsets[p] = start - offsets[indexOfPage]
+		contentLengths[p] = end - start
+	}
+
+	var hint linearizedHint
+	hint.pageOffset(objs, offsets, closures, part6, pageParts, part8, contentOffsets, contentLengths)
+	sharedOffset := hint.buff.Len()
+	hint.sharedObject(objs, offsets, objNums, part6, part8)
+	hintBody := new(bytes.Buffer)
+	hintBody.WriteString("<<\n")
+	hintBody.WriteString("/Length " + strconv.Itoa(hint.buff.Len()) + "\n")
+	hintBody.WriteString("/S " + strconv.Itoa(sharedOffset) + "\n")
+	hintBody.WriteString(">>\n")
+	hintBody.WriteString("stream\n")
+	hintBody.Write(hint.buff.Bytes())
+	hintBody.WriteString("\nendstream\n")
+	hintObj := linearizedObj(hintObjNum, hintBody.Bytes())
+
+	//final offsets
+	for i := range offsets {
+		if i != 0 {
+			offsets[i] += len(hintObj)
+		}
+	}
+	offsets[0] = startOfHint - len(objs[0])
+	endOfFirstPage := startOfHint + len(hintObj)
+	for _, i := range part6 {
+		endOfFirstPage += len(objs[i])
+	}
+	firstXrefOffset := startOfHint - len(objs[0]) - firstXrefLen
+	mainXrefOffset := offset + len(hintObj)
+	mainXref := new(bytes.Buffer)
+	mainXref.WriteString("xref\n")
+	mainXref.WriteString("0 " + strconv.Itoa(linObjNum) + "\n")
+	mainXref.WriteString("0000000000 65535 f \n")
+	for _, part := range []linearizedPart{part7, part8, part9} {
+		for _, i := range part {
+			mainXref.WriteString(gp.formatXrefline(offsets[i]) + " 00000 n \n")
+		}
+	}
+	mainXref.WriteString("trailer\n")
+	mainXref.WriteString("<<\n")
+	mainXref.WriteString("/Size " + strconv.Itoa(linObjNum) + "\n")
+	mainXref.WriteString(">>\n")
+	mainXref.WriteString("startxref\n")
+	mainXref.WriteString(strconv.Itoa(firstXrefOffset) + "\n")
+	mainXref.WriteString("%%EOF\n")
+	fileLen := mainXrefOffset + mainXref.Len()
+
+	firstOffsets := []int{len(header), offsets[0], startOfHint}
+	for _, i := range part6 {
+		firstOffsets = append(firstOffsets, offsets[i])
+	}
+
+	buff := new(bytes.Buffer)
+	buff.Write(header)
+	buff.Write(linearizedDict(linObjNum, fileLen, startOfHint, len(hintObj), objNums[pages[0]], endOfFirstPage, len(pages), mainXrefOffset+len("xref\n0 "+strconv.Itoa(linObjNum))))
+	buff.Write(linearizedFirstXref(linObjNum, firstOffsets, size, objNums[0], mainXrefOffset))
+	buff.Write(objs[0])
+	buff.Write(hintObj)
+	for _, part := range []linearizedPart{part6, part7, part8, part9} {
+		for _, i := range part {
+			buff.Write(objs[i])
+		}
+	}
+	buff.Write(mainXref.Bytes())
+	return buff.Bytes(), nil
+}
+
+//collectObjRefs : all objects that index refer to (not follow catalog and pages tree)
+func (gp *GoPdf) collectObjRefs(index int, refs [][]int, result map[int]bool) {
+	for _, ref := range refs[index] {
+		if ref == 0 || ref == gp.indexOfPagesObj || result[ref] {
+			continue
+		}
+		result[ref] = true
+		gp.collectObjRefs(ref, refs, result)
+	}
+}
+
+func (l linearizedPart) indexes() map[int]bool {
+	result := map[int]bool{}
+	for _, i := range l {
+		result[i] = true
+	}
+	return result
+}
+
+//objDictOf : dictionary part of obj (without stream data)
+func objDictOf(body []byte) []byte {
+	i := bytes.Index(body, []byte("\nstream\n"))
+	if i < 0 {
+		return body
+	}
+	return body[:i]
+}
+
+//objRefsOf : index of objects that referred from dictionary of obj
+func objRefsOf(body []byte, max int) []int {
+	var refs []int
+	for _, m := range regexpObjRef.FindAllSubmatch(objDictOf(body), -1) {
+		n, err := strconv.Atoi(string(m[1]))
+		if err == nil && n >= 1 && n <= max {
+			refs = append(refs, n-1)
+		}
+	}
+	return refs
+}
+
+//renumberObjRefs : replace "n 0 R" in dictionary of obj by new object number
+func renumberObjRefs(body []byte, objNums []int) []byte {
+	dict := objDictOf(body)
+	newDict := regexpObjRef.ReplaceAllFunc(dict, func(ref []byte) []byte {
+		n, err := strconv.Atoi(string(regexpObjRef.FindSubmatch(ref)[1]))
+		if err != nil || n < 1 || n > len(objNums) {
+			return ref
+		}
+		return []byte(strconv.Itoa(objNums[n-1]) + " 0 R")
+	})
+	result := append([]byte(nil), newDict...)
+	return append(result, body[len(dict):]...)
+}
+
+func linearizedObj(objNum int, body []byte) []byte {
+	var buff bytes.Buffer
+	buff.WriteString(strconv.Itoa(objNum) + " 0 obj\n")
+	buff.Write(body)
+	buff.WriteString("endobj\n\n")
+	return buff.Bytes()
+}
+
+//linearizedDict : numbers have fixed width so length of dictionary is known before offsets are known
+func linearizedDict(objNum int, fileLen int, hintOffset int, hintLen int, firstPageObjNum int, endOfFirstPage int, pageCount int, mainXrefFirstEntry int) []byte {
+	body := fmt.Sprintf("<<\n/Linearized 1\n/L %010d\n/H [ %010d %010d ]\n/O %010d\n/E %010d\n/N %010d\n/T %010d\n>>\n",
+		fileLen, hintOffset, hintLen, firstPageObjNum, endOfFirstPage, pageCount, mainXrefFirstEntry)
+	return linearizedObj(objNum, []byte(body))
+}
+
+//linearizedFirstXref : xref and trailer of first page section (linearization dictionary , catalog , hint stream and first page)
+func linearizedFirstXref(linObjNum int, offsets []int, size int, rootObjNum int, mainXrefOffset int) []byte {
+	count := size - linObjNum
+	var buff bytes.Buffer
+	buff.WriteString("xref\n")
+	buff.WriteString(strconv.Itoa(linObjNum) + " " + strconv.Itoa(count) + "\n")
+	for j := 0; j < count; j++ {
+		offset := 0
+		if j < len(offsets) {
+			offset = offsets[j]
+		}
+		buff.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	buff.WriteString("trailer\n")
+	buff.WriteString("<<\n")
+	buff.WriteString("/Size " + strconv.Itoa(size) + "\n")
+	buff.WriteString("/Root " + strconv.Itoa(rootObjNum) + " 0 R\n")
+	buff.WriteString(fmt.Sprintf("/Prev %010d\n", mainXrefOffset))
+	buff.WriteString(">>\n")
+	buff.WriteString("startxref\n0\n%%EOF\n")
+	return buff.Bytes()
+}
+
+//linearizedHint : data of primary hint stream
+type linearizedHint struct {
+	buff  bytes.Buffer
+	bits  uint64
+	nbits uint
+}
+
+func (h *linearizedHint) write(value int, nbits int) {
+	for b := nbits - 1; b >= 0; b-- {
+		h.bits = h.bits<<1 | uint64(value>>uint(b))&1
+		h.nbits++
+		if h.nbits == 8 {
+			h.buff.WriteByte(byte(h.bits))
+			h.bits = 0
+			h.nbits = 0
+		}
+	}
+}
+
+//flush : pad to byte boundary
+func (h *linearizedHint) flush() {
+	if h.nbits > 0 {
+		h.write(0, int(8-h.nbits))
+	}
+}
+
+//bitsOf : number of bits to represent n
+func bitsOf(n int) int {
+	nbits := 0
+	for n > 0 {
+		nbits++
+		n >>= 1
+	}
+	return nbits
+}
+
+func minMaxOf(values []int) (int, int) {
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+func partLen(objs [][]byte, part linearizedPart) int {
+	l := 0
+	for _, i := range part {
+		l += len(objs[i])
+	}
+	return l
+}
+
+//pageOffset : page offset hint table , contentOffsets and contentLengths are offset of content stream from
+//start of page and its length of each page
+func (h *linearizedHint) pageOffset(objs [][]byte, offsets []int, closures []map[int]bool, part6 linearizedPart, pageParts []linearizedPart, part8 linearizedPart, contentOffsets []int, contentLengths []int) {
+	sharedIDs := map[int]int{}
+	for j, i := range part6 {
+		sharedIDs[i] = j
+	}
+	for j, i := range part8 {
+		sharedIDs[i] = len(part6) + j
+	}
+
+	nobjects := []int{len(part6)}
+	lengths := []int{partLen(objs, part6)}
+	shareds := [][]int{nil}
+	for _, pagePart := range pageParts {
+		nobjects = append(nobjects, len(pagePart))
+		lengths = append(lengths, partLen(objs, pagePart))
+		shareds = append(shareds, nil)
+	}
+	//shared objects of other pages are the objects in first page or shared section that they need
+	for p := range pageParts {
+		var ids []int
+		for i := range closures[p+1] {
+			if id, ok := sharedIDs[i]; ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Ints(ids)
+		shareds[p+1] = ids
+	}
+
+	minObjects, maxObjects := minMaxOf(nobjects)
+	minLength, maxLength := minMaxOf(lengths)
+	maxShared, maxID := 0, 0
+	for _, ids := range shareds {
+		if len(ids) > maxShared {
+			maxShared = len(ids)
+		}
+		for _, id := range ids {
+			if id > maxID {
+				maxID = id
+			}
+		}
+	}
+	minContentOffset, maxContentOffset := minMaxOf(contentOffsets)
+	minContentLength, maxContentLength := minMaxOf(contentLengths)
+	nbitsObjects := bitsOf(maxObjects - minObjects)
+	nbitsLength := bitsOf(maxLength - minLength)
+	nbitsContentOffset := bitsOf(maxContentOffset - minContentOffset)
+	nbitsContentLength := bitsOf(maxContentLength - minContentLength)
+	nbitsShared := bitsOf(maxShared)
+	nbitsID := bitsOf(maxID)
+
+	h.write(minObjects, 32)
+	h.write(offsets[part6[0]], 32)
+	h.write(nbitsObjects, 16)
+	h.write(minLength, 32)
+	h.write(nbitsLength, 16)
+	h.write(minContentOffset, 32)
+	h.write(nbitsContentOffset, 16)
+	h.write(minContentLength, 32)
+	h.write(nbitsContentLength, 16)
+	h.write(nbitsShared, 16)
+	h.write(nbitsID, 16)
+	h.write(0, 16) //numerator of fractional position
+	h.write(1, 16) //denominator of fractional position
+
+	for _, n := range nobjects {
+		h.write(n-minObjects, nbitsObjects)
+	}
+	h.flush()
+	for _, l := range lengths {
+		h.write(l-minLength, nbitsLength)
+	}
+	h.flush()
+	for _, ids := range shareds {
+		h.write(len(ids), nbitsShared)
+	}
+	h.flush()
+	for _, ids := range shareds {
+		for _, id := range ids {
+			h.write(id, nbitsID)
+		}
+	}
+	h.flush()
+	for _, o := range contentOffsets {
+		h.write(o-minContentOffset, nbitsContentOffset)
+	}
+	h.flush()
+	for _, l := range contentLengths {
+		h.write(l-minContentLength, nbitsContentLength)
+	}
+	h.flush()
+}
+
+//sharedObject : shared object hint table , one object per group
+func (h *linearizedHint) sharedObject(objs [][]byte, offsets []int, objNums []int, part6 linearizedPart, part8 linearizedPart) {
+	var lengths []int
+	for _, i := range part6 {
+		lengths = append(lengths, len(objs[i]))
+	}
+	for _, i := range part8 {
+		lengths = append(lengths, len(objs[i]))
+	}
+	minLength, maxLength := minMaxOf(lengths)
+	nbitsLength := bitsOf(maxLength - minLength)
+
+	if len(part8) > 0 {
+		h.write(objNums[part8[0]], 32)
+		h.write(offsets[part8[0]], 32)
+	} else {
+		h.write(0, 32)
+		h.write(0, 32)
+	}
+	h.write(len(part6), 32)
+	h.write(len(lengths), 32)
+	h.write(0, 16) //1 object per group
+	h.write(minLength, 32)
+	h.write(nbitsLength, 16)
+
+	for _, l := range lengths {
+		h.write(l-minLength, nbitsLength)
+	}
+	h.flush()
+	for range lengths {
+		h.write(0, 1) //no signature
+	}
+	h.flush()
+}
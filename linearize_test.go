@@ -0,0 +1,169 @@
+package gopdf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+//linearizedValue : value of key in linearization dictionary
+func linearizedValue(t *testing.T, s string, key string) int {
+	i := strings.Index(s, "\n/"+key+" ")
+	if i == -1 {
+		t.Fatalf("/%s not found in linearization dictionary", key)
+	}
+	var v int
+	fmt.Sscanf(s[i+len(key)+3:], "%d", &v)
+	return v
+}
+
+//hintReader : read bits of hint stream
+type hintReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *hintReader) read(nbits int) int {
+	v := 0
+	for i := 0; i < nbits; i++ {
+		bit := int(r.data[r.pos/8]>>uint(7-r.pos%8)) & 1
+		v = v<<1 | bit
+		r.pos++
+	}
+	return v
+}
+
+func (r *hintReader) align() {
+	r.pos = (r.pos + 7) / 8 * 8
+}
+
+//pageContentHints : offset (from start of page) and length of content stream of each page in page offset hint table
+func pageContentHints(t *testing.T, s string) ([]int, []int) {
+	var hintOffset, hintLen int
+	fmt.Sscanf(s[strings.Index(s, "\n/H ["):], "\n/H [ %d %d ]", &hintOffset, &hintLen)
+	pageCount := linearizedValue(t, s, "N")
+	hint := s[hintOffset : hintOffset+hintLen]
+	r := &hintReader{data: []byte(hint[strings.Index(hint, "stream\n")+len("stream\n"):])}
+	r.read(32)
+	r.read(32)
+	nbitsObjects := r.read(16)
+	r.read(32)
+	nbitsLength := r.read(16)
+	minContentOffset := r.read(32)
+	nbitsContentOffset := r.read(16)
+	minContentLength := r.read(32)
+	nbitsContentLength := r.read(16)
+	nbitsShared := r.read(16)
+	nbitsID := r.read(16)
+	r.read(16)
+	r.read(16)
+	skip := func(nbits int) {
+		for p := 0; p < pageCount; p++ {
+			r.read(nbits)
+		}
+		r.align()
+	}
+	skip(nbitsObjects)
+	skip(nbitsLength)
+	shareds := make([]int, pageCount)
+	for p := range shareds {
+		shareds[p] = r.read(nbitsShared)
+	}
+	r.align()
+	for _, n := range shareds {
+		r.read(n * nbitsID)
+	}
+	r.align()
+	offsets := make([]int, pageCount)
+	for p := range offsets {
+		offsets[p] = minContentOffset + r.read(nbitsContentOffset)
+	}
+	r.align()
+	lengths := make([]int, pageCount)
+	for p := range lengths {
+		lengths[p] = minContentLength + r.read(nbitsContentLength)
+	}
+	return offsets, lengths
+}
+
+func TestLinearized(t *testing.T) {
+	pdf := newTestPdf(t)
+	pdf.SetLinearized(true)
+	err := pdf.AddTTFFont("loma2", testFontPath(t))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	pdf.Cell(nil, "page1")
+	for _, text := range []string{"page2", "page3"} {
+		pdf.AddPage()
+		pdf.SetFont("loma2", "", 14)
+		pdf.Cell(nil, text)
+	}
+
+	b, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	s := string(b)
+	header := "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"
+	var linObjNum int
+	fmt.Sscanf(s[len(header):], "%d 0 obj\n<<\n/Linearized 1\n", &linObjNum)
+	if !strings.HasPrefix(s[len(header):], fmt.Sprintf("%d 0 obj\n<<\n/Linearized 1\n", linObjNum)) {
+		t.Fatalf("linearization dictionary must be the first object")
+	}
+	if l := linearizedValue(t, s, "L"); l != len(b) {
+		t.Errorf("/L %d but file length is %d", l, len(b))
+	}
+	if n := linearizedValue(t, s, "N"); n != 3 {
+		t.Errorf("/N must be 3 but got %d", n)
+	}
+
+	//first page xref section
+	first := strings.Index(s, "xref\n")
+	var start, count int
+	fmt.Sscanf(s[first:], "xref\n%d %d\n", &start, &count)
+	if start != linObjNum {
+		t.Errorf("first page xref must start at linearization dictionary")
+	}
+	lines := strings.Split(s[first:], "\n")
+	offsets := map[int]int{}
+	for i := 0; i < count; i++ {
+		var offset int
+		fmt.Sscanf(lines[2+i], "%d", &offset)
+		offsets[start+i] = offset
+		if !strings.HasPrefix(s[offset:], fmt.Sprintf("%d 0 obj\n", start+i)) {
+			t.Errorf("xref of obj %d point to wrong offset %d", start+i, offset)
+		}
+	}
+	if !strings.HasSuffix(s, fmt.Sprintf("startxref\n%d\n%%%%EOF\n", first)) {
+		t.Errorf("last startxref must point to first page xref")
+	}
+	checkXref(t, b)
+
+	//first page object and end of first page
+	o := linearizedValue(t, s, "O")
+	if !strings.HasPrefix(s[offsets[o]:], fmt.Sprintf("%d 0 obj\n<<\n  /Type /Page\n", o)) {
+		t.Errorf("/O must be the first page")
+	}
+	e := linearizedValue(t, s, "E")
+	if !strings.HasSuffix(s[:e], "endobj\n\n") || strings.Contains(s[:e], "/Type /Pages\n") {
+		t.Errorf("/E must be the end of first page section")
+	}
+	if mainXref := linearizedValue(t, s, "T"); !strings.HasPrefix(s[mainXref:], "\n0000000000 65535 f \n") {
+		t.Errorf("/T must point to first entry of main xref")
+	}
+
+	//content stream ของแต่ละหน้าใน page offset hint table
+	contentOffsets, contentLengths := pageContentHints(t, s)
+	pages := regexp.MustCompile(`\n\d+ 0 obj\n<<\n  /Type /Page\n`).FindAllStringIndex(s, -1)
+	if len(pages) != 3 {
+		t.Fatalf("expect 3 pages but got %d", len(pages))
+	}
+	for p, page := range pages {
+		content := s[page[0]+1+contentOffsets[p]:]
+		if contentLengths[p] == 0 || !strings.Contains(content[:contentLengths[p]], "stream\n") || !strings.HasSuffix(content[:contentLengths[p]], "endstream\nendobj\n\n") {
+			t.Errorf("hint of content stream of page %d is wrong", p+1)
+		}
+	}
+}
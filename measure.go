@@ -0,0 +1,109 @@
+package gopdf
+
+import (
+	"math"
+)
+
+//measureState : state of dry-run started by BeginMeasure
+type measureState struct {
+	indexOfContent int
+	streamLen      int
+	startX, startY float64
+	//จำนวนรูปก่อน dry-run (รูปที่เพิ่มระหว่าง dry-run ถูกเอาออก)
+	countOfImg    int
+	countOfXobjs  int
+	countOfCaches int
+	//extents ของสิ่งที่วาด (ไม่มี = found false)
+	found                  bool
+	minX, minY, maxX, maxY float64
+}
+
+//BeginMeasure : start dry-run , cells , lines and images drawn until EndMeasure move current position as usual
+//but are removed from the page and images that are first drawn in dry-run are not embedded ,
+//only cells , lines and images are measured , other drawing is removed but not measured and its resources are kept
+//(must not add page or font before EndMeasure)
+func (gp *GoPdf) BeginMeasure() {
+	content := gp.getContent()
+	gp.measure = &measureState{
+		indexOfContent: gp.indexOfContent,
+		streamLen:      content.stream.Len(),
+		startX:         gp.Curr.X,
+		startY:         gp.Curr.Y,
+		countOfImg:     gp.Curr.CountOfImg,
+		countOfCaches:  len(gp.Curr.ImgCaches),
+	}
+	if gp.indexOfProcSet != -1 {
+		gp.measure.countOfXobjs = len(gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj).RealteXobjs)
+	}
+}
+
+//EndMeasure : end dry-run , remove what is drawn since BeginMeasure , move current position back to where BeginMeasure was
+//and return upper left corner and size of area of cells , lines and images that were drawn (0 if nothing was drawn)
+func (gp *GoPdf) EndMeasure() (float64, float64, Rect) {
+	m := gp.measure
+	if m == nil {
+		return 0, 0, Rect{}
+	}
+	gp.measure = nil
+	if gp.indexOfContent == m.indexOfContent {
+		gp.getContent().stream.Truncate(m.streamLen)
+	}
+	gp.dropMeasureImages(m)
+	gp.Curr.X = m.startX
+	gp.Curr.Y = m.startY
+	if !m.found {
+		return 0, 0, Rect{}
+	}
+	return m.minX, m.minY, Rect{W: m.maxX - m.minX, H: m.maxY - m.minY}
+}
+
+//measureArea : add area x,y (upper left) , w , h to extents of dry-run
+func (gp *GoPdf) measureArea(x float64, y float64, w float64, h float64) {
+	m := gp.measure
+	if m == nil {
+		return
+	}
+	x0, x1 := math.Min(x, x+w), math.Max(x, x+w)
+	y0, y1 := math.Min(y, y+h), math.Max(y, y+h)
+	if !m.found {
+		m.minX, m.minY, m.maxX, m.maxY = x0, y0, x1, y1
+		m.found = true
+		return
+	}
+	m.minX = math.Min(m.minX, x0)
+	m.minY = math.Min(m.minY, y0)
+	m.maxX = math.Max(m.maxX, x1)
+	m.maxY = math.Max(m.maxY, y1)
+}
+
+//dropMeasureImages : remove images that are added in dry-run from image cache and resources , their objs are not written
+func (gp *GoPdf) dropMeasureImages(m *measureState) {
+	if gp.indexOfProcSet == -1 {
+		return
+	}
+	procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+	if gp.measureDrops == nil {
+		gp.measureDrops = make(map[int]bool)
+	}
+	for _, xobj := range procset.RealteXobjs[m.countOfXobjs:] {
+		gp.measureDrops[xobj.IndexOfObj] = true
+	}
+	procset.RealteXobjs = procset.RealteXobjs[:m.countOfXobjs]
+	gp.Curr.ImgCaches = gp.Curr.ImgCaches[:m.countOfCaches]
+	gp.Curr.CountOfImg = m.countOfImg
+}
+
+//measureObjSkips : objs of images that are drawn only in dry-run
+func (gp *GoPdf) measureObjSkips(skips map[int]bool) map[int]bool {
+	if len(gp.measureDrops) == 0 {
+		return skips
+	}
+	merged := make(map[int]bool)
+	for index := range skips {
+		merged[index] = true
+	}
+	for index := range gp.measureDrops {
+		merged[index] = true
+	}
+	return merged
+}
@@ -0,0 +1,72 @@
+package gopdf
+
+import (
+	"container/list"
+)
+
+//defaultMeasureCacheSize : count of widths kept by MeasureTextWidth (SetMeasureCacheSize)
+const defaultMeasureCacheSize = 1024
+
+//measureKey : everything width of text depend on
+type measureKey struct {
+	font        interface{}
+	size        int
+	spaceFactor float64
+	text        string
+}
+
+type measureEntry struct {
+	key   measureKey
+	width float64
+}
+
+//measureCache : least recently used widths of MeasureTextWidth
+type measureCache struct {
+	entries map[measureKey]*list.Element
+	order   *list.List
+}
+
+//SetMeasureCacheSize : count of widths of text that MeasureTextWidth keep (least recently used are removed) ,
+//0 = no cache (default 1024)
+func (gp *GoPdf) SetMeasureCacheSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	gp.measureCacheSize = size
+	gp.ClearMeasureCache()
+}
+
+//ClearMeasureCache : remove widths kept by MeasureTextWidth
+func (gp *GoPdf) ClearMeasureCache() {
+	gp.measureCache = nil
+}
+
+//cachedTextWidth : width of text in current font from cache
+func (gp *GoPdf) cachedTextWidth(key measureKey) (float64, bool) {
+	if gp.measureCache == nil {
+		return 0, false
+	}
+	element, ok := gp.measureCache.entries[key]
+	if !ok {
+		return 0, false
+	}
+	gp.measureCache.order.MoveToFront(element)
+	return element.Value.(*measureEntry).width, true
+}
+
+//cacheTextWidth : keep width of text , remove least recently used width if cache is full
+func (gp *GoPdf) cacheTextWidth(key measureKey, width float64) {
+	if gp.measureCacheSize <= 0 {
+		return
+	}
+	if gp.measureCache == nil {
+		gp.measureCache = &measureCache{entries: make(map[measureKey]*list.Element), order: list.New()}
+	}
+	cache := gp.measureCache
+	cache.entries[key] = cache.order.PushFront(&measureEntry{key: key, width: width})
+	for cache.order.Len() > gp.measureCacheSize {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*measureEntry).key)
+	}
+}
@@ -0,0 +1,42 @@
+package gopdf
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+)
+
+var regexpSubtype = regexp.MustCompile(`/Subtype\s*/([^\s/<>\[\]()]+)`)
+
+//PdfObjectInfo : indirect object of pdf file that was built last (Objects)
+type PdfObjectInfo struct {
+	//Number : object number in pdf file
+	Number int
+	//Type : kind of obj in this package (sample "Page" , "Content" , "SubsetFont" , "PdfDictionary" is font file)
+	Type string
+	//Subtype : /Subtype of dictionary of obj ("" if none)
+	Subtype string
+	//Length : bytes of obj in file (from "n 0 obj" to "endobj")
+	Length int
+}
+
+//Objects : objects of pdf file built by last GetBytesPdf , WritePdf ... in order of number (nil before pdf is built) ,
+//objects that are not written (free) and linearization dictionary and hint stream of linearized file are not included
+func (gp *GoPdf) Objects() []PdfObjectInfo {
+	infos := append([]PdfObjectInfo(nil), gp.objectInfos...)
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Number < infos[j].Number })
+	return infos
+}
+
+//objectInfo : info of obj i with its dictionary (or stream) body and bytes in file
+func (gp *GoPdf) objectInfo(i int, number int, body []byte, length int) PdfObjectInfo {
+	//หา /Subtype เฉพาะใน dictionary ไม่ใช่ใน data ของ stream
+	if end := bytes.Index(body, []byte("stream\n")); end != -1 {
+		body = body[:end]
+	}
+	info := PdfObjectInfo{Number: number, Type: gp.pdfObjs[i].GetType(), Length: length}
+	if match := regexpSubtype.FindSubmatch(body); match != nil {
+		info.Subtype = string(match[1])
+	}
+	return info
+}
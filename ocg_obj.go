@@ -0,0 +1,43 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//OCGObj : optional content group (layer) , usage tell viewer whether to show and print content of the group
+type OCGObj struct { //impl IObj
+	buffer bytes.Buffer
+	name   string
+	//printState , viewState : "ON" , "OFF" or "" (not set)
+	printState string
+	viewState  string
+}
+
+func (o *OCGObj) Init(funcGetRoot func() *GoPdf) {}
+
+func (o *OCGObj) Build() error {
+	o.buffer.WriteString("<<\n")
+	o.buffer.WriteString("/Type /OCG\n")
+	o.buffer.WriteString("/Name " + pdfTextString(o.name) + "\n")
+	if o.printState != "" || o.viewState != "" {
+		o.buffer.WriteString("/Usage <<")
+		if o.printState != "" {
+			o.buffer.WriteString(fmt.Sprintf(" /Print << /PrintState /%s >>", o.printState))
+		}
+		if o.viewState != "" {
+			o.buffer.WriteString(fmt.Sprintf(" /View << /ViewState /%s >>", o.viewState))
+		}
+		o.buffer.WriteString(" >>\n")
+	}
+	o.buffer.WriteString(">>\n")
+	return nil
+}
+
+func (o *OCGObj) GetType() string {
+	return "OCG"
+}
+
+func (o *OCGObj) GetObjBuff() *bytes.Buffer {
+	return &o.buffer
+}
@@ -0,0 +1,126 @@
+package gopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+//ErrUnknownDestination : kind of Destination is not "XYZ" , "Fit" or "FitH"
+var ErrUnknownDestination = errors.New("unknown destination kind")
+
+//Destination : view that is shown when bookmark is opened
+type Destination struct {
+	//Kind : "XYZ" (default "" , Left , Top and Zoom) , "Fit" (whole page) or "FitH" (page width with Top at top of window)
+	Kind string
+	//Left , Top : position (Top from top of page) , 0 Left = keep current left
+	Left float64
+	Top  float64
+	//Zoom : zoom of XYZ (1 = 100%) , 0 = keep current zoom
+	Zoom float64
+}
+
+//array : destination array to page (index of page obj) , pageH is height to convert Top to pdf coordinate
+func (d Destination) array(indexOfPage int, pageH float64) (string, error) {
+	switch d.Kind {
+	case "", "XYZ":
+		left := "null"
+		if d.Left != 0 {
+			left = fmt.Sprintf("%0.2f", d.Left)
+		}
+		zoom := "null"
+		if d.Zoom != 0 {
+			zoom = fmt.Sprintf("%0.2f", d.Zoom)
+		}
+		return fmt.Sprintf("[%d 0 R /XYZ %s %0.2f %s]", indexOfPage+1, left, pageH-d.Top, zoom), nil
+	case "Fit":
+		return fmt.Sprintf("[%d 0 R /Fit]", indexOfPage+1), nil
+	case "FitH":
+		return fmt.Sprintf("[%d 0 R /FitH %0.2f]", indexOfPage+1, pageH-d.Top), nil
+	}
+	return "", ErrUnknownDestination
+}
+
+//OutlinesObj : root of bookmarks (/Outlines of catalog)
+type OutlinesObj struct { //impl IObj
+	buffer bytes.Buffer
+	//index ของ OutlineObj ตามลำดับ
+	indexOfItems []int
+	getRoot      func() *GoPdf
+}
+
+func (o *OutlinesObj) Init(funcGetRoot func() *GoPdf) {
+	o.getRoot = funcGetRoot
+}
+
+//items : index of OutlineObj that are written (bookmarks of pages that are removed by ExtractPage are not written)
+func (o *OutlinesObj) items() []int {
+	if o.getRoot == nil {
+		return o.indexOfItems
+	}
+	return o.getRoot().keptObjs(o.indexOfItems)
+}
+
+func (o *OutlinesObj) Build() error {
+	items := o.items()
+	o.buffer.WriteString("<<\n")
+	o.buffer.WriteString("/Type /Outlines\n")
+	if len(items) > 0 {
+		o.buffer.WriteString(fmt.Sprintf("/First %d 0 R\n", items[0]+1))
+		o.buffer.WriteString(fmt.Sprintf("/Last %d 0 R\n", items[len(items)-1]+1))
+	}
+	o.buffer.WriteString(fmt.Sprintf("/Count %d\n", len(items)))
+	o.buffer.WriteString(">>\n")
+	return nil
+}
+
+func (o *OutlinesObj) GetType() string {
+	return "Outlines"
+}
+
+func (o *OutlinesObj) GetObjBuff() *bytes.Buffer {
+	return &o.buffer
+}
+
+//OutlineObj : one bookmark
+type OutlineObj struct { //impl IObj
+	buffer      bytes.Buffer
+	title       string
+	dest        string
+	indexOfOwn  int
+	parent      *OutlinesObj
+	indexOfRoot int
+	//index ของ page obj ที่ bookmark ชี้ไป
+	indexOfPage int
+}
+
+func (o *OutlineObj) Init(funcGetRoot func() *GoPdf) {}
+
+func (o *OutlineObj) Build() error {
+	o.buffer.WriteString("<<\n")
+	o.buffer.WriteString("/Title " + pdfTextString(o.title) + "\n")
+	o.buffer.WriteString(fmt.Sprintf("/Parent %d 0 R\n", o.indexOfRoot+1))
+	items := o.parent.items()
+	for i, index := range items {
+		if index != o.indexOfOwn {
+			continue
+		}
+		if i > 0 {
+			o.buffer.WriteString(fmt.Sprintf("/Prev %d 0 R\n", items[i-1]+1))
+		}
+		if i < len(items)-1 {
+			o.buffer.WriteString(fmt.Sprintf("/Next %d 0 R\n", items[i+1]+1))
+		}
+	}
+	o.buffer.WriteString("/Dest " + o.dest + "\n")
+	o.buffer.WriteString(">>\n")
+	return nil
+}
+
+func (o *OutlineObj) GetType() string {
+	return "Outline"
+}
+
+func (o *OutlineObj) GetObjBuff() *bytes.Buffer {
+	return &o.buffer
+}
@@ -2,17 +2,24 @@ package gopdf
 
 import (
 	"bytes"
-	//"fmt"
+	"fmt"
 )
 
 type PageObj struct { //impl IObj
 	buffer          bytes.Buffer
 	Contents        string
 	ResourcesRelate string
+	//index ของ content ของหน้านี้ (set ตอน prepare)
+	indexOfContents []int
+	//กรอบที่แสดงผล (llx lly urx ury) , nil = เท่ากับ MediaBox
+	cropBox []float64
+	//index ของ widget annotation (form field) ในหน้านี้
+	indexOfAnnots []int
+	getRoot       func() *GoPdf
 }
 
 func (p *PageObj) Init(funcGetRoot func() *GoPdf) {
-
+	p.getRoot = funcGetRoot
 }
 
 func (p *PageObj) Build() error {
@@ -20,7 +27,23 @@ func (p *PageObj) Build() error {
 	p.buffer.WriteString("<<\n")
 	p.buffer.WriteString("  /Type /" + p.GetType() + "\n")
 	p.buffer.WriteString("  /Parent 2 0 R\n")
-	p.buffer.WriteString("  /Resources " + p.ResourcesRelate + "\n")
+	if p.cropBox != nil {
+		p.buffer.WriteString(fmt.Sprintf("  /CropBox [ %0.2f %0.2f %0.2f %0.2f ]\n", p.cropBox[0], p.cropBox[1], p.cropBox[2], p.cropBox[3]))
+	} else if p.getRoot != nil {
+		p.buffer.WriteString(fmt.Sprintf("  /CropBox [ 0 0 %0.2f %0.2f ]\n", p.getRoot().config.PageSize.W, p.getRoot().config.PageSize.H))
+	}
+	if p.getRoot != nil && p.getRoot().indexOfProcSet != -1 {
+		//ใส่เฉพาะ resource ที่หน้านี้ใช้
+		names := make(map[string]bool)
+		for _, index := range p.indexOfContents {
+			contentStreamNames(p.getRoot().pdfObjs[index].(*ContentObj).pageStream(), names)
+		}
+		procset := p.getRoot().pdfObjs[p.getRoot().indexOfProcSet].(*ProcSetObj)
+		p.buffer.WriteString("  /Resources ")
+		procset.BuildResources(&p.buffer, names)
+	} else {
+		p.buffer.WriteString("  /Resources " + p.ResourcesRelate + "\n")
+	}
 	/*me.buffer.WriteString("    /Font <<\n")
 	i := 0
 	max := len(me.Realtes)
@@ -31,6 +54,13 @@ func (p *PageObj) Build() error {
 	}
 	me.buffer.WriteString("    >>\n")*/
 	//me.buffer.WriteString("  >>\n")
+	if p.getRoot != nil && !p.getRoot().isFlattenForms && len(p.getRoot().keptObjs(p.indexOfAnnots)) > 0 {
+		p.buffer.WriteString("  /Annots [")
+		for _, index := range p.getRoot().keptObjs(p.indexOfAnnots) {
+			p.buffer.WriteString(fmt.Sprintf(" %d 0 R", index+1))
+		}
+		p.buffer.WriteString(" ]\n")
+	}
 	p.buffer.WriteString("  /Contents " + p.Contents + "\n") //sample  Contents 8 0 R
 	p.buffer.WriteString(">>\n")
 	return nil
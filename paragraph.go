@@ -0,0 +1,108 @@
+package gopdf
+
+import (
+	"strings"
+)
+
+//paragraphLine : wrapped line and whether it is the last line of its paragraph
+type paragraphLine struct {
+	text          string
+	endsParagraph bool
+}
+
+//ParagraphInBox : wrap text to width of box (upper left corner at current position) and draw lines that fit in height of box ,
+//hAlign is "left" (default "") , "center" , "right" or "justify" (last line of paragraph is left aligned) ,
+//vAlign is "top" (default "") , "middle" or "bottom" for the block of lines ,
+//return text that does not fit in box (empty if all text is drawn) , current y is moved below the box
+func (gp *GoPdf) ParagraphInBox(box Rect, text string, hAlign string, vAlign string) (string, error) {
+	var lines []paragraphLine
+	paragraphs := strings.Split(text, "\n")
+	for _, paragraph := range paragraphs {
+		wrapped, err := gp.splitTextToLines(paragraph, box.W)
+		if err != nil {
+			return "", err
+		}
+		for i, line := range wrapped {
+			lines = append(lines, paragraphLine{text: line, endsParagraph: i == len(wrapped)-1})
+		}
+	}
+
+	h := gp.autoLineHeight()
+	count := len(lines)
+	if max := int(box.H / h); count > max {
+		count = max
+	}
+	overflow := overflowText(lines[count:])
+
+	startX := gp.Curr.X
+	startY := gp.Curr.Y
+	blockH := float64(count) * h
+	switch vAlign {
+	case "middle":
+		gp.Curr.Y += (box.H - blockH) / 2
+	case "bottom":
+		gp.Curr.Y += box.H - blockH
+	}
+	for _, line := range lines[:count] {
+		err := gp.paragraphLine(line, startX, box.W, h, hAlign)
+		if err != nil {
+			return "", err
+		}
+		gp.Curr.Y += h
+	}
+	gp.Curr.X = startX
+	gp.Curr.Y = startY + box.H
+	return overflow, nil
+}
+
+//paragraphLine : draw line at x (left of box) with width w , justify by scaling advance of spaces
+func (gp *GoPdf) paragraphLine(line paragraphLine, x float64, w float64, h float64, hAlign string) error {
+	lineW, err := gp.MeasureTextWidth(line.text)
+	if err != nil {
+		return err
+	}
+	gp.Curr.X = x
+	switch hAlign {
+	case "center":
+		gp.Curr.X = x + (w-lineW)/2
+	case "right":
+		gp.Curr.X = x + w - lineW
+	case "justify":
+		spaces := 0
+		for _, r := range line.text {
+			if isSpaceRune(r) {
+				spaces++
+			}
+		}
+		spaceW, err := gp.MeasureTextWidth(" ")
+		if err != nil {
+			return err
+		}
+		if !line.endsParagraph && spaces > 0 && spaceW > 0 && lineW < w {
+			factor := gp.currSpaceWidthFactor()
+			//ขยาย space ให้บรรทัดเต็มความกว้าง (Tw ของ simple font , TJ ของ Type0 font ที่ไม่ใช้ Tw)
+			gp.spaceWidthFactor = factor * (1 + (w-lineW)/(float64(spaces)*spaceW))
+			gp.Cell(&Rect{W: w, H: h}, line.text)
+			gp.spaceWidthFactor = factor
+			return nil
+		}
+	}
+	gp.Cell(&Rect{W: lineW, H: h}, line.text)
+	return nil
+}
+
+//overflowText : text of lines that are not drawn , lines of same paragraph are joined by space
+func overflowText(lines []paragraphLine) string {
+	var buff strings.Builder
+	for i, line := range lines {
+		buff.WriteString(line.text)
+		if i < len(lines)-1 {
+			if line.endsParagraph {
+				buff.WriteString("\n")
+			} else {
+				buff.WriteString(" ")
+			}
+		}
+	}
+	return buff.String()
+}
@@ -71,18 +71,19 @@ func (me *PdfDictionaryObj) makeGlyfAndLocaTable() ([]byte, []int, error) {
 
 	numGlyphs := int(ttfp.NumGlyphs())
 
-	glyphs := me.completeGlyphClosure(me.PtrToSubsetFontObj.CharacterToGlyphIndex)
-	glyphCount := len(glyphs)
-	//copy
+	me.completeGlyphClosure(me.PtrToSubsetFontObj.CharacterToGlyphIndex)
+	//copy (glyph ที่ใช้ร่วมกันหลาย rune นับครั้งเดียว)
 	var glyphArray []int
-	for _, v := range me.PtrToSubsetFontObj.CharacterToGlyphIndex {
+	for _, v := range me.PtrToSubsetFontObj.UsedGlyphs() {
 		glyphArray = append(glyphArray, int(v))
 	}
-	sort.Ints(glyphArray)
+	glyphCount := len(glyphArray)
 
 	size := 0
 	for idx := 0; idx < glyphCount; idx++ {
-		size += me.getGlyphSize(glyphArray[idx])
+		if !me.isEmptyGlyph(glyphArray[idx]) {
+			size += me.getGlyphSize(glyphArray[idx])
+		}
 	}
 	glyf.Length = uint64(size)
 
@@ -95,6 +96,9 @@ func (me *PdfDictionaryObj) makeGlyfAndLocaTable() ([]byte, []int, error) {
 		locaTable[idx] = glyphOffset
 		if glyphIndex < glyphCount && glyphArray[glyphIndex] == idx {
 			glyphIndex++
+			if me.isEmptyGlyph(idx) {
+				continue
+			}
 			bytes := me.getGlyphData(idx)
 			length := len(bytes)
 			if length > 0 {
@@ -109,6 +113,11 @@ func (me *PdfDictionaryObj) makeGlyfAndLocaTable() ([]byte, []int, error) {
 	return glyphTable, locaTable, nil
 }
 
+//isEmptyGlyph : glyph is in subset without outline (.notdef of SubsetOption.ReplaceNotdefWithEmpty)
+func (me *PdfDictionaryObj) isEmptyGlyph(glyph int) bool {
+	return glyph == 0 && me.PtrToSubsetFontObj.subsetOption.ReplaceNotdefWithEmpty
+}
+
 func (me *PdfDictionaryObj) getGlyphSize(glyph int) int {
 
 	ttfp := me.PtrToSubsetFontObj.GetTTFParser()
@@ -137,15 +146,30 @@ func (me *PdfDictionaryObj) makeFont() ([]byte, error) {
 	var buff Buff
 	ttfp := me.PtrToSubsetFontObj.GetTTFParser()
 	tables := make(map[string]core.TableDirectoryEntry)
-	tables["cvt "] = ttfp.GetTables()["cvt "] //มีช่องว่างด้วยนะ
-	tables["fpgm"] = ttfp.GetTables()["fpgm"]
 	tables["glyf"] = ttfp.GetTables()["glyf"]
 	tables["head"] = ttfp.GetTables()["head"]
 	tables["hhea"] = ttfp.GetTables()["hhea"]
 	tables["hmtx"] = ttfp.GetTables()["hmtx"]
 	tables["loca"] = ttfp.GetTables()["loca"]
 	tables["maxp"] = ttfp.GetTables()["maxp"]
-	tables["prep"] = ttfp.GetTables()["prep"]
+	//hinting ไม่ขึ้นกับ glyph id จึง copy ได้ทั้ง table
+	if !me.PtrToSubsetFontObj.subsetOption.StripHinting {
+		for _, tag := range []string{"cvt ", "fpgm", "prep"} { //"cvt " มีช่องว่างด้วยนะ
+			if ttfp.HasTable(tag) {
+				tables[tag] = ttfp.GetTables()[tag]
+			}
+		}
+	}
+	//gasp ไม่ขึ้นกับ glyph id เช่นกัน
+	if ttfp.HasTable("gasp") {
+		tables["gasp"] = ttfp.GetTables()["gasp"]
+	}
+	//simple TrueType font หา glyph จาก unicode ของ WinAnsiEncoding ผ่าน cmap
+	var cmapTable []byte
+	if me.PtrToSubsetFontObj.isSimple() {
+		cmapTable = me.PtrToSubsetFontObj.simpleCmapTable()
+		tables["cmap"] = core.TableDirectoryEntry{}
+	}
 	tableCount := len(tables)
 	selector := EntrySelectors[tableCount]
 
@@ -176,6 +200,10 @@ func (me *PdfDictionaryObj) makeFont() ([]byte, error) {
 			entry.Length = uint64(len(glyphTable))
 			entry.CheckSum = CheckSum(glyphTable)
 			WriteBytes(&buff, glyphTable, 0, entry.PaddedLength())
+		} else if tags[idx] == "cmap" && cmapTable != nil {
+			entry.Length = uint64(len(cmapTable))
+			entry.CheckSum = CheckSum(cmapTable)
+			WriteBytes(&buff, cmapTable, 0, len(cmapTable))
 		} else if tags[idx] == "loca" {
 			if ttfp.IsShortIndex {
 				entry.Length = uint64(len(locaTable) * 2)
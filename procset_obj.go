@@ -10,7 +10,9 @@ type ProcSetObj struct {
 	//Font
 	Realtes     RelateFonts
 	RealteXobjs RealteXobjects
-	getRoot     func() *GoPdf
+	//resource อื่นๆ ที่ user เพิ่มเอง (AddResource)
+	RealteResources RealteResources
+	getRoot         func() *GoPdf
 }
 
 func (me *ProcSetObj) Init(funcGetRoot func() *GoPdf) {
@@ -18,10 +20,19 @@ func (me *ProcSetObj) Init(funcGetRoot func() *GoPdf) {
 }
 
 func (me *ProcSetObj) Build() error {
+	me.BuildResources(&me.buffer, nil)
+	return nil
+}
+
+//BuildResources : write resources dictionary that contains only resources in names (nil = all resources)
+func (me *ProcSetObj) BuildResources(buff *bytes.Buffer, names map[string]bool) {
+	used := func(name string) bool {
+		return names == nil || names[name]
+	}
 
-	me.buffer.WriteString("<<\n")
-	me.buffer.WriteString("/ProcSet [/PDF /Text /ImageB /ImageC /ImageI]\n")
-	me.buffer.WriteString("/Font <<\n")
+	buff.WriteString("<<\n")
+	buff.WriteString("/ProcSet [/PDF /Text /ImageB /ImageC /ImageI]\n")
+	buff.WriteString("/Font <<\n")
 	//me.buffer.WriteString("/F1 9 0 R
 	//me.buffer.WriteString("/F2 12 0 R
 	//me.buffer.WriteString("/F3 15 0 R
@@ -29,21 +40,43 @@ func (me *ProcSetObj) Build() error {
 	max := len(me.Realtes)
 	for i < max {
 		realte := me.Realtes[i]
-		me.buffer.WriteString(fmt.Sprintf("      /F%d %d 0 R\n", realte.CountOfFont+1, realte.IndexOfObj+1))
+		name := fmt.Sprintf("F%d", realte.CountOfFont+1)
+		if used(name) {
+			buff.WriteString(fmt.Sprintf("      /%s %d 0 R\n", name, realte.IndexOfObj+1))
+		}
 		i++
 	}
-	me.buffer.WriteString(">>\n")
-	me.buffer.WriteString("/XObject <<\n")
+	me.buildRealteResources(buff, "Font", used)
+	buff.WriteString(">>\n")
+	buff.WriteString("/XObject <<\n")
 	i = 0
 	max = len(me.RealteXobjs)
 	for i < max {
-		me.buffer.WriteString(fmt.Sprintf("/I%d %d 0 R\n", me.getRoot().Curr.CountOfL+1, me.RealteXobjs[i].IndexOfObj+1))
-		me.getRoot().Curr.CountOfL++
+		name := fmt.Sprintf("I%d", i+1)
+		if used(name) {
+			buff.WriteString(fmt.Sprintf("/%s %d 0 R\n", name, me.RealteXobjs[i].IndexOfObj+1))
+		}
 		i++
 	}
-	me.buffer.WriteString(">>\n")
-	me.buffer.WriteString(">>\n")
-	return nil
+	me.buildRealteResources(buff, "XObject", used)
+	buff.WriteString(">>\n")
+	for _, kind := range me.RealteResources.Kinds() {
+		if kind == "Font" || kind == "XObject" {
+			continue
+		}
+		buff.WriteString("/" + kind + " <<\n")
+		me.buildRealteResources(buff, kind, used)
+		buff.WriteString(">>\n")
+	}
+	buff.WriteString(">>\n")
+}
+
+func (me *ProcSetObj) buildRealteResources(buff *bytes.Buffer, kind string, used func(name string) bool) {
+	for _, realte := range me.RealteResources {
+		if realte.Kind == kind && used(realte.Name) {
+			buff.WriteString(fmt.Sprintf("/%s %d 0 R\n", realte.Name, realte.IndexOfObj+1))
+		}
+	}
 }
 
 func (me *ProcSetObj) GetType() string {
@@ -81,3 +114,40 @@ type RealteXobjects []RealteXobject
 type RealteXobject struct {
 	IndexOfObj int
 }
+
+type RealteResources []RealteResource
+
+//Kinds : kind of resources in order of first use
+func (me *RealteResources) Kinds() []string {
+	var kinds []string
+	found := make(map[string]bool)
+	for _, realte := range *me {
+		if !found[realte.Kind] {
+			found[realte.Kind] = true
+			kinds = append(kinds, realte.Kind)
+		}
+	}
+	return kinds
+}
+
+type RealteResource struct {
+	//etc ExtGState , Pattern , Shading , ColorSpace
+	Kind string
+	//etc GS1
+	Name string
+	//etc  5 0 R
+	IndexOfObj int
+}
+
+//procSetObjSkips : shared resources obj is not written , every page writes only resources that it uses (see PageObj)
+func (gp *GoPdf) procSetObjSkips(skips map[int]bool) map[int]bool {
+	if gp.indexOfProcSet == -1 {
+		return skips
+	}
+	merged := make(map[int]bool)
+	for index := range skips {
+		merged[index] = true
+	}
+	merged[gp.indexOfProcSet] = true
+	return merged
+}
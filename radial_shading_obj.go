@@ -0,0 +1,67 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//RadialGradientOption : option of RadialGradient
+type RadialGradientOption struct {
+	//ExtendStart : fill inside the inner circle with c1
+	ExtendStart bool
+	//ExtendEnd : fill outside the outer circle with c2 (paint in Clip)
+	ExtendEnd bool
+	//Clip : rectangle (ClipX,ClipY is the upper left corner) that gradient is painted in , nil = outer circle
+	Clip         *Rect
+	ClipX, ClipY float64
+}
+
+//RadialShadingObj : radial shading (ShadingType 3) from color c1 at circle r0 to c2 at circle r1 , circles are concentric at cx,cy (pdf space)
+type RadialShadingObj struct { //impl IObj
+	buffer      bytes.Buffer
+	cx, cy      float64
+	r0, r1      float64
+	c1, c2      [3]uint8
+	extendStart bool
+	extendEnd   bool
+	getRoot     func() *GoPdf
+}
+
+func (r *RadialShadingObj) Init(funcGetRoot func() *GoPdf) {
+	r.getRoot = funcGetRoot
+}
+
+func (r *RadialShadingObj) Build() error {
+	r.buffer.WriteString("<<\n")
+	r.buffer.WriteString("/ShadingType 3\n")
+	colorSpace, c0, c1 := "DeviceRGB", rgbComponents(r.c1), rgbComponents(r.c2)
+	if r.getRoot != nil && r.getRoot().isGrayscaleOutput {
+		//SetGrayscaleOutput : สีของ shading เป็นเทาด้วย
+		colorSpace, c0, c1 = "DeviceGray", grayComponent(r.c1), grayComponent(r.c2)
+	}
+	r.buffer.WriteString("/ColorSpace /" + colorSpace + "\n")
+	r.buffer.WriteString(fmt.Sprintf("/Coords [%0.2f %0.2f %0.2f %0.2f %0.2f %0.2f]\n", r.cx, r.cy, r.r0, r.cx, r.cy, r.r1))
+	//exponential interpolation function (FunctionType 2) จาก c1 ไป c2
+	r.buffer.WriteString(fmt.Sprintf("/Function << /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>\n", c0, c1))
+	r.buffer.WriteString(fmt.Sprintf("/Extend [%t %t]\n", r.extendStart, r.extendEnd))
+	r.buffer.WriteString(">>\n")
+	return nil
+}
+
+func (r *RadialShadingObj) GetType() string {
+	return "Shading"
+}
+
+func (r *RadialShadingObj) GetObjBuff() *bytes.Buffer {
+	return &(r.buffer)
+}
+
+//rgbComponents : r g b of color in 0.0-1.0
+func rgbComponents(c [3]uint8) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c[0])/255.0, float64(c[1])/255.0, float64(c[2])/255.0)
+}
+
+//grayComponent : luminance of color in 0.0-1.0 (same as grayscale of content stream)
+func grayComponent(c [3]uint8) string {
+	return fmt.Sprintf("%.3f", fixRange10(luminance(float64(c[0])/255.0, float64(c[1])/255.0, float64(c[2])/255.0)))
+}
@@ -4,3 +4,9 @@ type Rect struct {
 	W float64
 	H float64
 }
+
+//Point : position (y from top of page)
+type Point struct {
+	X float64
+	Y float64
+}
@@ -0,0 +1,108 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+//RichColor : rgb color (0-255) of RichSegment
+type RichColor struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+//RichSegment : run of text in RichText
+type RichSegment struct {
+	Text string
+	//Family , Style , Size : font of this run ("" or 0 = same as font when RichText is called)
+	Family string
+	Style  string
+	Size   int
+	//FillColor : color of this run (nil = current fill color)
+	FillColor *RichColor
+}
+
+//RichText : draw segments on one line start at current position in one text object , each segment can change font and color ,
+//font , font size and fill color are restored after the line
+func (gp *GoPdf) RichText(segments []RichSegment) error {
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET && gp.Curr.Font_ISubset == nil ||
+		gp.Curr.Font_Type == CURRENT_FONT_TYPE_IFONT && gp.Curr.Font_IFont == nil {
+		return ErrFontNotSet
+	}
+	curr := gp.Curr
+	defer func() {
+		gp.Curr.Font_Size = curr.Font_Size
+		gp.Curr.Font_Style = curr.Font_Style
+		gp.Curr.Font_FontCount = curr.Font_FontCount
+		gp.Curr.Font_Type = curr.Font_Type
+		gp.Curr.Font_IFont = curr.Font_IFont
+		gp.Curr.Font_ISubset = curr.Font_ISubset
+	}()
+
+	content := gp.getContent()
+	baseline := gp.config.PageSize.H - gp.cellBaseline(nil)
+	var buff bytes.Buffer
+	buff.WriteString("q\n")
+	buff.WriteString("BT\n")
+	buff.WriteString(fmt.Sprintf("%0.2f %0.2f TD\n", gp.Curr.X, baseline))
+	sumWidth := 0.0
+	currFamily := gp.currFontFamily()
+	for _, segment := range segments {
+		family, style, size := segment.Family, segment.Style, segment.Size
+		if family == "" {
+			family = currFamily
+		}
+		if style == "" {
+			style = curr.Font_Style
+		}
+		if size == 0 {
+			size = curr.Font_Size
+		}
+		if family != gp.currFontFamily() || style != gp.Curr.Font_Style || size != gp.Curr.Font_Size {
+			err := gp.SetFont(family, style, size)
+			if err != nil {
+				return err
+			}
+		}
+		buff.WriteString("/F" + strconv.Itoa(gp.Curr.Font_FontCount+1) + " " + strconv.Itoa(gp.Curr.Font_Size) + " Tf\n")
+		if segment.FillColor != nil {
+			color := segment.FillColor
+			buff.WriteString(gp.colorOperator(color.R, color.G, color.B, false))
+		}
+		if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
+			gp.Curr.Font_ISubset.AddChars(segment.Text)
+			textOp, width := content.subsetFontTextOperator(segment.Text)
+			buff.WriteString(textOp)
+			sumWidth += width * float64(gp.Curr.Font_Size) / 1000.0
+		} else {
+			width, err := gp.MeasureTextWidth(segment.Text)
+			if err != nil {
+				return err
+			}
+			if wordSpacing := gp.wordSpacing(); wordSpacing != 0 {
+				buff.WriteString(fmt.Sprintf("%0.2f Tw\n", wordSpacing))
+			}
+			buff.WriteString("(" + escapePdfString(gp.currEncodingObj().EncodeText(segment.Text)) + ") Tj\n")
+			sumWidth += width
+		}
+	}
+	buff.WriteString("ET\n")
+	buff.WriteString("Q\n")
+	content.AppendStreamRaw(buff.String())
+	gp.Curr.X += sumWidth
+	return nil
+}
+
+//currFontFamily : family of current font
+func (gp *GoPdf) currFontFamily() string {
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET {
+		if sub, ok := gp.Curr.Font_ISubset.(*SubsetFontObj); ok {
+			return sub.GetFamily()
+		}
+	} else if gp.Curr.Font_IFont != nil {
+		return gp.Curr.Font_IFont.GetFamily()
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package gopdf
+
+//SignatureLineOption : option of SignatureLine
+type SignatureLineOption struct {
+	//DateWidth : width of date line drawn at right of signature line (0 = no date line)
+	DateWidth float64
+	//DateLabel : label under date line ("" = "Date")
+	DateLabel string
+	//Gap : space between signature line and date line (0 = 20)
+	Gap float64
+}
+
+//SignatureLine : draw rule from x to x + width at y with label (sample "Signature") under it in current font at 70% of its size ,
+//opts[0] add date line at right of the rule , current position and font size are not changed
+func (gp *GoPdf) SignatureLine(x float64, y float64, width float64, label string, opts ...SignatureLineOption) error {
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET && gp.Curr.Font_ISubset == nil ||
+		gp.Curr.Font_Type == CURRENT_FONT_TYPE_IFONT && gp.Curr.Font_IFont == nil {
+		return ErrFontNotSet
+	}
+	var opt SignatureLineOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	curr := gp.Curr
+	defer func() {
+		gp.Curr.X = curr.X
+		gp.Curr.Y = curr.Y
+		gp.Curr.Font_Size = curr.Font_Size
+	}()
+	labelSize := curr.Font_Size * 7 / 10
+	if labelSize < 1 {
+		labelSize = 1
+	}
+
+	drawLine := func(x float64, width float64, label string) {
+		gp.Line(x, y, x+width, y)
+		if label == "" {
+			return
+		}
+		gp.Curr.Font_Size = labelSize
+		gp.Curr.X = x
+		gp.Curr.Y = y + 2 //เว้นจากเส้นเล็กน้อย
+		gp.Cell(nil, label)
+	}
+	drawLine(x, width, label)
+	if opt.DateWidth > 0 {
+		gap := opt.Gap
+		if gap <= 0 {
+			gap = 20
+		}
+		dateLabel := opt.DateLabel
+		if dateLabel == "" {
+			dateLabel = "Date"
+		}
+		drawLine(x+width+gap, opt.DateWidth, dateLabel)
+	}
+	return nil
+}
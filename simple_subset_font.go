@@ -0,0 +1,241 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+//winAnsiCode : WinAnsiEncoding code of r , false if r is not in WinAnsiEncoding (or is control char)
+func winAnsiCode(r rune) (byte, bool) {
+	if r >= 0x20 && r < 0x7F {
+		return byte(r), true
+	}
+	if code, ok := winAnsiEncoding[r]; ok {
+		return code, true
+	}
+	if r >= 0xA0 && r <= 0xFF {
+		return byte(r), true
+	}
+	return 0, false
+}
+
+//isSimple : font is written as simple TrueType font (FontOption.PreferSimple and every drawn rune is in WinAnsiEncoding)
+func (s *SubsetFontObj) isSimple() bool {
+	if !s.preferSimple || len(s.substitutedGlyphs) > 0 || len(s.CharacterToGlyphIndex) == 0 {
+		return false
+	}
+	for r := range s.CharacterToGlyphIndex {
+		//rune 0 คือ .notdef ที่ใส่ไว้ตอนสร้าง font file
+		if _, ok := winAnsiCode(r); !ok && r != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//simpleCodes : WinAnsiEncoding code of each glyph of simple font (lowest code if many runes use same glyph)
+func (s *SubsetFontObj) simpleCodes() map[uint64]byte {
+	codes := make(map[uint64]byte)
+	for r, glyphIndex := range s.CharacterToGlyphIndex {
+		code, ok := winAnsiCode(r)
+		if !ok {
+			continue
+		}
+		if c, ok := codes[glyphIndex]; !ok || code < c {
+			codes[glyphIndex] = code
+		}
+	}
+	return codes
+}
+
+//buildSimple : font dictionary of simple TrueType font
+func (s *SubsetFontObj) buildSimple() {
+	widths := make(map[byte]uint64)
+	firstChar, lastChar := 255, 0
+	for r, glyphIndex := range s.CharacterToGlyphIndex {
+		code, ok := winAnsiCode(r)
+		if !ok {
+			continue
+		}
+		widths[code] = s.glyphWidthOfRune(r, glyphIndex)
+		if int(code) < firstChar {
+			firstChar = int(code)
+		}
+		if int(code) > lastChar {
+			lastChar = int(code)
+		}
+	}
+	s.buffer.WriteString("<<\n")
+	s.buffer.WriteString("/Type /Font\n")
+	s.buffer.WriteString("/Subtype /TrueType\n")
+	s.buffer.WriteString(fmt.Sprintf("/BaseFont /%s\n", s.GetSubsetFontName()))
+	s.buffer.WriteString(fmt.Sprintf("/FirstChar %d\n", firstChar))
+	s.buffer.WriteString(fmt.Sprintf("/LastChar %d\n", lastChar))
+	s.buffer.WriteString("/Widths [")
+	for code := firstChar; code <= lastChar; code++ {
+		if code > firstChar {
+			s.buffer.WriteString(" ")
+		}
+		s.buffer.WriteString(strconv.FormatUint(widths[byte(code)], 10))
+	}
+	s.buffer.WriteString("]\n")
+	s.buffer.WriteString("/Encoding /WinAnsiEncoding\n")
+	s.buffer.WriteString(fmt.Sprintf("/FontDescriptor %d 0 R\n", s.indexObjFontDescriptor+1))
+	s.buffer.WriteString(fmt.Sprintf("/ToUnicode %d 0 R\n", s.indexObjUnicodeMap+1))
+	s.buffer.WriteString(">>\n")
+}
+
+//simpleCmapTable : cmap table (windows unicode , format 4) of runes of simple font ,
+//viewer find glyph of code by unicode of WinAnsiEncoding
+func (s *SubsetFontObj) simpleCmapTable() []byte {
+	var runes []rune
+	for r := range s.CharacterToGlyphIndex {
+		if _, ok := winAnsiCode(r); ok {
+			runes = append(runes, r)
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	segCount := len(runes) + 1 //segment ละ rune และ segment 0xFFFF ปิดท้าย
+	entrySelector := 0
+	for (2 << uint(entrySelector)) <= segCount {
+		entrySelector++
+	}
+	searchRange := 2 << uint(entrySelector)
+
+	var buff bytes.Buffer
+	WriteUInt16(&buff, 0) //version
+	WriteUInt16(&buff, 1)
+	WriteUInt16(&buff, 3) //windows
+	WriteUInt16(&buff, 1) //unicode BMP
+	WriteUInt32(&buff, 12)
+	WriteUInt16(&buff, 4)
+	WriteUInt16(&buff, uint(16+8*segCount))
+	WriteUInt16(&buff, 0)
+	WriteUInt16(&buff, uint(segCount*2))
+	WriteUInt16(&buff, uint(searchRange))
+	WriteUInt16(&buff, uint(entrySelector))
+	WriteUInt16(&buff, uint(segCount*2-searchRange))
+	for _, r := range runes {
+		WriteUInt16(&buff, uint(r))
+	}
+	WriteUInt16(&buff, 0xFFFF)
+	WriteUInt16(&buff, 0) //reservedPad
+	for _, r := range runes {
+		WriteUInt16(&buff, uint(r))
+	}
+	WriteUInt16(&buff, 0xFFFF)
+	for _, r := range runes {
+		WriteUInt16(&buff, uint((int(s.CharacterToGlyphIndex[r])-int(r))&0xFFFF))
+	}
+	WriteUInt16(&buff, 1)
+	for i := 0; i < segCount; i++ {
+		WriteUInt16(&buff, 0) //idRangeOffset
+	}
+	for buff.Len()%4 != 0 {
+		buff.WriteByte(0)
+	}
+	return buff.Bytes()
+}
+
+//simpleSubsetFonts : subset fonts written as simple TrueType font by name of font resource (sample "F1")
+func (gp *GoPdf) simpleSubsetFonts() map[string]*SubsetFontObj {
+	if gp.indexOfProcSet == -1 {
+		return nil
+	}
+	var fonts map[string]*SubsetFontObj
+	for _, realte := range gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj).Realtes {
+		if sub, ok := gp.pdfObjs[realte.IndexOfObj].(*SubsetFontObj); ok && sub.isSimple() {
+			if fonts == nil {
+				fonts = make(map[string]*SubsetFontObj)
+			}
+			fonts[fmt.Sprintf("F%d", realte.CountOfFont+1)] = sub
+		}
+	}
+	return fonts
+}
+
+//simpleFontSkips : cid font objs of simple TrueType fonts are not written
+func (gp *GoPdf) simpleFontSkips(skips map[int]bool) map[int]bool {
+	fonts := gp.simpleSubsetFonts()
+	if len(fonts) == 0 {
+		return skips
+	}
+	merged := make(map[int]bool)
+	for index := range skips {
+		merged[index] = true
+	}
+	for _, sub := range fonts {
+		merged[sub.indexObjCIDFont] = true
+	}
+	return merged
+}
+
+//simpleFontStream : glyph indexes (2 bytes) of text in simple TrueType fonts are replaced by codes (1 byte) of WinAnsiEncoding
+func (gp *GoPdf) simpleFontStream(stream []byte) []byte {
+	fonts := gp.simpleSubsetFonts()
+	if len(fonts) == 0 {
+		return stream
+	}
+	var result bytes.Buffer
+	var codes map[uint64]byte
+	lastName := ""
+	i := 0
+	max := len(stream)
+	for i < max {
+		ch := stream[i]
+		start := i
+		switch {
+		case ch == '<' && (i+1 >= max || stream[i+1] != '<'):
+			i = skipPdfOperand(stream, i)
+			if codes == nil {
+				result.Write(stream[start:i])
+				continue
+			}
+			result.WriteString(simpleFontHexText(stream[start+1:i-1], codes))
+			continue
+		case ch == '(' || ch == '<':
+			i = skipPdfOperand(stream, i)
+		case ch == '/':
+			i = skipPdfOperand(stream, i)
+			lastName = string(stream[start+1 : i])
+		case isPdfWhiteSpace(ch) || isPdfDelimiter(ch):
+			i++
+		default:
+			for i < max && !isPdfWhiteSpace(stream[i]) && !isPdfDelimiter(stream[i]) {
+				i++
+			}
+			if string(stream[start:i]) == "Tf" {
+				codes = nil
+				if sub, ok := fonts[lastName]; ok {
+					codes = sub.simpleCodes()
+				}
+			}
+		}
+		result.Write(stream[start:i])
+	}
+	return result.Bytes()
+}
+
+//simpleFontHexText : hex string of codes of glyph indexes in hex (4 digits each)
+func simpleFontHexText(hex []byte, codes map[uint64]byte) string {
+	var buff bytes.Buffer
+	buff.WriteString("<")
+	for i := 0; i+4 <= len(hex); i += 4 {
+		glyphIndex, err := strconv.ParseUint(string(hex[i:i+4]), 16, 16)
+		if err != nil {
+			continue
+		}
+		buff.WriteString(fmt.Sprintf("%02X", codes[glyphIndex]))
+	}
+	buff.WriteString(">")
+	return buff.String()
+}
+
+//simpleFontFlags : flags of simple TrueType font (nonsymbolic , glyphs are found by WinAnsiEncoding)
+func simpleFontFlags(flags int) int {
+	return flags&^core.Symbolic | core.Nonsymbolic
+}
@@ -0,0 +1,269 @@
+package gopdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+//scriptSegment : run of text in one script
+type scriptSegment struct {
+	//script : "Latin" , "Arabic" , "Hebrew" , "CJK" , "Other" (letters of other scripts) , "Number" (digits) ,
+	//"" (space , punctuation)
+	script string
+	text   []rune
+	//level : bidi embedding level (odd = right to left)
+	level int
+}
+
+//SetScriptFont : font family (added by AddTTFFont ...) that SmartWrite use for text in script "Latin" , "Arabic" , "Hebrew" or "CJK" ,
+//text in script without font is drawn with current font
+func (gp *GoPdf) SetScriptFont(script string, family string) {
+	if gp.scriptFonts == nil {
+		gp.scriptFonts = make(map[string]string)
+	}
+	gp.scriptFonts[script] = family
+}
+
+//SmartWrite : draw text at x,y split into runs of script , each run is drawn with font of SetScriptFont ,
+//runs are put in visual order (bidi) so arabic and hebrew text is right to left inside left to right text (and vice versa) ,
+//current font is not changed and current x is moved to end of text (contextual shaping of arabic is not done ,
+//there is no fallback font : rune that font of its script does not have is drawn with that font , see MissingRunes)
+func (gp *GoPdf) SmartWrite(x float64, y float64, text string) error {
+	segments := visualOrder(scriptSegments(text))
+	curr := gp.Curr
+	gp.Curr.X = x
+	gp.Curr.Y = y
+	for i, segment := range segments {
+		family := gp.segmentFamily(segments, i)
+		if family != "" {
+			if err := gp.SetFont(family, curr.Font_Style, curr.Font_Size); err != nil {
+				return err
+			}
+		} else {
+			gp.restoreFont(curr)
+		}
+		gp.Cell(nil, string(segment.text))
+	}
+	gp.restoreFont(curr)
+	return nil
+}
+
+//segmentFamily : family that SmartWrite draw segment i (visual order) with , "" = current font
+func (gp *GoPdf) segmentFamily(segments []scriptSegment, i int) string {
+	family := gp.scriptFonts[segments[i].script]
+	if segments[i].script == "" || segments[i].script == "Number" {
+		//space และตัวเลขใช้ font ของ run ที่อยู่ก่อนหน้า
+		for j := i - 1; j >= 0 && family == ""; j-- {
+			family = gp.scriptFonts[segments[j].script]
+		}
+	}
+	return family
+}
+
+//restoreFont : set font of curr back to current font
+func (gp *GoPdf) restoreFont(curr Current) {
+	gp.Curr.Font_Size = curr.Font_Size
+	gp.Curr.Font_Style = curr.Font_Style
+	gp.Curr.Font_FontCount = curr.Font_FontCount
+	gp.Curr.Font_Type = curr.Font_Type
+	gp.Curr.Font_IFont = curr.Font_IFont
+	gp.Curr.Font_ISubset = curr.Font_ISubset
+}
+
+//scriptOf : script of rune ("" if rune has no strong script)
+func scriptOf(r rune) string {
+	switch {
+	case unicode.Is(unicode.Arabic, r):
+		return "Arabic"
+	case unicode.Is(unicode.Hebrew, r):
+		return "Hebrew"
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return "CJK"
+	case unicode.Is(unicode.Latin, r):
+		return "Latin"
+	case unicode.IsLetter(r):
+		//script อื่น (ไทย ...) เขียนซ้ายไปขวา ใช้ font ปัจจุบัน
+		return "Other"
+	case unicode.IsDigit(r):
+		return "Number"
+	}
+	return ""
+}
+
+//isRTLScript : script is written right to left
+func isRTLScript(script string) bool {
+	return script == "Arabic" || script == "Hebrew"
+}
+
+//scriptSegments : runs of same script in logical order with bidi level ,
+//numbers are left to right inside right to left text , runs without script (space , punctuation ...) take direction of
+//runs around them if both runs have same direction else direction of text
+func scriptSegments(text string) []scriptSegment {
+	var segments []scriptSegment
+	for _, r := range text {
+		script := scriptOf(r)
+		if n := len(segments); n > 0 && segments[n-1].script == script {
+			segments[n-1].text = append(segments[n-1].text, r)
+			continue
+		}
+		segments = append(segments, scriptSegment{script: script, text: []rune{r}})
+	}
+	//ตัวคั่นตัวเดียวระหว่างตัวเลข (1,234.56) เป็นส่วนของตัวเลข
+	for i := 1; i < len(segments)-1; i++ {
+		if segments[i].script == "" && len(segments[i].text) == 1 && strings.ContainsRune(".,:/+-", segments[i].text[0]) &&
+			segments[i-1].script == "Number" && segments[i+1].script == "Number" {
+			segments[i-1].text = append(append(segments[i-1].text, segments[i].text...), segments[i+1].text...)
+			segments = append(segments[:i], segments[i+2:]...)
+			i--
+		}
+	}
+
+	//ทิศทางหลักมาจาก script แรกที่มีทิศทาง
+	base := 0
+	for _, segment := range segments {
+		if segment.script != "" && segment.script != "Number" {
+			if isRTLScript(segment.script) {
+				base = 1
+			}
+			break
+		}
+	}
+	//ทิศทางของแต่ละ run (true = ขวาไปซ้าย) , ตัวเลขมีทิศทางเดียวกับ script ที่อยู่ก่อนหน้า
+	rtls := make([]bool, len(segments))
+	lastRTL := base == 1
+	for i, segment := range segments {
+		switch segment.script {
+		case "":
+		case "Number":
+			rtls[i] = lastRTL
+		default:
+			rtls[i] = isRTLScript(segment.script)
+			lastRTL = rtls[i]
+		}
+	}
+	for i, segment := range segments {
+		switch {
+		case segment.script == "":
+			//ใช้ทิศทางของ run ที่มีทิศทางก่อนและหลัง (ต้นและท้ายข้อความใช้ทิศทางหลัก)
+			before, after := base == 1, base == 1
+			for j := i - 1; j >= 0; j-- {
+				if segments[j].script != "" {
+					before = rtls[j]
+					break
+				}
+			}
+			for j := i + 1; j < len(segments); j++ {
+				if segments[j].script != "" {
+					after = rtls[j]
+					break
+				}
+			}
+			if before != after {
+				segments[i].level = base
+			} else if before {
+				segments[i].level = 1
+			} else if base == 1 {
+				segments[i].level = 2
+			}
+		case segment.script == "Number":
+			//ตัวเลขเขียนซ้ายไปขวาเสมอ
+			if rtls[i] || base == 1 {
+				segments[i].level = 2
+			}
+		case rtls[i]:
+			segments[i].level = 1
+		case base == 1:
+			segments[i].level = 2
+		}
+	}
+	return segments
+}
+
+//visualOrder : reverse runs from highest level down to level 1 (rule L2 of unicode bidi) , text of right to left runs is reversed
+func visualOrder(segments []scriptSegment) []scriptSegment {
+	maxLevel := 0
+	for _, segment := range segments {
+		if segment.level > maxLevel {
+			maxLevel = segment.level
+		}
+	}
+	for level := maxLevel; level >= 1; level-- {
+		for start := 0; start < len(segments); {
+			if segments[start].level < level {
+				start++
+				continue
+			}
+			end := start
+			for end < len(segments) && segments[end].level >= level {
+				end++
+			}
+			for i, j := start, end-1; i < j; i, j = i+1, j-1 {
+				segments[i], segments[j] = segments[j], segments[i]
+			}
+			start = end
+		}
+	}
+	for _, segment := range segments {
+		if segment.level%2 == 1 {
+			for i, j := 0, len(segment.text)-1; i < j; i, j = i+1, j-1 {
+				segment.text[i], segment.text[j] = segment.text[j], segment.text[i]
+			}
+		}
+	}
+	return segments
+}
+
+//CanRender : font that SmartWrite draw r alone with (font of SetScriptFont of script of r or current font) has glyph of r
+//(space is always true , it has width without glyph)
+func (gp *GoPdf) CanRender(r rune) bool {
+	return len(gp.MissingRunes(string(r))) == 0
+}
+
+//MissingRunes : runes of text (once each , in order of text) that font SmartWrite draw them with does not have
+func (gp *GoPdf) MissingRunes(text string) []rune {
+	//font ของแต่ละ run เหมือนกับที่ SmartWrite เลือก
+	notFound := make(map[rune]bool)
+	segments := visualOrder(scriptSegments(text))
+	for i, segment := range segments {
+		family := gp.segmentFamily(segments, i)
+		for _, r := range segment.text {
+			if !notFound[r] && !isSpaceRune(r) && !gp.fontHasRune(family, r) {
+				notFound[r] = true
+			}
+		}
+	}
+	var missing []rune
+	found := make(map[rune]bool)
+	for _, r := range text {
+		if found[r] || !notFound[r] {
+			continue
+		}
+		found[r] = true
+		missing = append(missing, r)
+	}
+	return missing
+}
+
+//fontHasRune : font family ("" = current font) has glyph of r , false if family is not found
+func (gp *GoPdf) fontHasRune(family string, r rune) bool {
+	curr := gp.Curr
+	defer gp.restoreFont(curr)
+	if family != "" {
+		if err := gp.SetFont(family, curr.Font_Style, curr.Font_Size); err != nil {
+			return false
+		}
+	}
+	if gp.Curr.Font_Type == CURRENT_FONT_TYPE_SUBSET && gp.Curr.Font_ISubset != nil {
+		return gp.Curr.Font_ISubset.CharCodeToGlyphIndex(r) != 0
+	}
+	if gp.Curr.Font_IFont == nil {
+		return false
+	}
+	//simple font : char code ตาม encoding (และ Differences) ของ font
+	code := gp.currEncodingObj().EncodeText(string(r))
+	if len(code) != 1 || code[0] == '?' && r != '?' {
+		return false
+	}
+	_, ok := gp.Curr.Font_IFont.GetCw()[code[0]]
+	return ok
+}
@@ -1,5 +1,14 @@
 package gopdf
 
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
 func StrHelperGetStringWidth(str string, fontSize int, ifont IFont) float64 {
 	w := 0
 	bs := []byte(str)
@@ -12,7 +21,53 @@ func StrHelperGetStringWidth(str string, fontSize int, ifont IFont) float64 {
 	return float64(w) * (float64(fontSize) / 1000.0)
 }
 
-func CreateEmbeddedFontSubsetName(name string) string {
-	//TODO ทำด้วย  :-)
-	return name
+//CreateEmbeddedFontSubsetName : name of embedded subset font with six uppercase letters tag (sample ABCDEF+Loma) ,
+//tag is derived from name and glyphIndexes (same glyphs = same tag) , no glyphIndexes = plain name
+func CreateEmbeddedFontSubsetName(name string, glyphIndexes ...uint64) string {
+	if len(glyphIndexes) == 0 {
+		return name
+	}
+	sorted := append([]uint64(nil), glyphIndexes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	for _, glyphIndex := range sorted {
+		h.Write([]byte{byte(glyphIndex >> 8), byte(glyphIndex)})
+	}
+	sum := h.Sum64()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = byte('A' + sum%26)
+		sum /= 26
+	}
+	return string(tag) + "+" + name
+}
+
+//pdfTextString : text string of pdf (title , name ...) , literal string if str is ascii else UTF-16BE hex string with BOM
+func pdfTextString(str string) string {
+	isASCII := true
+	for _, r := range str {
+		if r >= 0x80 {
+			isASCII = false
+			break
+		}
+	}
+	if isASCII {
+		return "(" + escapePdfString(str) + ")"
+	}
+	var buff bytes.Buffer
+	buff.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(str)) {
+		buff.WriteString(fmt.Sprintf("%04X", u))
+	}
+	buff.WriteString(">")
+	return buff.String()
+}
+
+//escapePdfString : escape \ ( ) of pdf literal string
+func escapePdfString(str string) string {
+	str = strings.Replace(str, "\\", "\\\\", -1)
+	str = strings.Replace(str, "(", "\\(", -1)
+	str = strings.Replace(str, ")", "\\)", -1)
+	return str
 }
@@ -11,6 +11,7 @@ type SubfontDescriptorObj struct {
 	buffer                bytes.Buffer
 	PtrToSubsetFontObj    *SubsetFontObj
 	indexObjPdfDictionary int
+	fontOption            FontOption
 }
 
 func (s *SubfontDescriptorObj) Init(func() *GoPdf) {}
@@ -26,33 +27,55 @@ func (s *SubfontDescriptorObj) Build() error {
 	ttfp := s.PtrToSubsetFontObj.GetTTFParser()
 	s.buffer.WriteString("<<\n")
 	s.buffer.WriteString("/Type /FontDescriptor\n")
-	s.buffer.WriteString(fmt.Sprintf("/Ascent %d\n", DesignUnitsToPdf(ttfp.Ascender(), ttfp.UnitsPerEm())))
-	s.buffer.WriteString(fmt.Sprintf("/CapHeight %d\n", DesignUnitsToPdf(ttfp.CapHeight(), ttfp.UnitsPerEm())))
-	s.buffer.WriteString(fmt.Sprintf("/Descent %d\n", DesignUnitsToPdf(ttfp.Descender(), ttfp.UnitsPerEm())))
-	s.buffer.WriteString(fmt.Sprintf("/Flags %d\n", ttfp.Flag()))
-	s.buffer.WriteString(fmt.Sprintf("/FontBBox [%d %d %d %d]\n",
-		DesignUnitsToPdf(ttfp.XMin(), ttfp.UnitsPerEm()),
-		DesignUnitsToPdf(ttfp.YMin(), ttfp.UnitsPerEm()),
-		DesignUnitsToPdf(ttfp.XMax(), ttfp.UnitsPerEm()),
-		DesignUnitsToPdf(ttfp.YMax(), ttfp.UnitsPerEm()),
-	))
-	s.buffer.WriteString(fmt.Sprintf("/FontFile2 %d 0 R\n", s.indexObjPdfDictionary+1))
-	s.buffer.WriteString(fmt.Sprintf("/FontName /%s\n", CreateEmbeddedFontSubsetName(s.PtrToSubsetFontObj.GetFamily())))
-	s.buffer.WriteString(fmt.Sprintf("/ItalicAngle %d\n", ttfp.ItalicAngle()))
-	s.buffer.WriteString("/StemV 0\n")
-	s.buffer.WriteString(fmt.Sprintf("/XHeight %d\n", DesignUnitsToPdf(ttfp.XHeight(), ttfp.UnitsPerEm())))
+	descs := []FontDescItem{
+		{Key: "Ascent", Val: fmt.Sprintf("%d", DesignUnitsToPdf(ttfp.Ascender(), ttfp.UnitsPerEm()))},
+		{Key: "CapHeight", Val: fmt.Sprintf("%d", DesignUnitsToPdf(ttfp.CapHeight(), ttfp.UnitsPerEm()))},
+		{Key: "Descent", Val: fmt.Sprintf("%d", DesignUnitsToPdf(ttfp.Descender(), ttfp.UnitsPerEm()))},
+		{Key: "Flags", Val: fmt.Sprintf("%d", s.flags())},
+		{Key: "FontBBox", Val: fmt.Sprintf("[%d %d %d %d]",
+			DesignUnitsToPdf(ttfp.XMin(), ttfp.UnitsPerEm()),
+			DesignUnitsToPdf(ttfp.YMin(), ttfp.UnitsPerEm()),
+			DesignUnitsToPdf(ttfp.XMax(), ttfp.UnitsPerEm()),
+			DesignUnitsToPdf(ttfp.YMax(), ttfp.UnitsPerEm()),
+		)},
+		{Key: "FontName", Val: "/" + s.PtrToSubsetFontObj.GetSubsetFontName()},
+		{Key: "ItalicAngle", Val: fmt.Sprintf("%d", ttfp.ItalicAngle())},
+		{Key: "StemV", Val: "0"},
+		{Key: "XHeight", Val: fmt.Sprintf("%d", DesignUnitsToPdf(ttfp.XHeight(), ttfp.UnitsPerEm()))},
+	}
+	if s.indexObjPdfDictionary != -1 {
+		descs = append(descs, FontDescItem{Key: "FontFile2", Val: fmt.Sprintf("%d 0 R", s.indexObjPdfDictionary+1)})
+	}
+	for _, desc := range mergeFontDesc(descs, s.fontOption) {
+		s.buffer.WriteString("/" + desc.Key + " " + desc.Val + "\n")
+	}
 	s.buffer.WriteString(">>\n")
 	return nil
 }
 
+//flags : flags of font , nonsymbolic for simple TrueType font
+func (s *SubfontDescriptorObj) flags() int {
+	if s.PtrToSubsetFontObj.isSimple() {
+		return simpleFontFlags(s.PtrToSubsetFontObj.GetTTFParser().Flag())
+	}
+	return s.PtrToSubsetFontObj.GetTTFParser().Flag()
+}
+
+//SetIndexObjPdfDictionary : set index of font file obj , -1 = font is not embedded
 func (s *SubfontDescriptorObj) SetIndexObjPdfDictionary(index int) {
 	s.indexObjPdfDictionary = index
 }
 
+//SetFontOption : set option that override values of descriptor
+func (s *SubfontDescriptorObj) SetFontOption(option FontOption) {
+	s.fontOption = option
+}
+
 func (s *SubfontDescriptorObj) SetPtrToSubsetFontObj(ptr *SubsetFontObj) {
 	s.PtrToSubsetFontObj = ptr
 }
 
+//DesignUnitsToPdf : convert font design units to glyph space of pdf (1/1000 of font size) , same as scaleToPDF of SubsetFontObj
 func DesignUnitsToPdf(val int64, unitsPerEm uint64) int64 {
 	return core.Round(float64(float64(val) * 1000.00 / float64(unitsPerEm)))
 }
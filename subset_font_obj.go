@@ -3,29 +3,58 @@ package gopdf
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"math"
+	"sort"
 
 	"github.com/signintech/gopdf/fontmaker/core"
 )
 
+//defaultSpaceWidth : width of space (1/1000 of font size) if font has no space glyph
+const defaultSpaceWidth = 250
+
 //PdfType0 Font
 type SubsetFontObj struct {
 	buffer                bytes.Buffer
 	ttfp                  core.TTFParser
+	ttfpath               string
 	Family                string
 	CharacterToGlyphIndex map[rune]uint64
 	CountOfFont           int
 	indexObjCIDFont       int
 	indexObjUnicodeMap    int
+	//gsubLookups : lookups of GSUB by enabled features
+	gsubLookups map[string][]core.GSUBLookup
+	//substitutedGlyphs : glyphs from GSUB (ligatures ...) in subset and runes they represent
+	substitutedGlyphs map[uint64][]rune
+	//notEmbedded : font file is not written (FontOption.Embed false)
+	notEmbedded  bool
+	subsetOption SubsetOption
+	//glyphUnicodeMap : unicode of glyphs that replace unicode from cmap in ToUnicode
+	glyphUnicodeMap map[uint64]rune
+	//aliases : other families that use this font (AddTTFFontByReader)
+	aliases []string
+	//preferSimple : FontOption.PreferSimple
+	preferSimple bool
+	//fontOption : option that font was added with (AddTTFFontWithOption)
+	fontOption             FontOption
+	indexObjFontDescriptor int
 }
 
 func (s *SubsetFontObj) Init(funcGetRoot func() *GoPdf) {
 	s.CharacterToGlyphIndex = make(map[rune]uint64)
+	s.gsubLookups = make(map[string][]core.GSUBLookup)
+	s.substitutedGlyphs = make(map[uint64][]rune)
 }
 
 func (s *SubsetFontObj) Build() error {
 	//me.AddChars("จ")
+	if s.isSimple() {
+		s.buildSimple()
+		return nil
+	}
 	s.buffer.WriteString("<<\n")
-	s.buffer.WriteString(fmt.Sprintf("/BaseFont /%s\n", CreateEmbeddedFontSubsetName(s.Family)))
+	s.buffer.WriteString(fmt.Sprintf("/BaseFont /%s\n", s.GetSubsetFontName()))
 	s.buffer.WriteString(fmt.Sprintf("/DescendantFonts [%d 0 R]\n", s.indexObjCIDFont+1)) //TODO fix
 	s.buffer.WriteString("/Encoding /Identity-H\n")
 	s.buffer.WriteString("/Subtype /Type0\n")
@@ -35,14 +64,55 @@ func (s *SubsetFontObj) Build() error {
 	return nil
 }
 
+//GetSubsetFontName : PostScript name of font with subset tag (same for /BaseFont and /FontName) , without tag if font is not embedded
+func (s *SubsetFontObj) GetSubsetFontName() string {
+	name := s.ttfp.PostScriptName()
+	if name == "" {
+		//font ที่ไม่มี PostScript name (name id 6)
+		name = s.Family
+	}
+	if s.notEmbedded {
+		return name
+	}
+	return CreateEmbeddedFontSubsetName(name, append(s.UsedGlyphs(), 0)...)
+}
+
 func (s *SubsetFontObj) SetIndexObjCIDFont(index int) {
 	s.indexObjCIDFont = index
 }
 
+//SetIndexObjFontDescriptor : set index of font descriptor obj (used by simple TrueType font , see FontOption.PreferSimple)
+func (s *SubsetFontObj) SetIndexObjFontDescriptor(index int) {
+	s.indexObjFontDescriptor = index
+}
+
+//SetPreferSimple : write font as simple TrueType font if every drawn rune is in WinAnsiEncoding
+func (s *SubsetFontObj) SetPreferSimple(preferSimple bool) {
+	s.preferSimple = preferSimple
+}
+
 func (s *SubsetFontObj) SetIndexObjUnicodeMap(index int) {
 	s.indexObjUnicodeMap = index
 }
 
+//SetGlyphUnicodeMap : set unicode of glyphs for ToUnicode (override unicode from cmap)
+func (s *SubsetFontObj) SetGlyphUnicodeMap(m map[uint64]rune) {
+	s.glyphUnicodeMap = make(map[uint64]rune)
+	for glyphIndex, r := range m {
+		s.glyphUnicodeMap[glyphIndex] = r
+	}
+}
+
+//SetSubsetOption : set option of glyphs in subset
+func (s *SubsetFontObj) SetSubsetOption(option SubsetOption) {
+	s.subsetOption = option
+}
+
+//SetEmbed : false = font file is not embedded , font is referenced by PostScript name
+func (s *SubsetFontObj) SetEmbed(embed bool) {
+	s.notEmbedded = !embed
+}
+
 func (s *SubsetFontObj) SetFamily(familyname string) {
 	s.Family = familyname
 }
@@ -51,14 +121,59 @@ func (s *SubsetFontObj) GetFamily() string {
 	return s.Family
 }
 
+//hasFamily : family is family or alias of font
+func (s *SubsetFontObj) hasFamily(family string) bool {
+	if s.Family == family {
+		return true
+	}
+	for _, alias := range s.aliases {
+		if alias == family {
+			return true
+		}
+	}
+	return false
+}
+
+//addAlias : use font by alias in SetFont too
+func (s *SubsetFontObj) addAlias(alias string) {
+	s.aliases = append(s.aliases, alias)
+}
+
 func (s *SubsetFontObj) SetTTFByPath(ttfpath string) error {
 	err := s.ttfp.Parse(ttfpath)
 	if err != nil {
 		return err
 	}
+	err = s.checkGlyf()
+	if err != nil {
+		return err
+	}
+	s.ttfpath = ttfpath
+	return nil
+}
+
+//SetTTFByReader : same as SetTTFByPath , read ttf from r
+func (s *SubsetFontObj) SetTTFByReader(r io.Reader) error {
+	err := s.ttfp.ParseByReader(r)
+	if err != nil {
+		return err
+	}
+	return s.checkGlyf()
+}
+
+//checkGlyf : subset ทำได้เฉพาะ font ที่มี glyf
+func (s *SubsetFontObj) checkGlyf() error {
+	if !s.ttfp.HasTable("glyf") || s.ttfp.LocaTable == nil {
+		return core.ERROR_NO_GLYF_TABLE
+	}
 	return nil
 }
 
+//GetTTFPath : path of ttf file
+func (s *SubsetFontObj) GetTTFPath() string {
+	return s.ttfpath
+}
+
 func (s *SubsetFontObj) AddChars(txt string) {
 	for _, runeValue := range txt {
 		if _, ok := s.CharacterToGlyphIndex[runeValue]; ok {
@@ -69,6 +184,28 @@ func (s *SubsetFontObj) AddChars(txt string) {
 	}
 }
 
+//UsedGlyphs : sorted glyph indexes of chars and substituted glyphs (SetFontFeatures) that have been drawn
+func (s *SubsetFontObj) UsedGlyphs() []uint64 {
+	seen := make(map[uint64]bool)
+	var glyphIndexes []uint64
+	for _, glyphIndex := range s.CharacterToGlyphIndex {
+		if !seen[glyphIndex] {
+			seen[glyphIndex] = true
+			glyphIndexes = append(glyphIndexes, glyphIndex)
+		}
+	}
+	for glyphIndex := range s.substitutedGlyphs {
+		if !seen[glyphIndex] {
+			seen[glyphIndex] = true
+			glyphIndexes = append(glyphIndexes, glyphIndex)
+		}
+	}
+	sort.Slice(glyphIndexes, func(i, j int) bool {
+		return glyphIndexes[i] < glyphIndexes[j]
+	})
+	return glyphIndexes
+}
+
 func (s *SubsetFontObj) CharIndex(r rune) (uint64, error) {
 	if index, ok := s.CharacterToGlyphIndex[r]; ok {
 		return index, nil
@@ -79,11 +216,30 @@ func (s *SubsetFontObj) CharIndex(r rune) (uint64, error) {
 func (s *SubsetFontObj) CharWidth(r rune) (uint64, error) {
 	glyphIndex := s.CharacterToGlyphIndex
 	if index, ok := glyphIndex[r]; ok {
-		return s.GlyphIndexToPdfWidth(index), nil
+		return s.glyphWidthOfRune(r, index), nil
 	}
 	return 0, ErrCharNotFound
 }
 
+//RuneWidth : width of rune in 1/1000 of font size (rune need not be added to subset)
+func (s *SubsetFontObj) RuneWidth(r rune) uint64 {
+	return s.glyphWidthOfRune(r, s.CharCodeToGlyphIndex(r))
+}
+
+//glyphWidthOfRune : width of glyph (hmtx) , space that has no glyph or zero width use 1/4 em
+func (s *SubsetFontObj) glyphWidthOfRune(r rune, glyphIndex uint64) uint64 {
+	if r == 0xA0 {
+		//no-break space มี advance เท่ากับ space (/W ของ glyph ก็ใช้ค่านี้)
+		r = ' '
+		glyphIndex = s.cmapGlyphIndex(r)
+	}
+	width := s.GlyphIndexToPdfWidth(glyphIndex)
+	if isSpaceRune(r) && (glyphIndex == 0 || width == 0) {
+		return defaultSpaceWidth
+	}
+	return width
+}
+
 func (s *SubsetFontObj) GetType() string {
 	return "SubsetFont"
 }
@@ -93,53 +249,47 @@ func (s *SubsetFontObj) GetObjBuff() *bytes.Buffer {
 	return &s.buffer
 }
 
+//CharCodeToGlyphIndex : glyph of rune , no-break space use its own glyph (so ToUnicode map it back to U+00A0)
+//or glyph of space if font does not have it
 func (s *SubsetFontObj) CharCodeToGlyphIndex(r rune) uint64 {
-	seg := uint64(0)
-	value := uint64(r)
-	segCount := s.ttfp.SegCount
-	for seg < segCount {
-		if value <= s.ttfp.EndCount[seg] {
-			break
+	if r == 0xA0 {
+		if nbsp := s.cmapGlyphIndex(r); nbsp != 0 {
+			return nbsp
 		}
-		seg++
-	}
-	//fmt.Printf("\ncccc--->%#v\n", me.ttfp.Chars())
-	if value < s.ttfp.StartCount[seg] {
-		return 0
-	}
-
-	if s.ttfp.IdRangeOffset[seg] == 0 {
-		return (value + s.ttfp.IdDelta[seg]) & 0xFFFF
-	}
-	//fmt.Printf("IdRangeOffset=%d\n", me.ttfp.IdRangeOffset[seg])
-	idx := s.ttfp.IdRangeOffset[seg]/2 + (value - s.ttfp.StartCount[seg]) - (segCount - seg)
-
-	if s.ttfp.GlyphIdArray[int(idx)] == uint64(0) {
-		return 0
+		r = ' '
 	}
+	return s.cmapGlyphIndex(r)
+}
 
-	return (s.ttfp.GlyphIdArray[int(idx)] + s.ttfp.IdDelta[seg]) & 0xFFFF
+//cmapGlyphIndex : glyph of rune from cmap of ParseCmap (first segment that maps rune to glyph other than 0) , 0 if not found
+func (s *SubsetFontObj) cmapGlyphIndex(r rune) uint64 {
+	return s.ttfp.Chars()[int(r)]
 }
 
 func (s *SubsetFontObj) GlyphIndexToPdfWidth(glyphIndex uint64) uint64 {
 
 	numberOfHMetrics := s.ttfp.NumberOfHMetrics()
-	unitsPerEm := s.ttfp.UnitsPerEm()
 	if glyphIndex >= numberOfHMetrics {
 		glyphIndex = numberOfHMetrics - 1
 	}
 
-	width := s.ttfp.Widths()[glyphIndex]
-	if unitsPerEm == 1000 {
-		return width
+	return uint64(math.Floor(s.scaleToPDF(int64(s.ttfp.Widths()[glyphIndex])) + 0.5))
+}
+
+//scaleToPDF : convert font design units (unitsPerEm of font) to glyph space of pdf (1/1000 of font size)
+func (s *SubsetFontObj) scaleToPDF(v int64) float64 {
+	unitsPerEm := s.ttfp.UnitsPerEm()
+	if unitsPerEm == 0 || unitsPerEm == 1000 {
+		return float64(v)
 	}
-	return width * 1000 / unitsPerEm
+	return float64(v) * 1000.0 / float64(unitsPerEm)
 }
 
 func (s *SubsetFontObj) GetTTFParser() *core.TTFParser {
 	return &s.ttfp
 }
 
+//GetUt : underline thickness in 1/1000 of font size
 func (s *SubsetFontObj) GetUt() int64 {
-	return s.ttfp.UnderlineThickness()
+	return int64(math.Floor(s.scaleToPDF(s.ttfp.UnderlineThickness()) + 0.5))
 }
@@ -0,0 +1,302 @@
+package gopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//ErrUnsupportedSVGCommand : command of svg path is not M , L , H , V , C , Q , A or Z (upper or lower case)
+var ErrUnsupportedSVGCommand = errors.New("unsupported svg path command")
+
+//ErrInvalidSVGPath : svg path is empty , does not start with M or has missing or wrong number
+var ErrInvalidSVGPath = errors.New("invalid svg path")
+
+//DrawSVGPath : draw path of svg path data d (sample "M10 10 L20 20 Z") with its origin at x,y and its size times scale ,
+//commands M , L , H , V , C , Q , A , Z and their relative (lower case) forms are supported ,
+//style is "D" (default "" stroke) , "F" (fill) or "FD" , "DF" (fill and stroke)
+func (gp *GoPdf) DrawSVGPath(d string, x float64, y float64, scale float64, style string) error {
+	op, err := paintOperator(style)
+	if err != nil {
+		return err
+	}
+	pageH := gp.config.PageSize.H
+	p := svgPathParser{data: d}
+	p.point = func(px float64, py float64) (float64, float64) {
+		return x + px*scale, pageH - (y + py*scale)
+	}
+	if err := p.parse(); err != nil {
+		return err
+	}
+	if p.path.Len() == 0 {
+		//ไม่มี path ให้วาด
+		return ErrInvalidSVGPath
+	}
+	p.path.WriteString(op)
+	gp.getContent().AppendStreamRaw(p.path.String())
+	return nil
+}
+
+//svgPathParser : convert svg path data to pdf path operators
+type svgPathParser struct {
+	data string
+	pos  int
+	path bytes.Buffer
+	//point : pdf position of point of svg path
+	point func(px float64, py float64) (float64, float64)
+	//current point and start of subpath (svg space)
+	cx, cy         float64
+	startX, startY float64
+}
+
+func (p *svgPathParser) parse() error {
+	command := byte(0)
+	for {
+		p.skipSeparators()
+		if p.pos >= len(p.data) {
+			return nil
+		}
+		ch := p.data[p.pos]
+		if isSVGCommand(ch) {
+			command = ch
+			p.pos++
+		} else if command == 0 {
+			return ErrInvalidSVGPath
+		} else if command == 'Z' || command == 'z' {
+			return ErrInvalidSVGPath
+		}
+		if strings.IndexByte("MmLlHhVvCcQqAaZz", command) == -1 {
+			return fmt.Errorf("%w: %c", ErrUnsupportedSVGCommand, command)
+		}
+		if command != 'M' && command != 'm' && p.path.Len() == 0 {
+			return ErrInvalidSVGPath
+		}
+		if err := p.segment(command); err != nil {
+			return err
+		}
+		//ตัวเลขที่ตามหลัง moveto คือ lineto
+		if command == 'M' {
+			command = 'L'
+		} else if command == 'm' {
+			command = 'l'
+		}
+	}
+}
+
+//segment : read numbers of one segment of command and write its operators
+func (p *svgPathParser) segment(command byte) error {
+	relX, relY := 0.0, 0.0
+	if command >= 'a' && command <= 'z' {
+		relX, relY = p.cx, p.cy
+	}
+	switch command {
+	case 'M', 'm':
+		n, err := p.numbers(2)
+		if err != nil {
+			return err
+		}
+		p.cx, p.cy = relX+n[0], relY+n[1]
+		p.startX, p.startY = p.cx, p.cy
+		p.write("m", p.cx, p.cy)
+	case 'L', 'l':
+		n, err := p.numbers(2)
+		if err != nil {
+			return err
+		}
+		p.lineTo(relX+n[0], relY+n[1])
+	case 'H', 'h':
+		n, err := p.numbers(1)
+		if err != nil {
+			return err
+		}
+		p.lineTo(relX+n[0], p.cy)
+	case 'V', 'v':
+		n, err := p.numbers(1)
+		if err != nil {
+			return err
+		}
+		p.lineTo(p.cx, relY+n[0])
+	case 'C', 'c':
+		n, err := p.numbers(6)
+		if err != nil {
+			return err
+		}
+		p.curveTo(relX+n[0], relY+n[1], relX+n[2], relY+n[3], relX+n[4], relY+n[5])
+	case 'Q', 'q':
+		n, err := p.numbers(4)
+		if err != nil {
+			return err
+		}
+		//quadratic เป็น cubic ที่ control point อยู่ 2/3 ทางไปหา control point เดิม
+		qx, qy, ex, ey := relX+n[0], relY+n[1], relX+n[2], relY+n[3]
+		p.curveTo(p.cx+2.0/3*(qx-p.cx), p.cy+2.0/3*(qy-p.cy), ex+2.0/3*(qx-ex), ey+2.0/3*(qy-ey), ex, ey)
+	case 'A', 'a':
+		n, err := p.numbers(3)
+		if err != nil {
+			return err
+		}
+		largeArc, err := p.flag()
+		if err != nil {
+			return err
+		}
+		sweep, err := p.flag()
+		if err != nil {
+			return err
+		}
+		end, err := p.numbers(2)
+		if err != nil {
+			return err
+		}
+		p.arcTo(n[0], n[1], n[2], largeArc, sweep, relX+end[0], relY+end[1])
+	case 'Z', 'z':
+		p.path.WriteString("h\n")
+		p.cx, p.cy = p.startX, p.startY
+	}
+	return nil
+}
+
+func (p *svgPathParser) lineTo(x float64, y float64) {
+	p.cx, p.cy = x, y
+	p.write("l", x, y)
+}
+
+func (p *svgPathParser) curveTo(x1 float64, y1 float64, x2 float64, y2 float64, x float64, y float64) {
+	p.cx, p.cy = x, y
+	p.write("c", x1, y1, x2, y2, x, y)
+}
+
+//arcTo : elliptical arc from current point to x,y as cubic beziers (each not more than 90 degree) ,
+//radii are scaled up if they are too small (svg implementation notes)
+func (p *svgPathParser) arcTo(rx float64, ry float64, rotation float64, largeArc bool, sweep bool, x float64, y float64) {
+	x0, y0 := p.cx, p.cy
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (x0 == x && y0 == y) {
+		p.lineTo(x, y)
+		return
+	}
+	phi := rotation * math.Pi / 180
+	cosPhi, sinPhi := math.Cos(phi), math.Sin(phi)
+	dx, dy := (x0-x)/2, (y0-y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(num, 0) / den)
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	centerX := cosPhi*cx1 - sinPhi*cy1 + (x0+x)/2
+	centerY := sinPhi*cx1 + cosPhi*cy1 + (y0+y)/2
+
+	angle := func(ux float64, uy float64, vx float64, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta1 := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	segments := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(segments)
+	k := 4.0 / 3 * math.Tan(step/4)
+	//จุดบนวงรีที่มุม t และ tangent ที่จุดนั้น
+	pointAt := func(t float64) (float64, float64, float64, float64) {
+		cosT, sinT := math.Cos(t), math.Sin(t)
+		px := centerX + rx*cosT*cosPhi - ry*sinT*sinPhi
+		py := centerY + rx*cosT*sinPhi + ry*sinT*cosPhi
+		tx := -rx*sinT*cosPhi - ry*cosT*sinPhi
+		ty := -rx*sinT*sinPhi + ry*cosT*cosPhi
+		return px, py, tx, ty
+	}
+	t := theta1
+	for i := 0; i < segments; i++ {
+		sx, sy, stx, sty := pointAt(t)
+		ex, ey, etx, ety := pointAt(t + step)
+		if i == segments-1 {
+			ex, ey = x, y
+		}
+		p.curveTo(sx+k*stx, sy+k*sty, ex-k*etx, ey-k*ety, ex, ey)
+		t += step
+	}
+}
+
+//write : operator with points (svg space) converted to pdf space
+func (p *svgPathParser) write(operator string, coords ...float64) {
+	for i := 0; i+1 < len(coords); i += 2 {
+		px, py := p.point(coords[i], coords[i+1])
+		p.path.WriteString(fmt.Sprintf("%0.2f %0.2f ", px, py))
+	}
+	p.path.WriteString(operator + "\n")
+}
+
+//numbers : read count numbers
+func (p *svgPathParser) numbers(count int) ([]float64, error) {
+	n := make([]float64, count)
+	for i := range n {
+		p.skipSeparators()
+		start := p.pos
+		if p.pos < len(p.data) && (p.data[p.pos] == '-' || p.data[p.pos] == '+') {
+			p.pos++
+		}
+		dot := false
+		for p.pos < len(p.data) {
+			ch := p.data[p.pos]
+			if ch == '.' && !dot {
+				dot = true
+			} else if (ch == 'e' || ch == 'E') && p.pos > start {
+				p.pos++
+				if p.pos < len(p.data) && (p.data[p.pos] == '-' || p.data[p.pos] == '+') {
+					p.pos++
+				}
+				continue
+			} else if ch < '0' || ch > '9' {
+				break
+			}
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.data[start:p.pos], 64)
+		if err != nil {
+			return nil, ErrInvalidSVGPath
+		}
+		n[i] = v
+	}
+	return n, nil
+}
+
+//flag : read flag of arc (0 or 1 , may not be separated from next number)
+func (p *svgPathParser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.pos >= len(p.data) || (p.data[p.pos] != '0' && p.data[p.pos] != '1') {
+		return false, ErrInvalidSVGPath
+	}
+	p.pos++
+	return p.data[p.pos-1] == '1', nil
+}
+
+func (p *svgPathParser) skipSeparators() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+//isSVGCommand : ch is letter of path command (supported or not) , e of number is not command
+func isSVGCommand(ch byte) bool {
+	return (ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z') && ch != 'e' && ch != 'E'
+}
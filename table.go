@@ -0,0 +1,373 @@
+package gopdf
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//ErrInvalidAmount : debit or credit cell of running balance is not a number
+var ErrInvalidAmount = errors.New("invalid amount")
+
+//TableColumn : column of Table
+type TableColumn struct {
+	Width float64
+	//Align : "left" (default "") , "center" , "right" or "decimal" (decimal separators of the column line up)
+	Align string
+	//DecimalSeparator : separator of "decimal" align , '.' (default 0) or ','
+	DecimalSeparator rune
+}
+
+//TableCell : cell of row added by AddStyledRow
+type TableCell struct {
+	Text string
+	//Fill : background of cell (nil = no background) , drawn behind border and text
+	Fill *Color
+	//TextColor : color of text (nil = current fill color)
+	TextColor *Color
+	//Align : align of text ("" = Align of column)
+	Align string
+}
+
+//RunningBalance : column whose cell is total of previous row + debit - credit (ledger table) , see SetRunningBalance
+type RunningBalance struct {
+	//Column , Debit , Credit : index of balance , debit and credit column
+	Column int
+	Debit  int
+	Credit int
+	//Opening : balance before first row
+	Opening float64
+	//Decimals : digits after decimal separator of balance
+	Decimals int
+	//NegativeParentheses : negative balance is written as (12.50) not -12.50
+	NegativeParentheses bool
+}
+
+//Table : rows of text drawn in columns with borders , create by NewTable
+type Table struct {
+	gp      *GoPdf
+	columns []TableColumn
+	rows    [][]string
+	//styles : cells of AddStyledRow by index of row (nil = row of AddRow)
+	styles [][]TableCell
+	//RowHeight : height of row (0 = line height of current font)
+	RowHeight float64
+	//Padding : space between border and text at left and right of cell
+	Padding float64
+	//BottomMargin : rows are not drawn lower than page height - BottomMargin , table continue on new page
+	BottomMargin float64
+
+	header     []string
+	footer     []string
+	footerFunc func(rows [][]string) []string
+	balance    *RunningBalance
+}
+
+//NewTable : table that is drawn with current font at current position by Draw
+func (gp *GoPdf) NewTable(columns []TableColumn) *Table {
+	return &Table{
+		gp:           gp,
+		columns:      columns,
+		Padding:      2,
+		BottomMargin: gp.topMargin,
+	}
+}
+
+//AddRow : add row of cells (one text for each column , missing cells are empty)
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+	t.styles = append(t.styles, nil)
+}
+
+//AddStyledRow : add row of cells with background , text color or align of their own
+func (t *Table) AddStyledRow(cells ...TableCell) {
+	texts := make([]string, len(cells))
+	for i, cell := range cells {
+		texts[i] = cell.Text
+	}
+	t.rows = append(t.rows, texts)
+	t.styles = append(t.styles, cells)
+}
+
+//SetHeaderRow : row that is drawn above rows on every page of table
+func (t *Table) SetHeaderRow(cells ...string) {
+	t.header = cells
+}
+
+//SetFooterRow : row that is drawn below rows on every page of table
+func (t *Table) SetFooterRow(cells ...string) {
+	t.footer = cells
+}
+
+//SetFooterFunc : footer row of each page is made by footer from rows drawn until end of that page (sample running totals) ,
+//replace SetFooterRow
+func (t *Table) SetFooterFunc(footer func(rows [][]string) []string) {
+	t.footerFunc = footer
+}
+
+//SetRunningBalance : cells of balance column are computed when table is drawn (text of balance cell of rows is replaced) ,
+//balance column is "decimal" aligned , empty debit or credit cell is 0
+func (t *Table) SetRunningBalance(balance RunningBalance) {
+	t.balance = &balance
+	columns := make([]TableColumn, len(t.columns))
+	copy(columns, t.columns)
+	if balance.Column >= 0 && balance.Column < len(columns) {
+		columns[balance.Column].Align = "decimal"
+	}
+	t.columns = columns
+}
+
+//applyRunningBalance : write balance of each row into balance column
+func (t *Table) applyRunningBalance() error {
+	b := t.balance
+	if b == nil || b.Column < 0 || b.Column >= len(t.columns) {
+		return nil
+	}
+	separator := t.columns[b.Column].DecimalSeparator
+	total := b.Opening
+	for i, row := range t.rows {
+		debit, err := parseAmount(cellOf(row, b.Debit), separator)
+		if err != nil {
+			return err
+		}
+		credit, err := parseAmount(cellOf(row, b.Credit), separator)
+		if err != nil {
+			return err
+		}
+		total += debit - credit
+		for len(row) <= b.Column {
+			row = append(row, "")
+		}
+		row[b.Column] = formatAmount(total, b.Decimals, separator, b.NegativeParentheses)
+		t.rows[i] = row
+	}
+	return nil
+}
+
+//cellOf : text of cell i of row ("" if row has no cell i)
+func cellOf(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+//parseAmount : number of text with decimal separator (grouping of other separator and parentheses of negative are allowed)
+func parseAmount(text string, separator rune) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	negative := false
+	if strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		negative = true
+		text = text[1 : len(text)-1]
+	}
+	if separator == ',' {
+		text = strings.Replace(strings.Replace(text, ".", "", -1), ",", ".", 1)
+	} else {
+		text = strings.Replace(text, ",", "", -1)
+	}
+	amount, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+//formatAmount : amount with decimals digits after separator
+func formatAmount(amount float64, decimals int, separator rune, parentheses bool) string {
+	text := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	if separator == ',' {
+		text = strings.Replace(text, ".", ",", 1)
+	}
+	if amount >= 0 || strings.Trim(text, "0.,") == "" {
+		//-0.00 เขียนเป็น 0.00
+		return text
+	}
+	if parentheses {
+		return "(" + text + ")"
+	}
+	return "-" + text
+}
+
+//Draw : draw rows at current position , rows that do not fit on page are drawn on new pages (with header and footer row) ,
+//current y is moved below the table and x is not changed
+func (t *Table) Draw() error {
+	gp := t.gp
+	rowH := t.RowHeight
+	if rowH <= 0 {
+		rowH = gp.autoLineHeight()
+	}
+	if err := t.applyRunningBalance(); err != nil {
+		return err
+	}
+	decimalWidths, err := t.decimalFractionWidths()
+	if err != nil {
+		return err
+	}
+	hasFooter := t.footer != nil || t.footerFunc != nil
+	footerH := 0.0
+	if hasFooter {
+		footerH = rowH
+	}
+	bottom := gp.config.PageSize.H - t.BottomMargin
+
+	startX := gp.Curr.X
+	if t.header != nil {
+		if err := t.drawRow(t.header, nil, startX, rowH, nil); err != nil {
+			return err
+		}
+	}
+	rowsOfPage := 0
+	for i, row := range t.rows {
+		if rowsOfPage > 0 && gp.Curr.Y+rowH+footerH > bottom {
+			if hasFooter {
+				if err := t.drawRow(t.footerOf(i), nil, startX, rowH, nil); err != nil {
+					return err
+				}
+			}
+			gp.AddPage()
+			gp.Curr.X = startX
+			rowsOfPage = 0
+			if t.header != nil {
+				if err := t.drawRow(t.header, nil, startX, rowH, nil); err != nil {
+					return err
+				}
+			}
+		}
+		if err := t.drawRow(row, t.styles[i], startX, rowH, decimalWidths); err != nil {
+			return err
+		}
+		rowsOfPage++
+	}
+	if hasFooter {
+		if err := t.drawRow(t.footerOf(len(t.rows)), nil, startX, rowH, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//footerOf : footer row of page that ends before row n
+func (t *Table) footerOf(n int) []string {
+	if t.footerFunc != nil {
+		return t.footerFunc(t.rows[:n])
+	}
+	return t.footer
+}
+
+//drawRow : draw cells at x , current y and move current y below row , styles is nil for row without styled cells ,
+//decimalWidths is nil for header and footer row ("decimal" columns are right aligned)
+func (t *Table) drawRow(row []string, styles []TableCell, startX float64, rowH float64, decimalWidths []float64) error {
+	gp := t.gp
+	x := startX
+	y := gp.Curr.Y
+	for i, column := range t.columns {
+		text := ""
+		if i < len(row) {
+			text = row[i]
+		}
+		var style TableCell
+		if i < len(styles) {
+			style = styles[i]
+		}
+		if style.Fill != nil {
+			gp.getContent().AppendStreamFillRectangle(x, y, column.Width, rowH, *style.Fill)
+		}
+		gp.getContent().AppendStreamBorder(x, y, column.Width, rowH, AllBorders, nil, 0)
+		if style.Align != "" {
+			column.Align = style.Align
+		}
+		if text != "" {
+			fractionWidth := 0.0
+			if decimalWidths != nil {
+				fractionWidth = decimalWidths[i]
+			} else if column.Align == "decimal" {
+				column.Align = "right"
+			}
+			textX, textW, err := t.textPosition(column, text, x, fractionWidth)
+			if err != nil {
+				return err
+			}
+			gp.Curr.X = textX
+			gp.Curr.Y = y
+			if style.TextColor != nil {
+				gp.getContent().AppendStreamSaveGraphicsState()
+				gp.getContent().AppendStreamSetColor(*style.TextColor, false)
+			}
+			gp.Cell(&Rect{W: textW, H: rowH}, text)
+			if style.TextColor != nil {
+				gp.getContent().AppendStreamRestoreGraphicsState()
+			}
+		}
+		x += column.Width
+	}
+	gp.Curr.X = startX
+	gp.Curr.Y = y + rowH
+	return nil
+}
+
+//textPosition : x and width of text in cell of column at x , fractionWidth is width of widest fraction part of "decimal" column
+func (t *Table) textPosition(column TableColumn, text string, x float64, fractionWidth float64) (float64, float64, error) {
+	textW, err := t.gp.MeasureTextWidth(text)
+	if err != nil {
+		return 0, 0, err
+	}
+	right := x + column.Width - t.Padding
+	switch column.Align {
+	case "center":
+		return x + (column.Width-textW)/2, textW, nil
+	case "right":
+		return right - textW, textW, nil
+	case "decimal":
+		integer, _ := splitDecimal(text, column.DecimalSeparator)
+		integerW, err := t.gp.MeasureTextWidth(integer)
+		if err != nil {
+			return 0, 0, err
+		}
+		//separator ของทุกแถวอยู่ที่ x เดียวกัน
+		return right - fractionWidth - integerW, textW, nil
+	}
+	return x + t.Padding, textW, nil
+}
+
+//decimalFractionWidths : width of widest fraction part (with separator) of each "decimal" column
+func (t *Table) decimalFractionWidths() ([]float64, error) {
+	widths := make([]float64, len(t.columns))
+	for i, column := range t.columns {
+		if column.Align != "decimal" {
+			continue
+		}
+		for _, row := range t.rows {
+			if i >= len(row) {
+				continue
+			}
+			_, fraction := splitDecimal(row[i], column.DecimalSeparator)
+			w, err := t.gp.MeasureTextWidth(fraction)
+			if err != nil {
+				return nil, err
+			}
+			if w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths, nil
+}
+
+//splitDecimal : integer part and fraction part (start with separator , "" if text has no separator) of text
+func splitDecimal(text string, separator rune) (string, string) {
+	if separator == 0 {
+		separator = '.'
+	}
+	i := strings.LastIndex(text, string(separator))
+	if i == -1 {
+		return text, ""
+	}
+	return text[:i], text[i:]
+}
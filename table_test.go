@@ -0,0 +1,171 @@
+package gopdf
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//tableTextXs : x of each text drawn in stream (in drawing order)
+func tableTextXs(t *testing.T, stream string) []float64 {
+	var xs []float64
+	for _, match := range regexp.MustCompile(`(\d+\.\d+) \d+\.\d+ TD\n`).FindAllStringSubmatch(stream, -1) {
+		x, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+func TestTableDecimalAlign(t *testing.T) {
+	for _, separator := range []rune{'.', ','} {
+		pdf := newTestPdf(t)
+		sep := string(separator)
+		values := []string{"1" + sep + "5", "12345" + sep + "25", "10", "0" + sep + "125", "-3" + sep + "7"}
+		table := pdf.NewTable([]TableColumn{{Width: 150, Align: "decimal", DecimalSeparator: separator}})
+		for _, value := range values {
+			table.AddRow(value)
+		}
+		if err := table.Draw(); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if pdf.GetX() != 10 || math.Abs(pdf.GetY()-(10+5*pdf.autoLineHeight())) > 0.001 {
+			t.Errorf("current position must be below table")
+		}
+
+		xs := tableTextXs(t, pdf.getContent().stream.String())
+		if len(xs) != len(values) {
+			t.Fatalf("expect %d texts but got %d", len(values), len(xs))
+		}
+		var separatorX float64
+		for i, value := range values {
+			integer, _ := splitDecimal(value, separator)
+			w, _ := pdf.MeasureTextWidth(integer)
+			x := xs[i] + w
+			if i == 0 {
+				separatorX = x
+			} else if math.Abs(x-separatorX) > 0.01 {
+				t.Errorf("separator of %q at %f but expect %f", value, x, separatorX)
+			}
+		}
+		//widest fraction end at right padding
+		fraction, _ := pdf.MeasureTextWidth(sep + "125")
+		if math.Abs(separatorX+fraction-(10+150-2)) > 0.01 {
+			t.Errorf("widest fraction must end at right padding of cell")
+		}
+	}
+}
+
+func TestTableHeaderFooterOnEveryPage(t *testing.T) {
+	pdf := newTestPdf(t)
+	table := pdf.NewTable([]TableColumn{{Width: 100}, {Width: 80, Align: "decimal"}})
+	table.SetHeaderRow("Item", "Amount")
+	var footerRows []int
+	table.SetFooterFunc(func(rows [][]string) []string {
+		footerRows = append(footerRows, len(rows))
+		sum := 0
+		for _, row := range rows {
+			n, _ := strconv.Atoi(row[1])
+			sum += n
+		}
+		return []string{"Total", strconv.Itoa(sum)}
+	})
+	for i := 1; i <= 60; i++ {
+		table.AddRow("item "+strconv.Itoa(i), strconv.Itoa(i))
+	}
+	if err := table.Draw(); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	var streams []string
+	for _, obj := range pdf.pdfObjs {
+		if content, ok := obj.(*ContentObj); ok {
+			streams = append(streams, content.stream.String())
+		}
+	}
+	if len(streams) != 2 {
+		t.Fatalf("expect table on 2 pages but got %d", len(streams))
+	}
+	if len(footerRows) != 2 || footerRows[0] == 0 || footerRows[0] >= 60 || footerRows[1] != 60 {
+		t.Errorf("expect footer of each page with running rows but got %v", footerRows)
+	}
+	header, _ := pdf.getContent().subsetFontTextOperator("Item")
+	texts := 0
+	for _, stream := range streams {
+		texts += len(tableTextXs(t, stream))
+		if !strings.Contains(stream, header) || strings.Index(stream, header) > strings.Index(stream, " Tj\n") {
+			t.Errorf("header must be first row of page")
+		}
+	}
+	//60 rows , header and footer of 2 pages
+	if texts != 2*(60+2+2) {
+		t.Errorf("expect %d texts but got %d", 2*(60+2+2), texts)
+	}
+}
+
+func TestTableCellFill(t *testing.T) {
+	pdf := newTestPdf(t)
+	red := RGB(255, 0, 0)
+	green := RGB(0, 255, 0)
+	white := RGB(255, 255, 255)
+	table := pdf.NewTable([]TableColumn{{Width: 100}, {Width: 80}})
+	table.AddStyledRow(TableCell{Text: "down", Fill: &red, TextColor: &white}, TableCell{Text: "up", Fill: &green, Align: "right"})
+	if err := table.Draw(); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	h := pdf.autoLineHeight()
+	stream := pdf.getContent().stream.String()
+	for _, fill := range []string{
+		fmt.Sprintf("q\n1.000 0.000 0.000 rg\n10.00 %0.2f 100.00 %0.2f re f\nQ\n", 841.89-(10+h), h),
+		fmt.Sprintf("q\n0.000 1.000 0.000 rg\n110.00 %0.2f 80.00 %0.2f re f\nQ\n", 841.89-(10+h), h),
+	} {
+		i := strings.Index(stream, fill)
+		if i == -1 {
+			t.Fatalf("fill %q not found", fill)
+		}
+		//fill อยู่หลัง border และ text
+		if border := strings.Index(stream[i:], "\nS\n"); border == -1 || border > strings.Index(stream[i:], "BT") {
+			t.Errorf("fill must be drawn before border and text")
+		}
+	}
+	if !strings.Contains(stream, "q\n1.000 1.000 1.000 rg\nBT") {
+		t.Errorf("text color of cell not found")
+	}
+}
+
+func TestTableRunningBalance(t *testing.T) {
+	pdf := newTestPdf(t)
+	table := pdf.NewTable([]TableColumn{{Width: 100}, {Width: 80}, {Width: 80}, {Width: 80}})
+	table.AddRow("Opening sale", "1,200.50", "")
+	table.AddRow("Rent", "", "1500")
+	table.AddRow("Refund", "49.5", "")
+	table.SetRunningBalance(RunningBalance{Column: 3, Debit: 1, Credit: 2, Opening: 100, Decimals: 2, NegativeParentheses: true})
+	var carried []string
+	table.SetFooterFunc(func(rows [][]string) []string {
+		carried = append(carried, rows[len(rows)-1][3])
+		return []string{"Carried forward", "", "", rows[len(rows)-1][3]}
+	})
+	if err := table.Draw(); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	var balances []string
+	for _, row := range table.rows {
+		balances = append(balances, row[3])
+	}
+	if expected := []string{"1300.50", "(199.50)", "(150.00)"}; strings.Join(balances, " ") != strings.Join(expected, " ") {
+		t.Errorf("expect balances %q but got %q", expected, balances)
+	}
+	if len(carried) != 1 || carried[0] != "(150.00)" {
+		t.Errorf("footer must get last balance but got %q", carried)
+	}
+
+	table.AddRow("Bad", "x", "")
+	if err := table.Draw(); err != ErrInvalidAmount {
+		t.Errorf("expect ErrInvalidAmount but got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package gopdf
+
+import "errors"
+
+//ErrTextOnCircleRadius : radius too small for the font size
+var ErrTextOnCircleRadius = errors.New("radius too small for text on circle")
+
+//TextOnCircleOption : option of TextOnCircle
+type TextOnCircleOption struct {
+	//CounterClockwise : advance glyphs counter-clockwise (glyph tops point to the center)
+	CounterClockwise bool
+	//Inside : glyphs are drawn inside the circle instead of outside
+	Inside bool
+}
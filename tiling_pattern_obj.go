@@ -0,0 +1,49 @@
+package gopdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//TilingPatternObj : tiling pattern (PatternType 1) that paints image as its tile
+type TilingPatternObj struct {
+	buffer bytes.Buffer
+	//index ของ image obj ที่ใช้เป็น tile
+	indexOfImageObj int
+	tileW           float64
+	tileH           float64
+	//จุดเริ่มของ tile ใน pdf space
+	originX float64
+	originY float64
+}
+
+func (t *TilingPatternObj) Init(funcGetRoot func() *GoPdf) {
+}
+
+func (t *TilingPatternObj) Build() error {
+	stream := fmt.Sprintf("q %0.2f 0 0 %0.2f 0 0 cm /I1 Do Q\n", t.tileW, t.tileH)
+	t.buffer.WriteString("<<\n")
+	t.buffer.WriteString("/Type /Pattern\n")
+	t.buffer.WriteString("/PatternType 1\n")
+	t.buffer.WriteString("/PaintType 1\n")
+	t.buffer.WriteString("/TilingType 1\n")
+	t.buffer.WriteString(fmt.Sprintf("/BBox [0 0 %0.2f %0.2f]\n", t.tileW, t.tileH))
+	t.buffer.WriteString(fmt.Sprintf("/XStep %0.2f\n", t.tileW))
+	t.buffer.WriteString(fmt.Sprintf("/YStep %0.2f\n", t.tileH))
+	t.buffer.WriteString(fmt.Sprintf("/Resources << /XObject << /I1 %d 0 R >> >>\n", t.indexOfImageObj+1))
+	t.buffer.WriteString(fmt.Sprintf("/Matrix [1 0 0 1 %0.2f %0.2f]\n", t.originX, t.originY))
+	t.buffer.WriteString(fmt.Sprintf("/Length %d\n", len(stream)))
+	t.buffer.WriteString(">>\n")
+	t.buffer.WriteString("stream\n")
+	t.buffer.WriteString(stream)
+	t.buffer.WriteString("endstream\n")
+	return nil
+}
+
+func (t *TilingPatternObj) GetType() string {
+	return "Pattern"
+}
+
+func (t *TilingPatternObj) GetObjBuff() *bytes.Buffer {
+	return &(t.buffer)
+}
@@ -0,0 +1,197 @@
+package gopdf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+//ErrInvalidPFB : file is not type1 font in PFB (printer font binary) format
+var ErrInvalidPFB = errors.New("invalid PFB font")
+
+//ErrInvalidAFM : AFM (font metrics) has no FontName or char metrics
+var ErrInvalidAFM = errors.New("invalid AFM font metrics")
+
+//PFB : parts of type1 font read from PFB file
+type PFB struct {
+	//Clear : clear text part (/Length1)
+	Clear []byte
+	//Encrypted : binary eexec encrypted part (/Length2)
+	Encrypted []byte
+	//Trailer : zeros and cleartomark (/Length3)
+	Trailer []byte
+}
+
+//ParsePFB : split segments of PFB into clear text , encrypted and trailer part
+func ParsePFB(b []byte) (*PFB, error) {
+	var pfb PFB
+	for len(b) > 0 {
+		//segment : 0x80 , type (1 ascii , 2 binary , 3 eof) , length (4 bytes little-endian)
+		if b[0] != 0x80 || len(b) < 2 {
+			return nil, ErrInvalidPFB
+		}
+		segmentType := b[1]
+		if segmentType == 3 {
+			break
+		}
+		if len(b) < 6 {
+			return nil, ErrInvalidPFB
+		}
+		length := int(binary.LittleEndian.Uint32(b[2:6]))
+		if length > len(b)-6 {
+			return nil, ErrInvalidPFB
+		}
+		data := b[6 : 6+length]
+		b = b[6+length:]
+		if segmentType == 2 {
+			pfb.Encrypted = append(pfb.Encrypted, data...)
+		} else if segmentType == 1 && len(pfb.Encrypted) == 0 {
+			pfb.Clear = append(pfb.Clear, data...)
+		} else if segmentType == 1 {
+			pfb.Trailer = append(pfb.Trailer, data...)
+		} else {
+			return nil, ErrInvalidPFB
+		}
+	}
+	if len(pfb.Clear) == 0 || len(pfb.Encrypted) == 0 {
+		return nil, ErrInvalidPFB
+	}
+	return &pfb, nil
+}
+
+//Type1Font : IFont of type1 font with metrics from AFM , widths are by char code of AFM (built-in encoding of font)
+type Type1Font struct {
+	family       string
+	name         string
+	desc         []FontDescItem
+	cw           FontCw
+	up           int
+	ut           int
+	originalsize int
+}
+
+//ParseAFM : read font name , descriptor values and widths of AFM
+func ParseAFM(b []byte) (*Type1Font, error) {
+	f := &Type1Font{cw: make(FontCw)}
+	metrics := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "C ") {
+			f.parseCharMetric(line)
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) == 2 {
+			metrics[fields[0]] = strings.TrimSpace(fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	f.name = metrics["FontName"]
+	if f.name == "" || len(f.cw) == 0 {
+		return nil, ErrInvalidAFM
+	}
+	f.up, _ = strconv.Atoi(metrics["UnderlinePosition"])
+	f.ut, _ = strconv.Atoi(metrics["UnderlineThickness"])
+
+	flags := 1 << 5 //nonsymbolic
+	if metrics["IsFixedPitch"] == "true" {
+		flags |= 1 << 0
+	}
+	italicAngle := metrics["ItalicAngle"]
+	if angle, _ := strconv.ParseFloat(italicAngle, 64); angle != 0 {
+		flags |= 1 << 6 //italic
+	} else {
+		italicAngle = "0"
+	}
+	stemV := metrics["StdVW"]
+	if stemV == "" {
+		stemV = "70"
+	}
+	valueOf := func(key string) string {
+		if v, ok := metrics[key]; ok {
+			return v
+		}
+		return "0"
+	}
+	f.desc = []FontDescItem{
+		{Key: "Ascent", Val: valueOf("Ascender")},
+		{Key: "Descent", Val: valueOf("Descender")},
+		{Key: "CapHeight", Val: valueOf("CapHeight")},
+		{Key: "Flags", Val: strconv.Itoa(flags)},
+		{Key: "FontBBox", Val: "[" + valueOf("FontBBox") + "]"},
+		{Key: "ItalicAngle", Val: italicAngle},
+		{Key: "StemV", Val: stemV},
+		{Key: "MissingWidth", Val: strconv.Itoa(f.cw[' '])},
+	}
+	return f, nil
+}
+
+//parseCharMetric : read code and width of line "C 32 ; WX 250 ; N space ; B 0 0 0 0 ;"
+func (f *Type1Font) parseCharMetric(line string) {
+	code := -1
+	width := 0
+	for _, item := range strings.Split(line, ";") {
+		fields := strings.Fields(item)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "C":
+			code, _ = strconv.Atoi(fields[1])
+		case "WX":
+			width, _ = strconv.Atoi(fields[1])
+		}
+	}
+	if code >= 0 && code <= 255 {
+		f.cw[byte(code)] = width
+	}
+}
+
+func (f *Type1Font) Init()                   {}
+func (f *Type1Font) GetType() string         { return "Type1" }
+func (f *Type1Font) GetName() string         { return f.name }
+func (f *Type1Font) GetDesc() []FontDescItem { return f.desc }
+func (f *Type1Font) GetUp() int              { return f.up }
+func (f *Type1Font) GetUt() int              { return f.ut }
+func (f *Type1Font) GetCw() FontCw           { return f.cw }
+func (f *Type1Font) GetEnc() string          { return "" }
+func (f *Type1Font) GetDiff() string         { return "" }
+func (f *Type1Font) GetOriginalsize() int    { return f.originalsize }
+func (f *Type1Font) SetFamily(family string) { f.family = family }
+func (f *Type1Font) GetFamily() string       { return f.family }
+
+//AddType1Font : embed type1 font of PFB file with widths and metrics of AFM file (simple font like AddFont)
+func (gp *GoPdf) AddType1Font(family string, pfbPath string, afmPath string) error {
+	b, err := ioutil.ReadFile(pfbPath)
+	if err != nil {
+		return err
+	}
+	pfb, err := ParsePFB(b)
+	if err != nil {
+		return err
+	}
+	afm, err := ioutil.ReadFile(afmPath)
+	if err != nil {
+		return err
+	}
+	font, err := ParseAFM(afm)
+	if err != nil {
+		return err
+	}
+	font.originalsize = len(pfb.Clear) + len(pfb.Encrypted) + len(pfb.Trailer)
+
+	embedfont := new(EmbedFontObj)
+	embedfont.Init(func() *GoPdf {
+		return gp
+	})
+	embedfont.SetPFB(font, pfb)
+	gp.addSimpleFont(family, font, embedfont, FontOption{})
+	return nil
+}
@@ -0,0 +1,94 @@
+package gopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//testPFBSegment : segment of PFB file
+func testPFBSegment(segmentType byte, data []byte) []byte {
+	var buff bytes.Buffer
+	buff.Write([]byte{0x80, segmentType})
+	binary.Write(&buff, binary.LittleEndian, uint32(len(data)))
+	buff.Write(data)
+	return buff.Bytes()
+}
+
+const testAFM = `StartFontMetrics 4.1
+FontName TestType1
+ItalicAngle 0
+IsFixedPitch false
+FontBBox -50 -200 1000 900
+UnderlinePosition -100
+UnderlineThickness 50
+CapHeight 700
+Ascender 750
+Descender -250
+StdVW 85
+StartCharMetrics 3
+C 32 ; WX 250 ; N space ; B 0 0 0 0 ;
+C 65 ; WX 700 ; N A ; B 0 0 700 700 ;
+C 66 ; WX 650 ; N B ; B 0 0 650 700 ;
+EndCharMetrics
+EndFontMetrics
+`
+
+func TestAddType1Font(t *testing.T) {
+	clear := []byte("%!PS-AdobeFont-1.0: TestType1 001.000\n/FontName /TestType1 def\ncurrentfile eexec\n")
+	encrypted := []byte{0xD9, 0xD6, 0x6F, 0x63, 0x3B, 0x84, 0x6A, 0x98, 0x9B, 0x99}
+	trailer := []byte(strings.Repeat(strings.Repeat("0", 64)+"\n", 8) + "cleartomark\n")
+	var pfb bytes.Buffer
+	pfb.Write(testPFBSegment(1, clear))
+	pfb.Write(testPFBSegment(2, encrypted))
+	pfb.Write(testPFBSegment(1, trailer))
+	pfb.Write([]byte{0x80, 3})
+
+	dir := t.TempDir()
+	pfbPath := filepath.Join(dir, "test.pfb")
+	afmPath := filepath.Join(dir, "test.afm")
+	if err := ioutil.WriteFile(pfbPath, pfb.Bytes(), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := ioutil.WriteFile(afmPath, []byte(testAFM), 0644); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	pdf := GoPdf{}
+	pdf.Start(Config{Unit: "pt", PageSize: Rect{W: 595.28, H: 841.89}})
+	pdf.AddPage()
+	if err := pdf.AddType1Font("test", afmPath, afmPath); err != ErrInvalidPFB {
+		t.Errorf("expect ErrInvalidPFB but got %v", err)
+	}
+	if err := pdf.AddType1Font("test", pfbPath, afmPath); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := pdf.SetFont("test", "", 12); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if w, _ := pdf.MeasureTextWidth("AB "); w != (700+650+250)*12/1000.0 {
+		t.Errorf("width must come from AFM but got %f", w)
+	}
+	pdf.Cell(nil, "AB")
+
+	b := pdf.GetBytesPdf()
+	s := string(b)
+	lengths := "/Length1 " + strconv.Itoa(len(clear)) + "\n/Length2 " + strconv.Itoa(len(encrypted)) + "\n/Length3 " + strconv.Itoa(len(trailer)) + "\n"
+	if !strings.Contains(s, lengths) {
+		t.Errorf("lengths of type1 font not found")
+	}
+	if !strings.Contains(s, "  /Subtype /Type1\n  /BaseFont /TestType1\n") {
+		t.Errorf("type1 font dictionary not found")
+	}
+	if !strings.Contains(s, "/FontFile ") || strings.Contains(s, "/FontFile2 ") {
+		t.Errorf("type1 font must be embedded as FontFile")
+	}
+	if !strings.Contains(s, "/FontBBox [-50 -200 1000 900] ") || !strings.Contains(s, "/StemV 85 ") {
+		t.Errorf("descriptor must come from AFM")
+	}
+	checkXref(t, b)
+}
@@ -3,6 +3,7 @@ package gopdf
 import (
 	"bytes"
 	"fmt"
+	"sort"
 )
 
 type UnicodeMap struct {
@@ -29,16 +30,20 @@ func (u *UnicodeMap) GetObjBuff() *bytes.Buffer {
 	return &u.buffer
 }
 
+const unicodeMapPrefix = "/CIDInit /ProcSet findresource begin\n" +
+	"12 dict begin\n" +
+	"begincmap\n" +
+	"/CIDSystemInfo << /Registry (Adobe)/Ordering (UCS)/Supplement 0>> def\n" +
+	"/CMapName /Adobe-Identity-UCS def /CMapType 2 def\n"
+
+const unicodeMapSuffix = "endcmap CMapName currentdict /CMap defineresource pop end end"
+
 func (u *UnicodeMap) pdfToUnicodeMap() *bytes.Buffer {
+	if u.PtrToSubsetFontObj.isSimple() {
+		return unicodeMapStream(u.simpleUnicodeMap())
+	}
 	//stream
 	characterToGlyphIndex := u.PtrToSubsetFontObj.CharacterToGlyphIndex
-	prefix :=
-		"/CIDInit /ProcSet findresource begin\n" +
-			"12 dict begin\n" +
-			"begincmap\n" +
-			"/CIDSystemInfo << /Registry (Adobe)/Ordering (UCS)/Supplement 0>> def\n" +
-			"/CMapName /Adobe-Identity-UCS def /CMapType 2 def\n"
-	suffix := "endcmap CMapName currentdict /CMap defineresource pop end end"
 
 	glyphIndexToCharacter := make(map[int]rune)
 	lowIndex := 65536
@@ -51,22 +56,92 @@ func (u *UnicodeMap) pdfToUnicodeMap() *bytes.Buffer {
 		if index > hiIndex {
 			hiIndex = index
 		}
-		glyphIndexToCharacter[index] = k
+		//glyph ที่ใช้ร่วมกันหลาย rune (เช่น space กับ no-break space) map กลับเป็น rune ที่น้อยที่สุด
+		if r, ok := glyphIndexToCharacter[index]; !ok || k < r {
+			glyphIndexToCharacter[index] = k
+		}
+	}
+
+	//glyph จาก GSUB (เช่น ligature) map กลับเป็นทุก rune ที่แทน
+	glyphIndexToRunes := make(map[int][]rune)
+	for k, v := range u.PtrToSubsetFontObj.substitutedGlyphs {
+		index := int(k)
+		if _, ok := glyphIndexToCharacter[index]; ok {
+			continue
+		}
+		if index < lowIndex {
+			lowIndex = index
+		}
+		if index > hiIndex {
+			hiIndex = index
+		}
+		glyphIndexToRunes[index] = v
+	}
+
+	//unicode ที่ผู้ใช้กำหนดชนะ unicode จาก cmap
+	for k, r := range u.PtrToSubsetFontObj.glyphUnicodeMap {
+		index := int(k)
+		_, isChar := glyphIndexToCharacter[index]
+		_, isSubstituted := glyphIndexToRunes[index]
+		if isChar || isSubstituted {
+			delete(glyphIndexToRunes, index)
+			glyphIndexToCharacter[index] = r
+		}
 	}
 
 	var buff bytes.Buffer
-	buff.WriteString(prefix)
+	buff.WriteString(unicodeMapPrefix)
 	buff.WriteString("1 begincodespacerange\n")
 	buff.WriteString(fmt.Sprintf("<%04X><%04X>\n", lowIndex, hiIndex))
 	buff.WriteString("endcodespacerange\n")
-	buff.WriteString(fmt.Sprintf("%d beginbfrange\n", len(glyphIndexToCharacter)))
+	buff.WriteString(fmt.Sprintf("%d beginbfrange\n", len(glyphIndexToCharacter)+len(glyphIndexToRunes)))
 	for k, v := range glyphIndexToCharacter {
 		buff.WriteString(fmt.Sprintf("<%04X><%04X><%04X>\n", k, k, v))
 	}
+	for k, runes := range glyphIndexToRunes {
+		buff.WriteString(fmt.Sprintf("<%04X><%04X><", k, k))
+		for _, r := range runes {
+			buff.WriteString(fmt.Sprintf("%04X", r))
+		}
+		buff.WriteString(">\n")
+	}
 	buff.WriteString("endbfrange\n")
-	buff.WriteString(suffix)
+	buff.WriteString(unicodeMapSuffix)
 	buff.WriteString("\n")
+	return unicodeMapStream(&buff)
+}
+
+//simpleUnicodeMap : cmap of 1 byte codes of simple TrueType font
+func (u *UnicodeMap) simpleUnicodeMap() *bytes.Buffer {
+	codeToCharacter := make(map[byte]rune)
+	for r := range u.PtrToSubsetFontObj.CharacterToGlyphIndex {
+		if code, ok := winAnsiCode(r); ok {
+			codeToCharacter[code] = r
+		}
+	}
+	var codes []int
+	for code := range codeToCharacter {
+		codes = append(codes, int(code))
+	}
+	sort.Ints(codes)
+
+	var buff bytes.Buffer
+	buff.WriteString(unicodeMapPrefix)
+	buff.WriteString("1 begincodespacerange\n")
+	buff.WriteString("<00><FF>\n")
+	buff.WriteString("endcodespacerange\n")
+	buff.WriteString(fmt.Sprintf("%d beginbfchar\n", len(codes)))
+	for _, code := range codes {
+		buff.WriteString(fmt.Sprintf("<%02X><%04X>\n", code, codeToCharacter[byte(code)]))
+	}
+	buff.WriteString("endbfchar\n")
+	buff.WriteString(unicodeMapSuffix)
+	buff.WriteString("\n")
+	return &buff
+}
 
+//unicodeMapStream : stream obj of cmap
+func unicodeMapStream(buff *bytes.Buffer) *bytes.Buffer {
 	length := buff.Len()
 	var streambuff bytes.Buffer
 	streambuff.WriteString("<<\n")